@@ -0,0 +1,160 @@
+package apperror
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Kode error yang stabil dan dapat dibaca mesin. Klien sebaiknya bergantung pada kode ini,
+// bukan pada isi pesan yang dapat berubah.
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeValidationFailed        = "validation_failed"
+	CodeUnauthorized            = "unauthorized"
+	CodeTokenMissing            = "token_missing"
+	CodeTokenInvalid            = "token_invalid"
+	CodeTokenRevoked            = "token_revoked"
+	CodeTokenExpired            = "token_expired"
+	CodeInvalidCredentials      = "invalid_credentials"
+	CodeForbidden               = "forbidden"
+	CodeAccountInactive         = "account_inactive"
+	CodeNotFound                = "not_found"
+	CodeUserNotFound            = "user_not_found"
+	CodeEmailExists             = "email_exists"
+	CodeInvalidStatusTransition = "invalid_status_transition"
+	CodeVersionConflict         = "version_conflict"
+	CodePreconditionRequired    = "precondition_required"
+	CodeBatchRolledBack         = "batch_rolled_back"
+	CodeMethodNotAllowed        = "method_not_allowed"
+	CodeNotReady                = "not_ready"
+	CodeUnsupportedVersion      = "unsupported_version"
+	CodeRateLimited             = "rate_limited"
+	CodeInternal                = "internal_error"
+)
+
+// Kode error OAuth2 (RFC 6749 bagian 4.1.2.1 dan 5.2), dikirim di field error oleh
+// endpoint /oauth/*.
+const (
+	CodeInvalidClient           = "invalid_client"
+	CodeInvalidGrant            = "invalid_grant"
+	CodeInvalidScope            = "invalid_scope"
+	CodeUnauthorizedClient      = "unauthorized_client"
+	CodeUnsupportedGrantType    = "unsupported_grant_type"
+	CodeUnsupportedResponseType = "unsupported_response_type"
+	CodeAccessDenied            = "access_denied"
+	// CodeInsufficientScope dipakai resource yang memerlukan scope tertentu (RFC 6750 bagian 3.1)
+	CodeInsufficientScope = "insufficient_scope"
+)
+
+// Kode error login lewat IdP eksternal dan penautan identitas.
+const (
+	CodeProviderNotFound      = "provider_not_found"
+	CodeInvalidState          = "invalid_state"
+	CodeIdentityProviderError = "identity_provider_error"
+	CodeIdentityLinked        = "identity_already_linked"
+	CodeIdentityNotFound      = "identity_not_found"
+	CodeProvisioningDisabled  = "provisioning_disabled"
+	CodeLastLoginMethod       = "last_login_method"
+)
+
+// Kode error login tanpa password lewat magic link atau kode email.
+const (
+	CodeInvalidChallenge = "invalid_challenge"
+	CodeChallengeExpired = "challenge_expired"
+	CodeInvalidCode      = "invalid_code"
+	CodeDeviceMismatch   = "device_mismatch"
+	CodeTooManyAttempts  = "too_many_attempts"
+)
+
+// Kode error passkey WebAuthn.
+const (
+	CodeInvalidCeremony           = "invalid_ceremony"
+	CodePasskeyVerificationFailed = "passkey_verification_failed"
+	CodePasskeyNotFound           = "passkey_not_found"
+	CodePasskeyExists             = "passkey_exists"
+	// CodeSecondFactorRequired dikembalikan login password bila akun wajib dikonfirmasi passkey
+	CodeSecondFactorRequired = "second_factor_required"
+)
+
+// Kode error token akses pribadi.
+const (
+	CodeAPITokenNotFound = "api_token_not_found"
+	CodeAPITokenLimit    = "api_token_limit_reached"
+)
+
+// Kode error impersonation oleh admin.
+const (
+	CodeImpersonationNotAllowed = "impersonation_not_allowed"
+	CodeNotImpersonating        = "not_impersonating"
+)
+
+// Error adalah error aplikasi yang membawa status HTTP, kode stabil, dan pesan untuk klien.
+// Err menyimpan penyebab asli dan tidak pernah dikirim ke klien.
+type Error struct {
+	Status     int
+	Code       string
+	Message    string
+	Err        error
+	Extensions map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With menambahkan member tambahan pada respons problem+json.
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	e.Extensions[key] = value
+	return e
+}
+
+// New membuat error aplikasi baru.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Wrap membuat error aplikasi dengan penyebab asli yang hanya dicatat di log.
+func Wrap(err error, status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message, Err: err}
+}
+
+// BadRequest untuk payload atau parameter yang tidak valid.
+func BadRequest(code, message string) *Error {
+	return New(fiber.StatusBadRequest, code, message)
+}
+
+// Unauthorized untuk permintaan tanpa kredensial yang valid.
+func Unauthorized(code, message string) *Error {
+	return New(fiber.StatusUnauthorized, code, message)
+}
+
+// Forbidden untuk permintaan yang terautentikasi tetapi tidak diizinkan.
+func Forbidden(code, message string) *Error {
+	return New(fiber.StatusForbidden, code, message)
+}
+
+// NotFound untuk resource yang tidak ditemukan.
+func NotFound(code, message string) *Error {
+	return New(fiber.StatusNotFound, code, message)
+}
+
+// Conflict untuk permintaan yang bertentangan dengan keadaan resource saat ini.
+func Conflict(code, message string) *Error {
+	return New(fiber.StatusConflict, code, message)
+}
+
+// Internal untuk kegagalan tak terduga; pesan ke klien selalu generik.
+func Internal(err error, message string) *Error {
+	return Wrap(err, fiber.StatusInternalServerError, CodeInternal, message)
+}
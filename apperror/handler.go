@@ -0,0 +1,84 @@
+package apperror
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContentTypeProblem adalah media type untuk respons error sesuai RFC 7807.
+const ContentTypeProblem = "application/problem+json"
+
+// TypeURI membentuk URI tipe problem dari kode error.
+func TypeURI(code string) string {
+	return "urn:problem-type:user-management:" + code
+}
+
+// Handler adalah ErrorHandler Fiber yang mengubah setiap error menjadi application/problem+json.
+func Handler(c *fiber.Ctx, err error) error {
+	appErr := From(err)
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "request failed",
+			"method", c.Method(), "path", c.Path(), "code", appErr.Code, "error", err)
+	}
+
+	body := fiber.Map{
+		"type":     TypeURI(appErr.Code),
+		"title":    http.StatusText(appErr.Status),
+		"status":   appErr.Status,
+		"detail":   appErr.Message,
+		"instance": c.OriginalURL(),
+		"code":     appErr.Code,
+	}
+	for key, value := range appErr.Extensions {
+		if _, reserved := body[key]; !reserved {
+			body[key] = value
+		}
+	}
+
+	if err := c.Status(appErr.Status).JSON(body); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ContentTypeProblem)
+	return nil
+}
+
+// From mengubah error apa pun menjadi *Error. Error yang tidak dikenal menjadi internal_error
+// tanpa membocorkan pesan aslinya.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, codeForStatus(fiberErr.Code), fiberErr.Message)
+	}
+
+	return Internal(err, "Internal server error")
+}
+
+// codeForStatus memetakan error bawaan Fiber (mis. rute tidak ditemukan) ke kode stabil.
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeInvalidRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	default:
+		if status >= fiber.StatusInternalServerError {
+			return CodeInternal
+		}
+		return CodeInvalidRequest
+	}
+}
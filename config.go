@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go-fiber-user-management/config"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = `Usage: config <command> [options]
+
+Commands:
+  print [-format yaml|toml]  print the effective configuration with secrets redacted
+`
+
+// runConfig menjalankan subcommand `config` dan mengembalikan kode exit.
+func runConfig(args []string, cfg config.Config) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	format := flags.String("format", "yaml", "output format: yaml or toml")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	redacted := cfg.Redacted()
+	var err error
+	switch *format {
+	case "yaml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		err = encoder.Encode(redacted)
+	case "toml":
+		err = toml.NewEncoder(os.Stdout).Encode(redacted)
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "print config: %v\n", err)
+		return 1
+	}
+
+	// Konfigurasi tetap dicetak walau tidak valid agar mudah diperiksa
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "configuration is invalid:\n%v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,461 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/database"
+	"go-fiber-user-management/logging"
+	"go-fiber-user-management/mailer"
+	"go-fiber-user-management/openapi"
+	"go-fiber-user-management/tracing"
+	"go-fiber-user-management/versioning"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// MinJWTSecretLength adalah panjang minimum JWT_SECRET (256 bit untuk HS256).
+const MinJWTSecretLength = 32
+
+// Config adalah seluruh konfigurasi aplikasi.
+type Config struct {
+	Server        ServerConfig        `yaml:"server" toml:"server"`
+	API           APIConfig           `yaml:"api" toml:"api"`
+	Database      DatabaseConfig      `yaml:"database" toml:"database"`
+	Auth          AuthConfig          `yaml:"auth" toml:"auth"`
+	OAuth         OAuthConfig         `yaml:"oauth" toml:"oauth"`
+	Identity      IdentityConfig      `yaml:"identity" toml:"identity"`
+	Passwordless  PasswordlessConfig  `yaml:"passwordless" toml:"passwordless"`
+	WebAuthn      WebAuthnConfig      `yaml:"webauthn" toml:"webauthn"`
+	APITokens     APITokenConfig      `yaml:"api_tokens" toml:"api_tokens"`
+	Impersonation ImpersonationConfig `yaml:"impersonation" toml:"impersonation"`
+	Email         EmailConfig         `yaml:"email" toml:"email"`
+	Mail          mailer.Config       `yaml:"mail" toml:"mail"`
+	Log           LogConfig           `yaml:"log" toml:"log"`
+	Tracing       tracing.Config      `yaml:"tracing" toml:"tracing"`
+	Docs          openapi.DocsConfig  `yaml:"docs" toml:"docs"`
+}
+
+// Nilai ServerConfig.Environment.
+const (
+	EnvironmentProduction  = "production"
+	EnvironmentDevelopment = "development"
+)
+
+// ServerConfig berisi pengaturan server HTTP.
+type ServerConfig struct {
+	// development (default) atau production; production menolak issuer localhost atau http
+	Environment string `yaml:"environment" toml:"environment"`
+	Port        int    `yaml:"port" toml:"port"`
+	// Wajibkan If-Match untuk PUT/PATCH/DELETE; default nonaktif agar klien lama tanpa If-Match tetap berjalan
+	RequireIfMatch bool `yaml:"require_if_match" toml:"require_if_match"`
+	// Batas waktu menunggu request yang sedang berjalan saat shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// LegacySunsetLayout adalah format tanggal untuk APIConfig.LegacyDeprecated dan LegacySunset.
+const LegacySunsetLayout = "2006-01-02"
+
+// APIConfig berisi pengaturan versi API.
+type APIConfig struct {
+	// Versi yang melayani /api tanpa versi jika klien tidak mengirim header API-Version
+	DefaultVersion string `yaml:"default_version" toml:"default_version"`
+	// Tanggal (YYYY-MM-DD) path /api tanpa versi dinyatakan usang, dikirim di header Deprecation;
+	// kosong jika path tersebut masih didukung penuh
+	LegacyDeprecated string `yaml:"legacy_deprecated" toml:"legacy_deprecated"`
+	// Tanggal (YYYY-MM-DD) path /api tanpa versi dihapus, dikirim di header Sunset; kosong jika belum dijadwalkan
+	LegacySunset string `yaml:"legacy_sunset" toml:"legacy_sunset"`
+}
+
+// Deprecated mengembalikan LegacyDeprecated sebagai waktu UTC, atau zero jika kosong.
+func (cfg APIConfig) Deprecated() (time.Time, error) {
+	if cfg.LegacyDeprecated == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(LegacySunsetLayout, cfg.LegacyDeprecated)
+}
+
+// Sunset mengembalikan LegacySunset sebagai waktu UTC, atau zero jika kosong.
+func (cfg APIConfig) Sunset() (time.Time, error) {
+	if cfg.LegacySunset == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(LegacySunsetLayout, cfg.LegacySunset)
+}
+
+// DatabaseConfig berisi pengaturan koneksi database beserta migrasi saat boot.
+type DatabaseConfig struct {
+	database.Config `yaml:",inline"`
+	AutoMigrate     bool `yaml:"auto_migrate" toml:"auto_migrate"` // Jalankan `migrate up` saat aplikasi start
+}
+
+// AuthConfig berisi pengaturan token JWT.
+type AuthConfig struct {
+	JWTSecret string        `yaml:"jwt_secret" toml:"jwt_secret"`
+	TokenTTL  time.Duration `yaml:"token_ttl" toml:"token_ttl"`
+	// Interval background job yang menghapus token dibatalkan yang sudah kedaluwarsa; 0 menonaktifkan
+	RevocationCleanupInterval time.Duration `yaml:"revocation_cleanup_interval" toml:"revocation_cleanup_interval"`
+}
+
+// OAuthConfig berisi pengaturan server otorisasi OAuth2.
+type OAuthConfig struct {
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" toml:"access_token_ttl"` // Masa berlaku token dari /oauth/token
+	CodeTTL        time.Duration `yaml:"code_ttl" toml:"code_ttl"`                 // Masa berlaku kode otorisasi
+	IDTokenTTL     time.Duration `yaml:"id_token_ttl" toml:"id_token_ttl"`         // Masa berlaku ID token OpenID Connect
+	// Issuer adalah URL publik server (claim iss dan dasar discovery document)
+	Issuer string `yaml:"issuer" toml:"issuer"`
+	// SigningKeyFile adalah kunci privat RSA (PEM) untuk ID token. Kosong berarti kunci
+	// sementara dibuat saat start sehingga ID token lama tidak bisa diverifikasi setelah restart.
+	SigningKeyFile string `yaml:"signing_key_file" toml:"signing_key_file"`
+}
+
+// PasswordlessConfig berisi pengaturan login tanpa password.
+type PasswordlessConfig struct {
+	TTL         time.Duration `yaml:"ttl" toml:"ttl"`                   // Masa berlaku magic link dan kode
+	MaxAttempts int           `yaml:"max_attempts" toml:"max_attempts"` // Percobaan salah per challenge
+	// Jeda minimum antar permintaan dari satu alamat IP untuk email yang sama, terdaftar atau tidak
+	Cooldown time.Duration `yaml:"cooldown" toml:"cooldown"`
+	// Permintaan maksimum dari satu alamat IP dalam IPWindow
+	IPLimit  int           `yaml:"ip_limit" toml:"ip_limit"`
+	IPWindow time.Duration `yaml:"ip_window" toml:"ip_window"`
+	// LinkURL adalah halaman yang dibuka magic link, dengan token di query ?token=. Kosong berarti
+	// endpoint verify API di bawah OAUTH_ISSUER.
+	LinkURL string `yaml:"link_url" toml:"link_url"`
+	// Interval background job yang menghapus challenge kedaluwarsa; 0 menonaktifkan
+	CleanupInterval time.Duration `yaml:"cleanup_interval" toml:"cleanup_interval"`
+}
+
+// APITokenConfig berisi pengaturan token akses pribadi untuk otomasi (CI, skrip).
+type APITokenConfig struct {
+	DefaultTTL time.Duration `yaml:"default_ttl" toml:"default_ttl"` // Masa berlaku jika expires_in tidak diisi
+	MaxTTL     time.Duration `yaml:"max_ttl" toml:"max_ttl"`         // Masa berlaku terpanjang yang boleh diminta
+	MaxPerUser int           `yaml:"max_per_user" toml:"max_per_user"`
+}
+
+// ImpersonationConfig berisi pengaturan admin yang masuk sebagai pengguna lain untuk dukungan.
+type ImpersonationConfig struct {
+	TTL time.Duration `yaml:"ttl" toml:"ttl"` // Masa berlaku token impersonation
+	// Admins adalah email admin yang boleh melakukan impersonation; kosong berarti fitur nonaktif
+	Admins []string `yaml:"admins" toml:"admins"`
+}
+
+// Allows memeriksa apakah admin dengan email tersebut boleh melakukan impersonation.
+func (cfg ImpersonationConfig) Allows(email string) bool {
+	for _, admin := range cfg.Admins {
+		if strings.EqualFold(strings.TrimSpace(admin), email) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailConfig berisi pengaturan normalisasi email.
+type EmailConfig struct {
+	// Terapkan aturan alias penyedia (Gmail, Outlook, ...) saat menormalisasi email
+	CanonicalizeProviders bool `yaml:"canonicalize_providers" toml:"canonicalize_providers"`
+}
+
+// LogConfig berisi pengaturan logging terstruktur.
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // debug, info, warn, atau error; debug mencatat setiap query SQL
+	Format string `yaml:"format" toml:"format"` // json atau text
+}
+
+// NewLogger membuat logger slog sesuai konfigurasi yang menulis ke w.
+func (cfg LogConfig) NewLogger(w io.Writer) (*slog.Logger, error) {
+	level, err := logging.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(w, level, cfg.Format)
+}
+
+// Default mengembalikan konfigurasi bawaan sebelum file dan variabel lingkungan diterapkan.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Environment:     EnvironmentDevelopment,
+			Port:            3000,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		API: APIConfig{
+			DefaultVersion: "v1",
+		},
+		Database: DatabaseConfig{
+			Config: database.Config{
+				Driver:          database.DriverPostgres,
+				SSLMode:         "disable",
+				MaxOpenConns:    25,
+				MaxIdleConns:    10,
+				ConnMaxLifetime: 30 * time.Minute,
+				ConnMaxIdleTime: 5 * time.Minute,
+			},
+		},
+		Auth: AuthConfig{
+			TokenTTL:                  24 * time.Hour,
+			RevocationCleanupInterval: time.Hour,
+		},
+		OAuth: OAuthConfig{
+			AccessTokenTTL: time.Hour,
+			CodeTTL:        5 * time.Minute,
+			IDTokenTTL:     time.Hour,
+			Issuer:         "http://localhost:3000",
+		},
+		Passwordless: PasswordlessConfig{
+			TTL:             10 * time.Minute,
+			MaxAttempts:     5,
+			Cooldown:        time.Minute,
+			IPLimit:         20,
+			IPWindow:        time.Hour,
+			CleanupInterval: time.Hour,
+		},
+		WebAuthn: WebAuthnConfig{
+			RPName:  "User Management",
+			Timeout: 5 * time.Minute,
+		},
+		APITokens: APITokenConfig{
+			DefaultTTL: 30 * 24 * time.Hour,
+			MaxTTL:     365 * 24 * time.Hour,
+			MaxPerUser: 25,
+		},
+		Impersonation: ImpersonationConfig{
+			TTL: 15 * time.Minute,
+		},
+		Mail: mailer.Config{
+			Driver: mailer.DriverLog,
+			From:   "no-reply@localhost",
+			Port:   587,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: logging.FormatJSON,
+		},
+		Tracing: tracing.Config{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+			ServiceName: "user-management",
+		},
+	}
+}
+
+// Load membaca konfigurasi dengan urutan prioritas naik: nilai default, file konfigurasi
+// (path dari CONFIG_FILE, .yaml/.yml atau .toml), lalu variabel lingkungan termasuk .env.
+// File .env yang tidak ada bukan error. Load tidak memvalidasi; panggil Validate setelahnya.
+func Load() (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("load .env: %w", err)
+	}
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile seperti Load tetapi dengan path file konfigurasi eksplisit dan tanpa membaca .env.
+// path kosong berarti hanya default dan variabel lingkungan yang dipakai.
+func LoadFile(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := decodeFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// decodeFile membaca file YAML atau TOML ke cfg berdasarkan ekstensinya.
+func decodeFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(raw))
+		decoder.KnownFields(true)
+		// io.EOF berarti file kosong; biarkan nilai default
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(raw), cfg)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parse %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("unsupported config file %q: use .yaml, .yml or .toml", path)
+	}
+	return nil
+}
+
+// Validate memeriksa konfigurasi saat startup agar kesalahan terdeteksi sebelum server berjalan.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.Server.Environment != EnvironmentProduction && cfg.Server.Environment != EnvironmentDevelopment {
+		errs = append(errs, fmt.Errorf("APP_ENV must be production or development, got %q", cfg.Server.Environment))
+	}
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", cfg.Server.Port))
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", cfg.Server.ShutdownTimeout))
+	}
+	if !versioning.Valid(cfg.API.DefaultVersion) {
+		errs = append(errs, fmt.Errorf("API_DEFAULT_VERSION must look like v1, got %q", cfg.API.DefaultVersion))
+	}
+	deprecated, deprecatedErr := cfg.API.Deprecated()
+	if deprecatedErr != nil {
+		errs = append(errs, fmt.Errorf("API_LEGACY_DEPRECATED must be a date like 2026-10-19, got %q", cfg.API.LegacyDeprecated))
+	}
+	sunset, sunsetErr := cfg.API.Sunset()
+	if sunsetErr != nil {
+		errs = append(errs, fmt.Errorf("API_LEGACY_SUNSET must be a date like 2027-01-31, got %q", cfg.API.LegacySunset))
+	}
+	// Klien harus menerima header Deprecation sebelum path tanpa versi dihapus
+	if deprecatedErr == nil && sunsetErr == nil && !sunset.IsZero() && (deprecated.IsZero() || !deprecated.Before(sunset)) {
+		errs = append(errs, fmt.Errorf("API_LEGACY_DEPRECATED (%q) must be set to a date before API_LEGACY_SUNSET (%q)",
+			cfg.API.LegacyDeprecated, cfg.API.LegacySunset))
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(cfg.Auth.JWTSecret) < MinJWTSecretLength {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters", MinJWTSecretLength))
+	}
+	if cfg.Auth.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("JWT_TOKEN_TTL must be positive, got %s", cfg.Auth.TokenTTL))
+	}
+	if cfg.OAuth.AccessTokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("OAUTH_ACCESS_TOKEN_TTL must be positive, got %s", cfg.OAuth.AccessTokenTTL))
+	}
+	// Catatan revocation dihapus setelah JWT_TOKEN_TTL; token OAuth yang berlaku lebih lama
+	// akan kembali valid setelah catatannya dihapus
+	if cfg.OAuth.AccessTokenTTL > cfg.Auth.TokenTTL {
+		errs = append(errs, fmt.Errorf("OAUTH_ACCESS_TOKEN_TTL (%s) must not exceed JWT_TOKEN_TTL (%s)",
+			cfg.OAuth.AccessTokenTTL, cfg.Auth.TokenTTL))
+	}
+	if cfg.OAuth.CodeTTL <= 0 || cfg.OAuth.CodeTTL > 10*time.Minute {
+		errs = append(errs, fmt.Errorf("OAUTH_CODE_TTL must be between 1s and 10m, got %s", cfg.OAuth.CodeTTL))
+	}
+	if cfg.OAuth.IDTokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("OAUTH_ID_TOKEN_TTL must be positive, got %s", cfg.OAuth.IDTokenTTL))
+	}
+	// Issuer OpenID Connect harus URL absolut tanpa query dan fragment
+	issuer, err := url.Parse(cfg.OAuth.Issuer)
+	if err != nil || !issuer.IsAbs() || issuer.Host == "" || issuer.RawQuery != "" || issuer.Fragment != "" {
+		errs = append(errs, fmt.Errorf("OAUTH_ISSUER must be an absolute URL without query or fragment, got %q", cfg.OAuth.Issuer))
+	} else if cfg.Server.Environment == EnvironmentProduction && (issuer.Scheme != "https" || loopback(issuer.Hostname())) {
+		// Default http://localhost:3000 hanya untuk development; token dengan iss itu tidak bisa diverifikasi klien
+		errs = append(errs, fmt.Errorf("OAUTH_ISSUER must be the public https URL of the server in production, got %q "+
+			"(set APP_ENV=development for local use)", cfg.OAuth.Issuer))
+	}
+	if err := cfg.Identity.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	// Magic link dan kode harus berumur pendek
+	if cfg.Passwordless.TTL <= 0 || cfg.Passwordless.TTL > time.Hour {
+		errs = append(errs, fmt.Errorf("PASSWORDLESS_TTL must be between 1s and 1h, got %s", cfg.Passwordless.TTL))
+	}
+	if cfg.Passwordless.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("PASSWORDLESS_MAX_ATTEMPTS must be at least 1, got %d", cfg.Passwordless.MaxAttempts))
+	}
+	if cfg.Passwordless.Cooldown < time.Second {
+		errs = append(errs, fmt.Errorf("PASSWORDLESS_COOLDOWN must be at least 1s, got %s", cfg.Passwordless.Cooldown))
+	}
+	if cfg.Passwordless.IPLimit < 1 || cfg.Passwordless.IPWindow < time.Second {
+		errs = append(errs, fmt.Errorf("PASSWORDLESS_IP_LIMIT must be at least 1 per PASSWORDLESS_IP_WINDOW of at least 1s, got %d per %s",
+			cfg.Passwordless.IPLimit, cfg.Passwordless.IPWindow))
+	}
+	if link := cfg.Passwordless.LinkURL; link != "" {
+		if u, err := url.Parse(link); err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+			errs = append(errs, fmt.Errorf("PASSWORDLESS_LINK_URL must be an absolute URL without fragment, got %q", link))
+		}
+	}
+	if cfg.Passwordless.CleanupInterval < 0 {
+		errs = append(errs, fmt.Errorf("PASSWORDLESS_CLEANUP_INTERVAL must not be negative, got %s", cfg.Passwordless.CleanupInterval))
+	}
+	if err := cfg.WebAuthn.validate(cfg.OAuth.Issuer); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.APITokens.MaxTTL <= 0 {
+		errs = append(errs, fmt.Errorf("API_TOKEN_MAX_TTL must be positive, got %s", cfg.APITokens.MaxTTL))
+	}
+	if cfg.APITokens.DefaultTTL <= 0 || cfg.APITokens.DefaultTTL > cfg.APITokens.MaxTTL {
+		errs = append(errs, fmt.Errorf("API_TOKEN_DEFAULT_TTL must be between 1s and API_TOKEN_MAX_TTL, got %s",
+			cfg.APITokens.DefaultTTL))
+	}
+	if cfg.APITokens.MaxPerUser < 1 {
+		errs = append(errs, fmt.Errorf("API_TOKEN_MAX_PER_USER must be at least 1, got %d", cfg.APITokens.MaxPerUser))
+	}
+	// Token impersonation harus berumur pendek; catatan revocation-nya juga dihapus setelah JWT_TOKEN_TTL
+	if ttl := cfg.Impersonation.TTL; ttl <= 0 || ttl > time.Hour || ttl > cfg.Auth.TokenTTL {
+		errs = append(errs, fmt.Errorf("IMPERSONATION_TTL must be between 1s and 1h and not exceed JWT_TOKEN_TTL, got %s", ttl))
+	}
+	if err := cfg.Mail.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := cfg.Log.NewLogger(io.Discard); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL/LOG_FORMAT: %w", err))
+	}
+	if err := cfg.Tracing.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := cfg.Docs.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Auth.RevocationCleanupInterval < 0 {
+		errs = append(errs, fmt.Errorf("JWT_REVOCATION_CLEANUP_INTERVAL must not be negative, got %s", cfg.Auth.RevocationCleanupInterval))
+	}
+
+	return errors.Join(errs...)
+}
+
+// loopback melaporkan apakah host hanya bisa dijangkau dari mesin yang sama.
+func loopback(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// Redacted mengembalikan salinan konfigurasi dengan nilai rahasia disamarkan, aman untuk dicetak.
+func (cfg Config) Redacted() Config {
+	cfg.Database.Password = redact(cfg.Database.Password)
+	cfg.Auth.JWTSecret = redact(cfg.Auth.JWTSecret)
+	cfg.Mail.Password = redact(cfg.Mail.Password)
+	// Map disalin agar secret provider di konfigurasi asli tidak ikut tersamarkan
+	if len(cfg.Identity.Providers) > 0 {
+		providers := make(map[string]IdentityProvider, len(cfg.Identity.Providers))
+		for name, provider := range cfg.Identity.Providers {
+			provider.ClientSecret = redact(provider.ClientSecret)
+			providers[name] = provider
+		}
+		cfg.Identity.Providers = providers
+	}
+	return cfg
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
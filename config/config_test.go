@@ -0,0 +1,238 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/database"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+server:
+  port: 8080
+  require_if_match: true
+database:
+  driver: sqlite
+  name: app.db
+  auto_migrate: true
+auth:
+  jwt_secret: ` + testSecret + `
+  token_ttl: 2h
+`,
+		"config.toml": `
+[server]
+port = 8080
+require_if_match = true
+
+[database]
+driver = "sqlite"
+name = "app.db"
+auto_migrate = true
+
+[auth]
+jwt_secret = "` + testSecret + `"
+token_ttl = "2h"
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := config.LoadFile(writeFile(t, name, content))
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			if cfg.Server.Port != 8080 || !cfg.Server.RequireIfMatch {
+				t.Errorf("server = %+v", cfg.Server)
+			}
+			if cfg.Database.Driver != database.DriverSQLite || cfg.Database.Name != "app.db" || !cfg.Database.AutoMigrate {
+				t.Errorf("database = %+v", cfg.Database)
+			}
+			// Nilai yang tidak ada di file tetap memakai default
+			if cfg.Database.SSLMode != "disable" {
+				t.Errorf("sslmode = %q, want default disable", cfg.Database.SSLMode)
+			}
+			if cfg.Auth.JWTSecret != testSecret || cfg.Auth.TokenTTL != 2*time.Hour {
+				t.Errorf("auth = %+v", cfg.Auth)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFileEnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "config.yaml", "server:\n  port: 8080\n")
+	t.Setenv("PORT", "9090")
+	t.Setenv("DB_AUTO_MIGRATE", "true")
+	t.Setenv("JWT_TOKEN_TTL", "30m")
+	t.Setenv("WEBAUTHN_ORIGINS", "https://app.example.com, https://admin.example.com")
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Server.Port != 9090 || !cfg.Database.AutoMigrate || cfg.Auth.TokenTTL != 30*time.Minute {
+		t.Errorf("env not applied: %+v", cfg)
+	}
+	if origins := cfg.WebAuthn.Origins; len(origins) != 2 || origins[1] != "https://admin.example.com" {
+		t.Errorf("webauthn origins = %q", origins)
+	}
+}
+
+func TestLoadFileIdentityProviderSecretFromEnv(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+identity:
+  providers:
+    acme-sso:
+      issuer: https://sso.acme.example
+      client_id: from-file
+`)
+	t.Setenv("IDP_ACME_SSO_CLIENT_SECRET", "from-env")
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	provider := cfg.Identity.Providers["acme-sso"]
+	if provider.ClientID != "from-file" || provider.ClientSecret != "from-env" {
+		t.Errorf("provider = %+v", provider)
+	}
+	if cfg.Redacted().Identity.Providers["acme-sso"].ClientSecret == "from-env" ||
+		cfg.Identity.Providers["acme-sso"].ClientSecret != "from-env" {
+		t.Error("provider secret not redacted on a copy")
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{name: "unknown yaml key", file: "c.yaml", content: "server:\n  prot: 80\n", want: "prot"},
+		{name: "unknown toml key", file: "c.toml", content: "[server]\nprot = 80\n", want: "server.prot"},
+		{name: "unsupported extension", file: "c.json", content: "{}", want: "unsupported config file"},
+		{name: "invalid env bool", file: "c.yaml", env: map[string]string{"REQUIRE_IF_MATCH": "maybe"}, want: "REQUIRE_IF_MATCH"},
+		{name: "invalid env duration", file: "c.yaml", env: map[string]string{"JWT_TOKEN_TTL": "1 day"}, want: "JWT_TOKEN_TTL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := config.LoadFile(writeFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() config.Config {
+		cfg := config.Default()
+		cfg.Database.Driver = database.DriverSQLite
+		cfg.Database.Name = ":memory:"
+		cfg.Auth.JWTSecret = testSecret
+		cfg.Server.Environment = config.EnvironmentProduction
+		cfg.OAuth.Issuer = "https://auth.example.com"
+		return cfg
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	// Tanpa APP_ENV dan OAUTH_ISSUER konfigurasi lama tetap valid
+	defaults := valid()
+	defaults.Server.Environment = config.Default().Server.Environment
+	defaults.OAuth.Issuer = config.Default().OAuth.Issuer
+	if err := defaults.Validate(); err != nil {
+		t.Fatalf("default environment with default issuer: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*config.Config)
+		want   string
+	}{
+		{"short jwt secret", func(c *config.Config) { c.Auth.JWTSecret = "short" }, "JWT_SECRET"},
+		{"invalid port", func(c *config.Config) { c.Server.Port = 70000 }, "PORT"},
+		{"zero token ttl", func(c *config.Config) { c.Auth.TokenTTL = 0 }, "JWT_TOKEN_TTL"},
+		{"missing database", func(c *config.Config) { c.Database.Name = "" }, "DB_NAME"},
+		{"invalid api version", func(c *config.Config) { c.API.DefaultVersion = "1" }, "API_DEFAULT_VERSION"},
+		{"oauth ttl exceeds jwt ttl", func(c *config.Config) { c.OAuth.AccessTokenTTL = 48 * time.Hour }, "OAUTH_ACCESS_TOKEN_TTL"},
+		{"relative issuer", func(c *config.Config) { c.OAuth.Issuer = "/auth" }, "OAUTH_ISSUER"},
+		{"default issuer in production", func(c *config.Config) { c.OAuth.Issuer = config.Default().OAuth.Issuer }, "OAUTH_ISSUER"},
+		{"loopback issuer in production", func(c *config.Config) { c.OAuth.Issuer = "https://127.0.0.1:8443" }, "OAUTH_ISSUER"},
+		{"unknown environment", func(c *config.Config) { c.Server.Environment = "staging" }, "APP_ENV"},
+		{"identity provider without endpoints", func(c *config.Config) {
+			c.Identity.Providers = map[string]config.IdentityProvider{"acme": {ClientID: "client"}}
+		}, `identity provider "acme"`},
+		{"long passwordless ttl", func(c *config.Config) { c.Passwordless.TTL = 24 * time.Hour }, "PASSWORDLESS_TTL"},
+		{"passwordless without ip limit", func(c *config.Config) { c.Passwordless.IPLimit = 0 }, "PASSWORDLESS_IP_LIMIT"},
+		{"smtp without host", func(c *config.Config) { c.Mail.Driver = "smtp" }, "SMTP_HOST"},
+		{"webauthn origin outside rp id", func(c *config.Config) {
+			c.WebAuthn.RPID = "example.com"
+			c.WebAuthn.Origins = []string{"https://evil.test"}
+		}, "WEBAUTHN_ORIGINS"},
+		{"api token default above max", func(c *config.Config) { c.APITokens.DefaultTTL = 2 * c.APITokens.MaxTTL }, "API_TOKEN_DEFAULT_TTL"},
+		{"impersonation ttl too long", func(c *config.Config) { c.Impersonation.TTL = 2 * time.Hour }, "IMPERSONATION_TTL"},
+		{"invalid sunset", func(c *config.Config) { c.API.LegacySunset = "next year" }, "API_LEGACY_SUNSET"},
+		{"invalid deprecation date", func(c *config.Config) { c.API.LegacyDeprecated = "last year" }, "API_LEGACY_DEPRECATED"},
+		{"sunset before deprecation", func(c *config.Config) {
+			c.API.LegacyDeprecated = "2026-10-19"
+			c.API.LegacySunset = "2026-01-31"
+		}, "API_LEGACY_DEPRECATED"},
+		{"sunset without deprecation", func(c *config.Config) { c.API.LegacySunset = "2027-01-31" }, "API_LEGACY_DEPRECATED"},
+		{"swagger ui cdn without sri", func(c *config.Config) {
+			c.Docs.SwaggerUIURL = "https://unpkg.com/swagger-ui-dist@5.17.14"
+		}, "DOCS_SCRIPT_INTEGRITY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Password = "db-password"
+	cfg.Auth.JWTSecret = testSecret
+	cfg.Mail.Password = "smtp-password"
+
+	redacted := cfg.Redacted()
+	if redacted.Database.Password == cfg.Database.Password || redacted.Auth.JWTSecret == cfg.Auth.JWTSecret ||
+		redacted.Mail.Password == cfg.Mail.Password {
+		t.Fatalf("secrets not redacted: %+v", redacted)
+	}
+	if cfg.Auth.JWTSecret != testSecret {
+		t.Fatal("Redacted modified the original config")
+	}
+}
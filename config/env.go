@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyEnv menimpa cfg dengan variabel lingkungan yang diset; variabel kosong diabaikan.
+func applyEnv(cfg *Config) error {
+	var errs []error
+
+	envString(&cfg.Server.Environment, "APP_ENV")
+	envInt(&cfg.Server.Port, "PORT", &errs)
+	envBool(&cfg.Server.RequireIfMatch, "REQUIRE_IF_MATCH", &errs)
+	envDuration(&cfg.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT", &errs)
+
+	envString(&cfg.API.DefaultVersion, "API_DEFAULT_VERSION")
+	envString(&cfg.API.LegacyDeprecated, "API_LEGACY_DEPRECATED")
+	envString(&cfg.API.LegacySunset, "API_LEGACY_SUNSET")
+
+	envString(&cfg.Database.Driver, "DB_DRIVER")
+	envString(&cfg.Database.Host, "DB_HOST")
+	envString(&cfg.Database.Port, "DB_PORT")
+	envString(&cfg.Database.User, "DB_USER")
+	envString(&cfg.Database.Password, "DB_PASSWORD")
+	envString(&cfg.Database.Name, "DB_NAME")
+	envString(&cfg.Database.SSLMode, "DB_SSLMODE")
+	envString(&cfg.Database.SSLRootCert, "DB_SSLROOTCERT")
+	envString(&cfg.Database.SSLCert, "DB_SSLCERT")
+	envString(&cfg.Database.SSLKey, "DB_SSLKEY")
+	envInt(&cfg.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS", &errs)
+	envInt(&cfg.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS", &errs)
+	envDuration(&cfg.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME", &errs)
+	envDuration(&cfg.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME", &errs)
+	envBool(&cfg.Database.AutoMigrate, "DB_AUTO_MIGRATE", &errs)
+
+	envString(&cfg.Auth.JWTSecret, "JWT_SECRET")
+	envDuration(&cfg.Auth.TokenTTL, "JWT_TOKEN_TTL", &errs)
+	envDuration(&cfg.Auth.RevocationCleanupInterval, "JWT_REVOCATION_CLEANUP_INTERVAL", &errs)
+
+	envDuration(&cfg.OAuth.AccessTokenTTL, "OAUTH_ACCESS_TOKEN_TTL", &errs)
+	envDuration(&cfg.OAuth.CodeTTL, "OAUTH_CODE_TTL", &errs)
+	envDuration(&cfg.OAuth.IDTokenTTL, "OAUTH_ID_TOKEN_TTL", &errs)
+	envString(&cfg.OAuth.Issuer, "OAUTH_ISSUER")
+	envString(&cfg.OAuth.SigningKeyFile, "OAUTH_SIGNING_KEY_FILE")
+
+	applyIdentityEnv(&cfg.Identity)
+
+	envDuration(&cfg.Passwordless.TTL, "PASSWORDLESS_TTL", &errs)
+	envInt(&cfg.Passwordless.MaxAttempts, "PASSWORDLESS_MAX_ATTEMPTS", &errs)
+	envDuration(&cfg.Passwordless.Cooldown, "PASSWORDLESS_COOLDOWN", &errs)
+	envInt(&cfg.Passwordless.IPLimit, "PASSWORDLESS_IP_LIMIT", &errs)
+	envDuration(&cfg.Passwordless.IPWindow, "PASSWORDLESS_IP_WINDOW", &errs)
+	envString(&cfg.Passwordless.LinkURL, "PASSWORDLESS_LINK_URL")
+	envDuration(&cfg.Passwordless.CleanupInterval, "PASSWORDLESS_CLEANUP_INTERVAL", &errs)
+
+	envString(&cfg.WebAuthn.RPID, "WEBAUTHN_RP_ID")
+	envString(&cfg.WebAuthn.RPName, "WEBAUTHN_RP_NAME")
+	envList(&cfg.WebAuthn.Origins, "WEBAUTHN_ORIGINS")
+	envDuration(&cfg.WebAuthn.Timeout, "WEBAUTHN_TIMEOUT", &errs)
+	envBool(&cfg.WebAuthn.RequireForPasswordLogin, "WEBAUTHN_REQUIRE_FOR_PASSWORD_LOGIN", &errs)
+
+	envDuration(&cfg.APITokens.DefaultTTL, "API_TOKEN_DEFAULT_TTL", &errs)
+	envDuration(&cfg.APITokens.MaxTTL, "API_TOKEN_MAX_TTL", &errs)
+	envInt(&cfg.APITokens.MaxPerUser, "API_TOKEN_MAX_PER_USER", &errs)
+
+	envDuration(&cfg.Impersonation.TTL, "IMPERSONATION_TTL", &errs)
+	envList(&cfg.Impersonation.Admins, "IMPERSONATION_ADMINS")
+
+	envBool(&cfg.Email.CanonicalizeProviders, "EMAIL_CANONICALIZE_PROVIDERS", &errs)
+
+	envString(&cfg.Mail.Driver, "MAIL_DRIVER")
+	envString(&cfg.Mail.From, "MAIL_FROM")
+	envString(&cfg.Mail.Host, "SMTP_HOST")
+	envInt(&cfg.Mail.Port, "SMTP_PORT", &errs)
+	envString(&cfg.Mail.Username, "SMTP_USERNAME")
+	envString(&cfg.Mail.Password, "SMTP_PASSWORD")
+
+	envString(&cfg.Log.Level, "LOG_LEVEL")
+	envString(&cfg.Log.Format, "LOG_FORMAT")
+
+	envString(&cfg.Tracing.Exporter, "TRACING_EXPORTER")
+	envString(&cfg.Tracing.Endpoint, "TRACING_ENDPOINT")
+	envBool(&cfg.Tracing.Insecure, "TRACING_INSECURE", &errs)
+	envString(&cfg.Tracing.File, "TRACING_FILE")
+	envFloat(&cfg.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO", &errs)
+	envString(&cfg.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+
+	envString(&cfg.Docs.SwaggerUIURL, "DOCS_SWAGGER_UI_URL")
+	envString(&cfg.Docs.ScriptIntegrity, "DOCS_SCRIPT_INTEGRITY")
+	envString(&cfg.Docs.StyleIntegrity, "DOCS_STYLE_INTEGRITY")
+
+	return errors.Join(errs...)
+}
+
+func envString(dst *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+// envList membaca daftar yang dipisahkan koma; item kosong diabaikan.
+func envList(dst *[]string, key string) {
+	if value := os.Getenv(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+func envInt(dst *int, key string, errs *[]error) {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: invalid integer %q", key, value))
+			return
+		}
+		*dst = n
+	}
+}
+
+func envFloat(dst *float64, key string, errs *[]error) {
+	if value := os.Getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: invalid number %q", key, value))
+			return
+		}
+		*dst = f
+	}
+}
+
+func envBool(dst *bool, key string, errs *[]error) {
+	if value := os.Getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: invalid boolean %q", key, value))
+			return
+		}
+		*dst = b
+	}
+}
+
+func envDuration(dst *time.Duration, key string, errs *[]error) {
+	if value := os.Getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: invalid duration %q (e.g. 24h, 90m)", key, value))
+			return
+		}
+		*dst = d
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// providerNamePattern membatasi nama provider karena dipakai di path URL dan nama variabel lingkungan.
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// IdentityConfig berisi penyedia identitas eksternal (Google, GitHub, IdP OpenID Connect
+// perusahaan) untuk login lewat /api/v1/auth/oauth/:provider.
+type IdentityConfig struct {
+	// Providers dengan kunci nama provider di URL, mis. google untuk /api/v1/auth/oauth/google/start
+	Providers map[string]IdentityProvider `yaml:"providers" toml:"providers"`
+	// ReturnURLs adalah URL frontend yang boleh dipakai sebagai return_to; token dikirim di fragment
+	ReturnURLs []string `yaml:"return_urls" toml:"return_urls"`
+}
+
+// IdentityProvider adalah satu IdP eksternal. IdP OpenID Connect cukup diisi Issuer karena
+// endpoint dibaca dari discovery document; IdP OAuth2 biasa (mis. GitHub) memakai AuthURL,
+// TokenURL, dan UserInfoURL. Endpoint yang diisi eksplisit menimpa hasil discovery.
+type IdentityProvider struct {
+	DisplayName  string   `yaml:"display_name" toml:"display_name"`
+	Issuer       string   `yaml:"issuer" toml:"issuer"`
+	AuthURL      string   `yaml:"auth_url" toml:"auth_url"`
+	TokenURL     string   `yaml:"token_url" toml:"token_url"`
+	UserInfoURL  string   `yaml:"userinfo_url" toml:"userinfo_url"`
+	ClientID     string   `yaml:"client_id" toml:"client_id"`         // IDP_<NAMA>_CLIENT_ID
+	ClientSecret string   `yaml:"client_secret" toml:"client_secret"` // IDP_<NAMA>_CLIENT_SECRET
+	Scopes       []string `yaml:"scopes" toml:"scopes"`
+	// RedirectURL default: OAUTH_ISSUER + /api/v1/auth/oauth/<nama>/callback
+	RedirectURL string `yaml:"redirect_url" toml:"redirect_url"`
+
+	// Nama claim untuk subject, email, dan nama; default sub, email, dan name
+	SubjectClaim string `yaml:"subject_claim" toml:"subject_claim"`
+	EmailClaim   string `yaml:"email_claim" toml:"email_claim"`
+	NameClaim    string `yaml:"name_claim" toml:"name_claim"`
+	// TrustEmail menganggap email dari provider sudah terverifikasi walau tanpa claim email_verified
+	TrustEmail bool `yaml:"trust_email" toml:"trust_email"`
+
+	// AutoProvision membuat akun baru (just-in-time) untuk pengguna yang belum terdaftar
+	AutoProvision bool `yaml:"auto_provision" toml:"auto_provision"`
+	// AllowedDomains membatasi provisioning ke domain email tertentu; kosong berarti semua domain
+	AllowedDomains []string `yaml:"allowed_domains" toml:"allowed_domains"`
+	// LinkByEmail menautkan identitas ke akun yang sudah ada dengan email terverifikasi yang sama
+	LinkByEmail bool `yaml:"link_by_email" toml:"link_by_email"`
+}
+
+// Names mengembalikan nama provider yang terurut.
+func (cfg IdentityConfig) Names() []string {
+	names := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate memeriksa setiap provider dan daftar return_to.
+func (cfg IdentityConfig) validate() error {
+	var errs []error
+	for _, name := range cfg.Names() {
+		provider := cfg.Providers[name]
+		if !providerNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("identity provider name %q must match %s", name, providerNamePattern))
+			continue
+		}
+		if provider.ClientID == "" {
+			errs = append(errs, fmt.Errorf("identity provider %q: %s is required", name, providerEnv(name, "CLIENT_ID")))
+		}
+		if provider.Issuer == "" && (provider.AuthURL == "" || provider.TokenURL == "" || provider.UserInfoURL == "") {
+			errs = append(errs, fmt.Errorf("identity provider %q: set issuer, or auth_url, token_url and userinfo_url", name))
+		}
+		for _, raw := range []string{provider.Issuer, provider.AuthURL, provider.TokenURL, provider.UserInfoURL, provider.RedirectURL} {
+			if raw == "" {
+				continue
+			}
+			if u, err := url.Parse(raw); err != nil || !u.IsAbs() || u.Host == "" {
+				errs = append(errs, fmt.Errorf("identity provider %q: %q must be an absolute URL", name, raw))
+			}
+		}
+	}
+	for _, raw := range cfg.ReturnURLs {
+		if u, err := url.Parse(raw); err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+			errs = append(errs, fmt.Errorf("identity return URL %q must be an absolute URL without fragment", raw))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyIdentityEnv menimpa client ID dan secret provider yang terdaftar di file konfigurasi
+// dengan IDP_<NAMA>_CLIENT_ID dan IDP_<NAMA>_CLIENT_SECRET, agar secret tidak perlu disimpan di file.
+func applyIdentityEnv(cfg *IdentityConfig) {
+	for name, provider := range cfg.Providers {
+		envString(&provider.ClientID, providerEnv(name, "CLIENT_ID"))
+		envString(&provider.ClientSecret, providerEnv(name, "CLIENT_SECRET"))
+		cfg.Providers[name] = provider
+	}
+}
+
+// providerEnv membentuk nama variabel lingkungan untuk provider, mis. IDP_ACME_SSO_CLIENT_SECRET.
+func providerEnv(name, key string) string {
+	return "IDP_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_" + key
+}
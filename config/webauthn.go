@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebAuthnConfig berisi pengaturan relying party untuk passkey di /api/v1/auth/webauthn.
+type WebAuthnConfig struct {
+	// RPID adalah domain tempat passkey terikat; kosong berarti host OAUTH_ISSUER. Passkey
+	// tidak bisa dipakai lagi jika RPID diganti.
+	RPID   string `yaml:"rp_id" toml:"rp_id"`
+	RPName string `yaml:"rp_name" toml:"rp_name"` // Nama yang ditampilkan authenticator
+	// Origins adalah origin frontend yang menjalankan ceremony; kosong berarti origin OAUTH_ISSUER
+	Origins []string      `yaml:"origins" toml:"origins"`
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"` // Batas waktu ceremony di browser dan server
+	// RequireForPasswordLogin mewajibkan pengguna yang punya passkey mengonfirmasi login
+	// password dengan passkey sebagai faktor kedua
+	RequireForPasswordLogin bool `yaml:"require_for_password_login" toml:"require_for_password_login"`
+}
+
+// RelyingParty mengembalikan RP ID dan origin efektif, dengan default dari issuer.
+func (cfg WebAuthnConfig) RelyingParty(issuer string) (rpID string, origins []string) {
+	rpID, origins = cfg.RPID, cfg.Origins
+	if u, err := url.Parse(issuer); err == nil && u.Host != "" {
+		if rpID == "" {
+			rpID = u.Hostname()
+		}
+		if len(origins) == 0 {
+			origins = []string{u.Scheme + "://" + u.Host}
+		}
+	}
+	return rpID, origins
+}
+
+func (cfg WebAuthnConfig) validate(issuer string) error {
+	var errs []error
+	if cfg.Timeout < 30*time.Second || cfg.Timeout > 10*time.Minute {
+		errs = append(errs, fmt.Errorf("WEBAUTHN_TIMEOUT must be between 30s and 10m, got %s", cfg.Timeout))
+	}
+
+	rpID, origins := cfg.RelyingParty(issuer)
+	if rpID == "" || strings.ContainsAny(rpID, ":/") {
+		errs = append(errs, fmt.Errorf("WEBAUTHN_RP_ID must be a domain name, got %q", rpID))
+	}
+	for _, origin := range origins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host == "" || u.Path != "" || (u.Scheme != "https" && u.Scheme != "http") {
+			errs = append(errs, fmt.Errorf("WEBAUTHN_ORIGINS: %q must be a scheme://host[:port] origin", origin))
+			continue
+		}
+		// Browser menolak RP ID yang bukan domain origin atau salah satu induknya
+		if host := u.Hostname(); host != rpID && !strings.HasSuffix(host, "."+rpID) {
+			errs = append(errs, fmt.Errorf("WEBAUTHN_ORIGINS: %q is not within WEBAUTHN_RP_ID %q", origin, rpID))
+		}
+	}
+	return errors.Join(errs...)
+}
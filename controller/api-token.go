@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APITokenController menangani token akses pribadi di bawah /api/v1/auth/tokens.
+type APITokenController struct {
+	apiTokens *service.APITokenService
+	auth      *service.AuthService
+}
+
+// NewAPITokenController membuat APITokenController.
+func NewAPITokenController(apiTokens *service.APITokenService, auth *service.AuthService) *APITokenController {
+	return &APITokenController{apiTokens: apiTokens, auth: auth}
+}
+
+// Create membuat token akses pribadi. Nilai token hanya ada di respons ini.
+func (ctl *APITokenController) Create(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	var req model.APITokenRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	token, value, err := ctl.apiTokens.Create(c.UserContext(), user, req)
+	if err != nil {
+		return err
+	}
+	noStore(c)
+	return response.Created(c, "API token created, copy it now because it will not be shown again", model.APITokenCreatedDTO{
+		Token:    value,
+		APIToken: newAPITokenResponse(token),
+	})
+}
+
+// List mengembalikan token akses pribadi milik pengguna yang sedang login.
+func (ctl *APITokenController) List(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	tokens, err := ctl.apiTokens.List(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]model.APITokenResponseDTO, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, newAPITokenResponse(token))
+	}
+	return response.OK(c, "API tokens fetched successfully", resp)
+}
+
+// Revoke mencabut token akses pribadi milik pengguna yang sedang login.
+func (ctl *APITokenController) Revoke(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperror.BadRequest(apperror.CodeValidationFailed, "API token ID is required")
+	}
+
+	if err := ctl.apiTokens.Revoke(c.UserContext(), user.ID, uint(id)); err != nil {
+		return err
+	}
+	return response.OK(c, "API token revoked successfully", nil)
+}
+
+func newAPITokenResponse(token model.APIToken) model.APITokenResponseDTO {
+	return model.APITokenResponseDTO{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     model.SplitScope(token.Scopes),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -1,117 +1,103 @@
 package controller
 
 import (
-	"fmt"
-	"go-fiber-user-management/database"
+	"go-fiber-user-management/apperror"
 	"go-fiber-user-management/model"
-	"go-fiber-user-management/utils"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/golang-jwt/jwt"
 )
 
+// AuthController menangani rute autentikasi di bawah /api/v1/auth.
+type AuthController struct {
+	auth *service.AuthService
+}
+
+// NewAuthController membuat AuthController dengan AuthService yang diberikan.
+func NewAuthController(auth *service.AuthService) *AuthController {
+	return &AuthController{auth: auth}
+}
+
 // Register menangani pendaftaran pengguna dengan membuat pengguna baru di database.
-func Register(c *fiber.Ctx) error {
+func (ctl *AuthController) Register(c *fiber.Ctx) error {
 	var req model.UserRequestDTO // Gunakan UserRequestDTO untuk parsing body permintaan
 
 	// Parsing body permintaan ke dalam struct UserRequestDTO
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request payload",
-		})
-	}
-
-	// Periksa apakah email sudah ada di database menggunakan findUserByEmail
-	if _, err := findUserByEmail(req.Email); err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error":   true,
-			"message": "Email already exists",
-		})
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
 	}
 
-	// Validasi manual untuk password
-	if req.Password == "" || len(req.Password) < 6 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Password is required and must be at least 6 characters long",
-		})
-	}
-
-	// Hash password
-	hashedPassword := utils.GeneratePassword(req.Password)
-
-	// Membuat objek pengguna baru dengan data dari DTO
-	user := model.User{
-		Email:        req.Email,
-		PasswordHash: hashedPassword,
-		Fullname:     req.Fullname,
-		Address:      req.Address,
-		Gender:       req.Gender,
-		PhoneNumber:  req.PhoneNumber,
-	}
-
-	// Simpan pengguna baru ke database
-	if err := database.DB.Create(&user).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create user"})
+	user, err := ctl.auth.Register(c.UserContext(), req)
+	if err != nil {
+		return err
 	}
 
 	// Respons sukses
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": "User successfully created",
-		"data":    user,
-	})
+	return response.Created(c, "User successfully created", newUserResponse(user))
 }
 
 // Login menangani login pengguna dan menghasilkan token JWT untuk pengguna yang terautentikasi.
-func Login(c *fiber.Ctx) error {
+func (ctl *AuthController) Login(c *fiber.Ctx) error {
 	var req model.AuthenticationRequest // Gunakan model.AuthenticationRequest
 
 	// Parsing body permintaan yang masuk ke dalam struktur authenticationRequest.
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request payload"})
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
 	}
 
-	// Mengambil pengguna berdasarkan email.
-	user, err := findUserByEmail(req.Email)
+	token, scope, err := ctl.auth.Login(c.UserContext(), req)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": err.Error()})
+		return err
 	}
 
-	// Memvalidasi password yang diberikan oleh pengguna.
-	if !utils.ComparePassword(user.PasswordHash, req.Password) {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Password salah",
-		})
+	// Mengirimkan token yang dihasilkan setelah login berhasil.
+	return response.OK(c, "Login successful", fiber.Map{
+		"token": token,
+		"scope": scope,
+	})
+}
+
+// Exchange menukar token yang dipakai dengan token baru yang scope-nya lebih sempit.
+func (ctl *AuthController) Exchange(c *fiber.Ctx) error {
+	var req model.TokenExchangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
 	}
 
-	// Menghasilkan token JWT untuk pengguna yang terautentikasi.
-	token, err := utils.GenerateToken(user)
+	token, scope, err := ctl.auth.Exchange(c.UserContext(), c.Locals("jwt").(jwt.MapClaims), c.Locals("user").(model.User), req.Scope)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Gagal menghasilkan token",
-		})
+		return err
 	}
-
-	// Mengirimkan token yang dihasilkan setelah login berhasil.
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"token":   token,
+	return response.OK(c, "Token exchanged successfully", fiber.Map{
+		"token": token,
+		"scope": scope,
 	})
 }
 
 // GetUserInfo mengambil informasi pengguna berdasarkan klaim JWT.
-func GetUserInfo(c *fiber.Ctx) error {
+func (ctl *AuthController) GetUserInfo(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+
+	// Token impersonation menampilkan admin yang sedang bertindak sebagai pengguna ini
+	resp := newUserResponse(user)
+	if actor, ok := service.ActorFromClaims(c.Locals("jwt").(jwt.MapClaims)); ok {
+		resp.Act = &actor
+	}
+
+	// Mengirimkan detail pengguna beserta ETag untuk If-Match.
+	c.Set(fiber.HeaderETag, userETag(user))
+	return response.OK(c, "User info fetched successfully", resp)
+}
+
+// currentUser mengambil profil pemilik token dari klaim JWT yang disimpan JWTAuthorization.
+func currentUser(c *fiber.Ctx, auth *service.AuthService) (model.User, error) {
 	// Mengambil klaim JWT dari konteks.
 	claims := c.Locals("jwt").(jwt.MapClaims)
 
@@ -120,80 +106,40 @@ func GetUserInfo(c *fiber.Ctx) error {
 	email, okEmail := claims["email"].(string)
 
 	if !okID || !okEmail {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Klaim token tidak valid",
-		})
+		return model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "Klaim token tidak valid")
 	}
 
-	// Mengambil detail pengguna dari database menggunakan ID dan email.
-	var user model.User
-	if err := database.DB.Where("id = ? AND email = ?", userID, email).First(&user).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Pengguna tidak ditemukan",
-		})
-	}
+	return auth.Profile(c.UserContext(), uint(userID), email)
+}
 
-	// Membuat instance UserResponseDTO untuk respons tanpa password.
-	userResponse := model.UserResponseDTO{
-		ID:          user.ID,
-		Email:       user.Email,
-		Fullname:    user.Fullname,
-		Address:     user.Address,
-		Gender:      user.Gender,
-		PhoneNumber: user.PhoneNumber,
+// Penanganan ketika user logout
+func (ctl *AuthController) Logout(c *fiber.Ctx) error {
+	tokenString, err := bearerToken(c)
+	if err != nil {
+		return err
 	}
 
-	// Mengirimkan detail pengguna.
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"user":    userResponse,
-	})
-}
-
-// findUserByEmail mencari pengguna berdasarkan alamat email mereka.
-func findUserByEmail(email string) (model.User, error) {
-	var user model.User
-	response := database.DB.Where("email = ?", email).First(&user)
-	if response.Error != nil {
-		return user, fmt.Errorf("pengguna tidak ditemukan")
+	// Simpan token yang dibatalkan ke dalam basis data
+	if err := ctl.auth.Logout(c.UserContext(), tokenString); err != nil {
+		return err
 	}
-	return user, nil
+
+	return response.OK(c, "Successfully logged out", nil)
 }
 
-// Penanganan ketika user logout
-func Logout(c *fiber.Ctx) error {
+// bearerToken mengambil token dari header Authorization dengan format "Bearer <token>".
+func bearerToken(c *fiber.Ctx) (string, error) {
 	// Get token from Authorization header
 	tokenString := c.Get("Authorization")
 	if tokenString == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Missing or malformed JWT",
-		})
+		return "", apperror.Unauthorized(apperror.CodeTokenMissing, "Missing or malformed JWT")
 	}
 
 	// Memisahkan token dari kata "Bearer"
-	if strings.HasPrefix(tokenString, "Bearer ") {
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-	} else {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid Authorization format",
-		})
+	if !strings.HasPrefix(tokenString, "Bearer ") {
+		return "", apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid Authorization format")
 	}
-
-	// Simpan token yang dibatalkan ke dalam basis data
-	revokedToken := model.RevokedToken{Token: tokenString}
-	if err := database.DB.Create(&revokedToken).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to revoke token",
-		})
-	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": true,
-		"message": "Successfully logged out",
-	})
+	// Disalin karena nilai header Fiber memakai buffer yang dipakai ulang setelah request selesai,
+	// sedangkan token disimpan (mis. di daftar token yang dibatalkan)
+	return utils.CopyString(strings.TrimPrefix(tokenString, "Bearer ")), nil
 }
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// userETag membentuk nilai header ETag dari versi pengguna.
+func userETag(user model.User) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// ifMatch membuat precondition yang membandingkan header If-Match dengan versi pengguna saat ini.
+// Jika gagal, ETag terbaru ikut dikirim agar klien bisa mengambil ulang data.
+func (ctl *UserController) ifMatch(c *fiber.Ctx) service.Precondition {
+	return func(user model.User) error {
+		if err := checkIfMatch(c.Get(fiber.HeaderIfMatch), user, ctl.requireIfMatch); err != nil {
+			c.Set(fiber.HeaderETag, userETag(user))
+			return err
+		}
+		return nil
+	}
+}
+
+// checkIfMatch memvalidasi nilai header If-Match terhadap ETag pengguna.
+// required menentukan apakah header yang kosong ditolak dengan 428.
+func checkIfMatch(header string, user model.User, required bool) error {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		if required {
+			return apperror.New(fiber.StatusPreconditionRequired, apperror.CodePreconditionRequired, "If-Match header is required")
+		}
+		return nil
+	}
+	if header == "*" {
+		return nil
+	}
+
+	// Header dapat berisi beberapa ETag yang dipisahkan koma
+	current := userETag(user)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == current {
+			return nil
+		}
+	}
+	return service.VersionConflictError()
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/health"
+	"go-fiber-user-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthController menangani probe liveness/readiness dan laporan kesehatan untuk admin.
+type HealthController struct {
+	checker *health.Checker
+}
+
+// NewHealthController membuat HealthController dengan Checker yang diberikan.
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{checker: checker}
+}
+
+// Liveness menandakan proses masih berjalan; tidak memeriksa dependensi apa pun.
+func (ctl *HealthController) Liveness(c *fiber.Ctx) error {
+	return response.OK(c, "Service is alive", fiber.Map{"status": health.StatusUp})
+}
+
+// Readiness mengembalikan 503 jika ada komponen yang gagal agar instance tidak menerima trafik.
+// Pesan error komponen tidak dikirim karena endpoint ini publik; lihat Report untuk detailnya.
+func (ctl *HealthController) Readiness(c *fiber.Ctx) error {
+	report := ctl.checker.Check(c.UserContext())
+
+	components := make(fiber.Map, len(report.Components))
+	for _, component := range report.Components {
+		components[component.Name] = component.Status
+	}
+
+	if report.Status != health.StatusUp {
+		return apperror.New(fiber.StatusServiceUnavailable, apperror.CodeNotReady, "Service is not ready").
+			With("components", components)
+	}
+	return response.OK(c, "Service is ready", fiber.Map{
+		"status":     report.Status,
+		"components": components,
+	})
+}
+
+// Report mengembalikan laporan kesehatan lengkap beserta latensi dan error setiap komponen.
+// Selalu 200 karena laporan berhasil dibuat; status layanan ada di data.status.
+func (ctl *HealthController) Report(c *fiber.Ctx) error {
+	return response.OK(c, "Health report fetched successfully", ctl.checker.Check(c.UserContext()))
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// IdentityController menangani login lewat IdP eksternal di bawah /api/v1/auth/oauth dan
+// pengelolaan identitas tertaut di /api/v1/auth/identities.
+type IdentityController struct {
+	identities *service.IdentityService
+	auth       *service.AuthService
+	// secureCookie mengirim cookie state hanya lewat HTTPS, sesuai skema URL publik server
+	secureCookie bool
+}
+
+// NewIdentityController membuat IdentityController. issuer adalah URL publik server.
+func NewIdentityController(identities *service.IdentityService, auth *service.AuthService, issuer string) *IdentityController {
+	return &IdentityController{
+		identities:   identities,
+		auth:         auth,
+		secureCookie: strings.HasPrefix(issuer, "https://"),
+	}
+}
+
+// Providers mengembalikan provider login yang dikonfigurasi untuk ditampilkan di halaman login.
+func (ctl *IdentityController) Providers(c *fiber.Ctx) error {
+	return response.OK(c, "Identity providers fetched successfully", ctl.identities.Providers())
+}
+
+// Start mengalihkan browser ke IdP. return_to opsional adalah URL frontend yang menerima
+// hasil login di fragment.
+func (ctl *IdentityController) Start(c *fiber.Ctx) error {
+	authURL, state, err := ctl.identities.Start(c.UserContext(), c.Params("provider"), 0, c.Query("return_to"))
+	if err != nil {
+		return err
+	}
+
+	ctl.setStateCookie(c, state)
+	noStore(c)
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Link memulai penautan IdP ke akun yang sedang login. URL IdP dikembalikan di body karena
+// permintaan ini dikirim frontend dengan token, bukan navigasi browser.
+func (ctl *IdentityController) Link(c *fiber.Ctx) error {
+	var req model.IdentityLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+		}
+	}
+
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	authURL, state, err := ctl.identities.Start(c.UserContext(), c.Params("provider"), user.ID, req.ReturnTo)
+	if err != nil {
+		return err
+	}
+
+	ctl.setStateCookie(c, state)
+	noStore(c)
+	return response.OK(c, "Open authorization_url to link the identity", model.IdentityLinkResponse{AuthorizationURL: authURL})
+}
+
+// Callback menerima pengguna kembali dari IdP. Tanpa return_to hasilnya dikirim sebagai JSON;
+// dengan return_to browser dialihkan ke frontend dengan token atau error di fragment.
+func (ctl *IdentityController) Callback(c *fiber.Ctx) error {
+	// Nilai query Fiber tidak aman disimpan setelah handler selesai, sehingga disalin
+	params := service.IdentityCallback{
+		Code:             utils.CopyString(c.Query("code")),
+		State:            utils.CopyString(c.Query("state")),
+		Error:            utils.CopyString(c.Query("error")),
+		ErrorDescription: utils.CopyString(c.Query("error_description")),
+	}
+	cookie := c.Cookies(service.IdentityStateCookie)
+	// State hanya boleh dipakai sekali
+	ctl.setStateCookie(c, "")
+	noStore(c)
+
+	result, err := ctl.identities.Callback(c.UserContext(), c.Params("provider"), params, cookie)
+	if result.ReturnTo != "" {
+		return c.Redirect(callbackLocation(c, result, err), fiber.StatusFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	message := "Login successful"
+	if result.Token == "" {
+		message = "Identity linked successfully"
+	}
+	return response.OK(c, message, model.IdentityCallbackResponse{
+		Token:    result.Token,
+		Created:  result.Created,
+		Linked:   result.Linked,
+		Identity: newIdentityResponse(result.Identity),
+	})
+}
+
+// Identities mengembalikan identitas eksternal yang tertaut ke akun yang sedang login.
+func (ctl *IdentityController) Identities(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	identities, err := ctl.identities.Identities(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]model.IdentityResponseDTO, 0, len(identities))
+	for _, identity := range identities {
+		resp = append(resp, newIdentityResponse(identity))
+	}
+	return response.OK(c, "Identities fetched successfully", resp)
+}
+
+// Unlink melepas identitas provider dari akun yang sedang login.
+func (ctl *IdentityController) Unlink(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	if err := ctl.identities.Unlink(c.UserContext(), user, c.Params("provider")); err != nil {
+		return err
+	}
+	return response.OK(c, "Identity unlinked successfully", nil)
+}
+
+// setStateCookie menyimpan state alur IdP; value kosong menghapus cookie. SameSite=Lax agar
+// cookie ikut terkirim saat IdP mengalihkan browser kembali ke callback.
+func (ctl *IdentityController) setStateCookie(c *fiber.Ctx, value string) {
+	cookie := &fiber.Cookie{
+		Name:     service.IdentityStateCookie,
+		Value:    value,
+		Path:     "/api", // Mencakup /api/v1 dan alias /api tanpa versi
+		MaxAge:   int(service.IdentityStateTTL / time.Second),
+		Secure:   ctl.secureCookie,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge, cookie.Expires = 0, time.Unix(0, 0)
+	}
+	c.Cookie(cookie)
+}
+
+// callbackLocation membentuk URL return_to dengan hasil callback di fragment, sehingga token
+// tidak terkirim ke server frontend maupun tercatat di log akses.
+func callbackLocation(c *fiber.Ctx, result service.IdentityResult, err error) string {
+	params := url.Values{"provider": {c.Params("provider")}}
+	if err != nil {
+		// Error tidak melewati apperror.Handler sehingga error server dicatat di sini
+		appErr := apperror.From(err)
+		if appErr.Status >= fiber.StatusInternalServerError {
+			slog.ErrorContext(c.UserContext(), "request failed",
+				"method", c.Method(), "path", c.Path(), "code", appErr.Code, "error", err)
+		}
+		params = url.Values{"error": {appErr.Code}, "error_description": {appErr.Message}}
+	} else if result.Token != "" {
+		params.Set("token", result.Token)
+		params.Set("created", strconv.FormatBool(result.Created))
+	} else {
+		params.Set("linked", strconv.FormatBool(result.Linked))
+	}
+	return result.ReturnTo + "#" + params.Encode()
+}
+
+func newIdentityResponse(identity model.UserIdentity) model.IdentityResponseDTO {
+	return model.IdentityResponseDTO{
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
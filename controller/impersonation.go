@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+// ImpersonationController menangani impersonation pengguna oleh admin dan audit trail-nya.
+type ImpersonationController struct {
+	impersonation *service.ImpersonationService
+}
+
+// NewImpersonationController membuat ImpersonationController.
+func NewImpersonationController(impersonation *service.ImpersonationService) *ImpersonationController {
+	return &ImpersonationController{impersonation: impersonation}
+}
+
+// Start menerbitkan token untuk bertindak sebagai pengguna :id.
+func (ctl *ImpersonationController) Start(c *fiber.Ctx) error {
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
+	}
+	var req model.ImpersonationRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	actor := c.Locals("user").(model.User)
+	session, err := ctl.impersonation.Start(c.UserContext(), actor, id, req, c.IP())
+	if err != nil {
+		return err
+	}
+	noStore(c)
+	return response.Created(c, "Impersonation started", model.ImpersonationResponseDTO{
+		Token:     session.Token,
+		SessionID: session.SessionID,
+		ExpiresAt: session.ExpiresAt,
+		User:      newUserResponse(session.User),
+	})
+}
+
+// End mengakhiri sesi impersonation dari token yang dipakai.
+func (ctl *ImpersonationController) End(c *fiber.Ctx) error {
+	token, err := bearerToken(c)
+	if err != nil {
+		return err
+	}
+
+	claims := c.Locals("jwt").(jwt.MapClaims)
+	if err := ctl.impersonation.End(c.UserContext(), claims, token, c.IP()); err != nil {
+		return err
+	}
+	return response.OK(c, "Impersonation ended", nil)
+}
+
+// Events mengembalikan audit trail, dapat difilter dengan ?action=, ?actor_id=, ?subject_id=,
+// ?session_id=, dan dibatasi dengan ?limit=.
+func (ctl *ImpersonationController) Events(c *fiber.Ctx) error {
+	var query struct {
+		Action    string `query:"action"`
+		ActorID   uint   `query:"actor_id"`
+		SubjectID uint   `query:"subject_id"`
+		SessionID string `query:"session_id"`
+		Limit     int    `query:"limit"`
+	}
+	if err := c.QueryParser(&query); err != nil {
+		return apperror.BadRequest(apperror.CodeValidationFailed, "Invalid query parameters")
+	}
+
+	events, err := ctl.impersonation.Events(c.UserContext(), model.AuditFilter{
+		Action:    query.Action,
+		ActorID:   query.ActorID,
+		SubjectID: query.SubjectID,
+		SessionID: query.SessionID,
+		Limit:     query.Limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp := make([]model.AuditEventResponseDTO, 0, len(events))
+	for _, event := range events {
+		resp = append(resp, model.AuditEventResponseDTO{
+			ID:        event.ID,
+			Action:    event.Action,
+			ActorID:   event.ActorID,
+			SubjectID: event.SubjectID,
+			SessionID: event.SessionID,
+			Detail:    event.Detail,
+			IP:        event.IP,
+			RequestID: event.RequestID,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return response.OK(c, "Audit events fetched successfully", resp)
+}
@@ -0,0 +1,326 @@
+package controller
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+//go:embed oauth.html
+var oauthPage string
+
+// oauthTemplate berisi halaman consent dan halaman error authorization endpoint.
+var oauthTemplate = template.Must(template.New("oauth").Parse(oauthPage))
+
+// consentPage adalah data untuk oauth.html. Client kosong berarti halaman error.
+type consentPage struct {
+	Client  string
+	Scopes  []string
+	Request model.OAuthAuthorizationRequest
+	Email   string
+	Error   string
+}
+
+// OAuthController menangani endpoint server otorisasi OAuth2 di bawah /oauth dan
+// pendaftaran klien oleh admin di /api/v1/oauth/clients.
+type OAuthController struct {
+	oauth *service.OAuthService
+	auth  *service.AuthService
+}
+
+// NewOAuthController membuat OAuthController. AuthService dipakai untuk login di halaman consent.
+func NewOAuthController(oauth *service.OAuthService, auth *service.AuthService) *OAuthController {
+	return &OAuthController{oauth: oauth, auth: auth}
+}
+
+// RegisterClient mendaftarkan klien baru. Client secret hanya dikirim di response ini.
+func (ctl *OAuthController) RegisterClient(c *fiber.Ctx) error {
+	var req model.OAuthClientRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	client, secret, err := ctl.oauth.RegisterClient(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	resp := newOAuthClientResponse(client)
+	resp.ClientSecret = secret
+	return response.Created(c, "Client registered successfully", resp)
+}
+
+// ListClients mengembalikan semua klien terdaftar tanpa secret.
+func (ctl *OAuthController) ListClients(c *fiber.Ctx) error {
+	clients, err := ctl.oauth.ListClients(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]model.OAuthClientResponseDTO, 0, len(clients))
+	for _, client := range clients {
+		resp = append(resp, newOAuthClientResponse(client))
+	}
+	return response.OK(c, "Clients fetched successfully", resp)
+}
+
+// Authorize menampilkan halaman consent untuk permintaan authorization code yang valid.
+func (ctl *OAuthController) Authorize(c *fiber.Ctx) error {
+	denyFraming(c)
+	var req model.OAuthAuthorizationRequest
+	if err := c.QueryParser(&req); err != nil {
+		return renderOAuthPage(c, fiber.StatusBadRequest, consentPage{Error: "Invalid authorization request"})
+	}
+
+	client, scope, err := ctl.oauth.Authorize(c.UserContext(), req)
+	if err != nil {
+		return authorizationFailed(c, err)
+	}
+	return renderOAuthPage(c, fiber.StatusOK, consentPage{Client: client.Name, Scopes: strings.Fields(scope), Request: req})
+}
+
+// Decide memproses form consent: menolak, atau login lalu menerbitkan kode otorisasi.
+func (ctl *OAuthController) Decide(c *fiber.Ctx) error {
+	denyFraming(c)
+	var req model.OAuthAuthorizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return renderOAuthPage(c, fiber.StatusBadRequest, consentPage{Error: "Invalid authorization request"})
+	}
+	req = copyAuthorizationRequest(req)
+
+	if c.FormValue("decision") != "approve" {
+		location, err := ctl.oauth.Deny(c.UserContext(), req)
+		if err != nil {
+			return authorizationFailed(c, err)
+		}
+		return c.Redirect(location, fiber.StatusFound)
+	}
+
+	client, scope, err := ctl.oauth.Authorize(c.UserContext(), req)
+	if err != nil {
+		return authorizationFailed(c, err)
+	}
+
+	email := c.FormValue("email")
+	user, err := ctl.auth.Authenticate(c.UserContext(), model.AuthenticationRequest{Email: email, Password: c.FormValue("password")})
+	if err != nil {
+		// Kredensial salah menampilkan kembali halaman consent dengan pesan error
+		appErr := apperror.From(err)
+		if appErr.Status >= fiber.StatusInternalServerError {
+			return err
+		}
+		// Halaman consent belum bisa menjalankan ceremony passkey; mfa_token tidak ditampilkan
+		if appErr.Code == apperror.CodeSecondFactorRequired {
+			appErr = apperror.Forbidden(apperror.CodeSecondFactorRequired,
+				"This account is protected by a passkey, which this sign-in page does not support yet. "+
+					"Ask the application to let you sign in with your passkey instead.")
+		}
+		return renderOAuthPage(c, appErr.Status, consentPage{
+			Client: client.Name, Scopes: strings.Fields(scope), Request: req, Email: email, Error: appErr.Message,
+		})
+	}
+
+	location, err := ctl.oauth.Approve(c.UserContext(), req, user)
+	if err != nil {
+		return authorizationFailed(c, err)
+	}
+	return c.Redirect(location, fiber.StatusFound)
+}
+
+// Token menangani token endpoint (RFC 6749 bagian 3.2) untuk semua grant type.
+func (ctl *OAuthController) Token(c *fiber.Ctx) error {
+	var req model.OAuthTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return oauthFailed(c, apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload"))
+	}
+
+	client, err := ctl.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return oauthFailed(c, err)
+	}
+
+	token, err := ctl.oauth.Token(c.UserContext(), client, req)
+	if err != nil {
+		return oauthFailed(c, err)
+	}
+	noStore(c)
+	return c.JSON(token)
+}
+
+// Introspect menangani introspection endpoint (RFC 7662).
+func (ctl *OAuthController) Introspect(c *fiber.Ctx) error {
+	var req model.OAuthTokenActionRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthFailed(c, apperror.BadRequest(apperror.CodeInvalidRequest, "token is required"))
+	}
+
+	client, err := ctl.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return oauthFailed(c, err)
+	}
+
+	result, err := ctl.oauth.Introspect(c.UserContext(), client, req.Token)
+	if err != nil {
+		return oauthFailed(c, err)
+	}
+	noStore(c)
+	return c.JSON(result)
+}
+
+// Revoke menangani revocation endpoint (RFC 7009). Response sukses selalu 200 tanpa body,
+// termasuk untuk token yang tidak dikenal.
+func (ctl *OAuthController) Revoke(c *fiber.Ctx) error {
+	var req model.OAuthTokenActionRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return oauthFailed(c, apperror.BadRequest(apperror.CodeInvalidRequest, "token is required"))
+	}
+
+	client, err := ctl.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return oauthFailed(c, err)
+	}
+
+	// Token disimpan di daftar revocation; nilai form Fiber menunjuk ke buffer yang dipakai ulang
+	if err := ctl.oauth.Revoke(c.UserContext(), client, utils.CopyString(req.Token)); err != nil {
+		return oauthFailed(c, err)
+	}
+	noStore(c)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// copyAuthorizationRequest menyalin nilai form sebelum disimpan bersama kode otorisasi,
+// karena string dari Fiber menunjuk ke buffer request yang dipakai ulang.
+func copyAuthorizationRequest(req model.OAuthAuthorizationRequest) model.OAuthAuthorizationRequest {
+	return model.OAuthAuthorizationRequest{
+		ResponseType:        utils.CopyString(req.ResponseType),
+		ClientID:            utils.CopyString(req.ClientID),
+		RedirectURI:         utils.CopyString(req.RedirectURI),
+		Scope:               utils.CopyString(req.Scope),
+		State:               utils.CopyString(req.State),
+		CodeChallenge:       utils.CopyString(req.CodeChallenge),
+		CodeChallengeMethod: utils.CopyString(req.CodeChallengeMethod),
+		Nonce:               utils.CopyString(req.Nonce),
+	}
+}
+
+// authenticateClient mengambil kredensial klien dari header Authorization Basic atau dari
+// field form client_id/client_secret (RFC 6749 bagian 2.3.1).
+func (ctl *OAuthController) authenticateClient(c *fiber.Ctx, clientID, secret string) (model.OAuthClient, error) {
+	if header := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(header, "Basic ") {
+		id, pass, ok := basicCredentials(strings.TrimPrefix(header, "Basic "))
+		if !ok || (clientID != "" && clientID != id) {
+			return model.OAuthClient{}, apperror.Unauthorized(apperror.CodeInvalidClient, "Malformed client credentials")
+		}
+		clientID, secret = id, pass
+	}
+	return ctl.oauth.AuthenticateClient(c.UserContext(), clientID, secret)
+}
+
+// basicCredentials membaca client_id dan secret dari HTTP Basic. Keduanya di-encode
+// application/x-www-form-urlencoded sebelum base64.
+func basicCredentials(encoded string) (string, string, bool) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return "", "", false
+	}
+	id, errID := url.QueryUnescape(id)
+	secret, errSecret := url.QueryUnescape(secret)
+	return id, secret, errID == nil && errSecret == nil
+}
+
+// oauthFailed mengirim error dalam format RFC 6749 bagian 5.2, bukan problem+json,
+// karena klien OAuth2 mengharapkan field error dan error_description.
+func oauthFailed(c *fiber.Ctx, err error) error {
+	appErr := apperror.From(err)
+	code := appErr.Code
+	if appErr.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "request failed",
+			"method", c.Method(), "path", c.Path(), "code", appErr.Code, "error", err)
+		code = "server_error"
+	}
+	if code == apperror.CodeInvalidClient {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+
+	noStore(c)
+	return c.Status(appErr.Status).JSON(model.OAuthError{Error: code, ErrorDescription: appErr.Message})
+}
+
+// authorizationFailed me-redirect error ke klien jika redirect_uri sudah terbukti valid,
+// dan menampilkan halaman error jika tidak (RFC 6749 bagian 4.1.2.1).
+func authorizationFailed(c *fiber.Ctx, err error) error {
+	var redirect *service.AuthorizationError
+	if errors.As(err, &redirect) {
+		return c.Redirect(redirect.Location(), fiber.StatusFound)
+	}
+
+	appErr := apperror.From(err)
+	if appErr.Status >= fiber.StatusInternalServerError {
+		return err
+	}
+	return renderOAuthPage(c, appErr.Status, consentPage{Error: appErr.Message})
+}
+
+// renderOAuthPage mengirim halaman HTML authorization endpoint.
+func renderOAuthPage(c *fiber.Ctx, status int, page consentPage) error {
+	var buf bytes.Buffer
+	if err := oauthTemplate.ExecuteTemplate(&buf, "layout", page); err != nil {
+		return apperror.Internal(err, "Failed to render page")
+	}
+
+	noStore(c)
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(status).Send(buf.Bytes())
+}
+
+// denyFraming melarang setiap response authorization endpoint dimuat di frame, termasuk
+// halaman error dan redirect, agar tombol Approve tidak bisa di-clickjack.
+func denyFraming(c *fiber.Ctx) {
+	c.Set(fiber.HeaderXFrameOptions, "DENY")
+	c.Set(fiber.HeaderContentSecurityPolicy, "frame-ancestors 'none'")
+}
+
+// noStore mencegah token dan kredensial disimpan di cache (RFC 6749 bagian 5.1).
+func noStore(c *fiber.Ctx) {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+}
+
+// newOAuthClientResponse membentuk DTO klien tanpa secret.
+func newOAuthClientResponse(client model.OAuthClient) model.OAuthClientResponseDTO {
+	return model.OAuthClientResponseDTO{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: fields(client.RedirectURIs),
+		GrantTypes:   fields(client.GrantTypes),
+		Scopes:       fields(client.Scopes),
+		Confidential: client.Confidential(),
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// fields memecah daftar dipisahkan spasi; hasilnya tidak pernah nil agar dikirim sebagai [].
+func fields(list string) []string {
+	result := strings.Fields(list)
+	if result == nil {
+		return []string{}
+	}
+	return result
+}
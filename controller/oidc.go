@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/oidc"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+// OIDCController menangani endpoint OpenID Connect: discovery, JWKS, dan userinfo.
+type OIDCController struct {
+	auth      *service.AuthService
+	discovery oidc.Discovery
+	jwks      oidc.JWKSet
+}
+
+// NewOIDCController membuat OIDCController untuk issuer dan kunci ID token yang diberikan.
+func NewOIDCController(auth *service.AuthService, issuer string, signer *oidc.Signer) *OIDCController {
+	return &OIDCController{auth: auth, discovery: oidc.NewDiscovery(issuer), jwks: signer.JWKS()}
+}
+
+// Discovery mengirim OpenID Provider Metadata. Isinya statis sehingga boleh di-cache.
+func (ctl *OIDCController) Discovery(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.JSON(ctl.discovery)
+}
+
+// JWKS mengirim kunci publik untuk memverifikasi tanda tangan ID token.
+func (ctl *OIDCController) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.JSON(ctl.jwks)
+}
+
+// UserInfo mengirim claim pengguna pemilik token akses (OIDC Core bagian 5.3), disaring
+// sesuai scope token. Token harus diterbitkan dengan scope openid.
+func (ctl *OIDCController) UserInfo(c *fiber.Ctx) error {
+	claims := c.Locals("jwt").(jwt.MapClaims)
+	scope, _ := claims["scope"].(string)
+	if !oidc.HasScope(scope, oidc.ScopeOpenID) {
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="openid"`)
+		return apperror.Forbidden(apperror.CodeInsufficientScope, "Token was not issued with the openid scope")
+	}
+
+	// Profil diambil dengan cara yang sama seperti GET /api/v1/auth/profile
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+
+	noStore(c)
+	return c.JSON(oidc.UserClaims(user, scope))
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// PasswordlessController menangani login tanpa password di bawah /api/v1/auth/passwordless.
+type PasswordlessController struct {
+	passwordless *service.PasswordlessService
+	ttl          time.Duration
+	// secureCookie mengirim cookie perangkat hanya lewat HTTPS, sesuai skema URL publik server
+	secureCookie bool
+}
+
+// NewPasswordlessController membuat PasswordlessController. ttl adalah masa berlaku challenge
+// dan issuer adalah URL publik server.
+func NewPasswordlessController(passwordless *service.PasswordlessService, ttl time.Duration, issuer string) *PasswordlessController {
+	return &PasswordlessController{
+		passwordless: passwordless,
+		ttl:          ttl,
+		secureCookie: strings.HasPrefix(issuer, "https://"),
+	}
+}
+
+// Request mengirim magic link atau kode login ke email. Respons selalu 202 agar tidak
+// membocorkan apakah email terdaftar.
+func (ctl *PasswordlessController) Request(c *fiber.Ctx) error {
+	var req model.PasswordlessRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+	if req.DeviceSecret == "" {
+		req.DeviceSecret = c.Cookies(service.PasswordlessDeviceCookie)
+	}
+
+	challenge, err := ctl.passwordless.Request(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	ctl.setDeviceCookie(c, challenge.DeviceSecret)
+	noStore(c)
+	return response.Accepted(c, "If the email is registered, a login link or code has been sent", challenge)
+}
+
+// Verify menukar kode atau token magic link dengan token JWT. device_secret boleh diganti
+// cookie perangkat yang diset oleh Request.
+func (ctl *PasswordlessController) Verify(c *fiber.Ctx) error {
+	var req model.PasswordlessVerifyDTO
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+	return ctl.verify(c, req)
+}
+
+// VerifyLink adalah tujuan magic link jika passwordless.link_url kosong. Perangkat dikenali
+// dari cookie, sehingga link harus dibuka di browser yang memintanya.
+func (ctl *PasswordlessController) VerifyLink(c *fiber.Ctx) error {
+	// Nilai query Fiber tidak aman disimpan setelah handler selesai, sehingga disalin
+	return ctl.verify(c, model.PasswordlessVerifyDTO{
+		ChallengeID: utils.CopyString(c.Query("challenge_id")),
+		Secret:      utils.CopyString(c.Query("token")),
+	})
+}
+
+func (ctl *PasswordlessController) verify(c *fiber.Ctx, req model.PasswordlessVerifyDTO) error {
+	if req.DeviceSecret == "" {
+		req.DeviceSecret = c.Cookies(service.PasswordlessDeviceCookie)
+	}
+	noStore(c)
+
+	token, err := ctl.passwordless.Verify(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	ctl.setDeviceCookie(c, "")
+	// Bentuk respons sama dengan login password
+	return response.OK(c, "Login successful", fiber.Map{
+		"token": token,
+	})
+}
+
+// setDeviceCookie menyimpan rahasia perangkat; value kosong menghapus cookie. SameSite=Lax agar
+// cookie ikut terkirim saat magic link dibuka dari aplikasi email.
+func (ctl *PasswordlessController) setDeviceCookie(c *fiber.Ctx, value string) {
+	cookie := &fiber.Cookie{
+		Name:     service.PasswordlessDeviceCookie,
+		Value:    value,
+		Path:     "/api", // Mencakup /api/v1 dan alias /api tanpa versi
+		MaxAge:   int(ctl.ttl / time.Second),
+		Secure:   ctl.secureCookie,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	}
+	if value == "" {
+		cookie.MaxAge, cookie.Expires = 0, time.Unix(0, 0)
+	}
+	c.Cookie(cookie)
+}
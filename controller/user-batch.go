@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BatchUsers menjalankan beberapa operasi pengguna (suspend, reactivate, disable, delete, set_role) sekaligus.
+// Mode atomic (default) menjalankan semua operasi di dalam satu transaksi; mode best_effort
+// menjalankan setiap operasi secara terpisah dan membalas 207 Multi-Status.
+func (ctl *UserController) BatchUsers(c *fiber.Ctx) error {
+	var req model.UserBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request body")
+	}
+
+	if req.Mode == "" {
+		req.Mode = model.BatchModeAtomic
+	}
+
+	results, err := ctl.users.Batch(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	if req.Mode == model.BatchModeBestEffort {
+		return response.JSON(c, fiber.StatusMultiStatus, "Batch executed", results)
+	}
+	return response.OK(c, "Batch executed", results)
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SuspendUser menangguhkan akun pengguna sehingga tidak bisa login maupun memakai token yang ada.
+func (ctl *UserController) SuspendUser(c *fiber.Ctx) error {
+	return ctl.changeUserStatus(c, model.StatusSuspended, "User suspended successfully")
+}
+
+// LockUser mengunci akun pengguna karena alasan keamanan, mis. kredensial yang bocor.
+func (ctl *UserController) LockUser(c *fiber.Ctx) error {
+	return ctl.changeUserStatus(c, model.StatusLocked, "User locked successfully")
+}
+
+// ReactivateUser mengaktifkan akun yang belum diaktifkan, atau mengaktifkan kembali akun yang
+// ditangguhkan, dikunci, atau dinonaktifkan.
+func (ctl *UserController) ReactivateUser(c *fiber.Ctx) error {
+	return ctl.changeUserStatus(c, model.StatusActive, "User reactivated successfully")
+}
+
+// DisableUser menonaktifkan akun pengguna.
+func (ctl *UserController) DisableUser(c *fiber.Ctx) error {
+	return ctl.changeUserStatus(c, model.StatusDisabled, "User disabled successfully")
+}
+
+// changeUserStatus memindahkan status pengguna dengan ID dari URL ke status tujuan.
+func (ctl *UserController) changeUserStatus(c *fiber.Ctx, to string, successMessage string) error {
+	// Alasan bersifat opsional, sehingga body kosong tetap diterima
+	var req model.UserStatusRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request body")
+		}
+	}
+
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := ctl.users.ChangeStatus(c.UserContext(), id, to, req.Reason)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderETag, userETag(user))
+	return response.OK(c, successMessage, newUserResponse(user))
+}
@@ -1,246 +1,168 @@
 package controller
 
 import (
-	"go-fiber-user-management/database"
+	"strings"
+
+	"go-fiber-user-management/apperror"
 	"go-fiber-user-management/model"
-	"go-fiber-user-management/utils"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func GetUsers(c *fiber.Ctx) error {
-	var userData []model.User
-	// Mengambil data pengguna dengan urutan berdasarkan ID
-	if err := database.DB.Order("id").Find(&userData).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to fetch users",
-			"error":   err.Error(),
-		})
+// UserController menangani rute manajemen pengguna di bawah /api/v1/users.
+type UserController struct {
+	users          *service.UserService
+	requireIfMatch bool // Tolak PUT/PATCH/DELETE tanpa header If-Match dengan 428
+}
+
+// NewUserController membuat UserController dengan UserService yang diberikan.
+func NewUserController(users *service.UserService, requireIfMatch bool) *UserController {
+	return &UserController{users: users, requireIfMatch: requireIfMatch}
+}
+
+func (ctl *UserController) GetUsers(c *fiber.Ctx) error {
+	// Filter opsional berdasarkan status, misalnya ?status=active,suspended
+	var statuses []string
+	if statusParam := c.Query("status"); statusParam != "" {
+		for _, status := range strings.Split(statusParam, ",") {
+			statuses = append(statuses, strings.TrimSpace(status))
+		}
+	}
+
+	userData, err := ctl.users.List(c.UserContext(), statuses)
+	if err != nil {
+		return err
 	}
 
 	// Menyiapkan respons DTO untuk setiap pengguna
-	var usersResponse []model.UserResponseDTO
+	usersResponse := make([]model.UserResponseDTO, 0, len(userData))
 	for _, user := range userData {
-		userResponse := model.UserResponseDTO{
-			ID:          user.ID,
-			Email:       user.Email,
-			Fullname:    user.Fullname,
-			Address:     user.Address,
-			Gender:      user.Gender,
-			PhoneNumber: user.PhoneNumber,
-		}
+		userResponse := newUserResponse(user)
 		usersResponse = append(usersResponse, userResponse)
 	}
 
 	// Mengembalikan data pengguna dalam bentuk JSON
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Users fetched successfully",
-		"data":    usersResponse,
-	})
+	return response.OK(c, "Users fetched successfully", usersResponse)
 }
 
-func GetDetailUser(c *fiber.Ctx) error {
+func (ctl *UserController) GetDetailUser(c *fiber.Ctx) error {
 	// Ambil parameter ID dari URL
-	id := c.Params("id")
-
-	// Cari data pengguna berdasarkan ID
-	var user model.User
-	if err := database.DB.First(&user, id).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": "User not found",
-			"error":   err.Error(),
-		})
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
 	}
 
-	// Membuat DTO respons untuk pengguna
-	userResponse := model.UserResponseDTO{
-		ID:          user.ID,
-		Email:       user.Email,
-		Fullname:    user.Fullname,
-		Address:     user.Address,
-		Gender:      user.Gender,
-		PhoneNumber: user.PhoneNumber,
+	user, err := ctl.users.Get(c.UserContext(), id)
+	if err != nil {
+		return err
 	}
 
-	// Kembalikan respons dengan detail pengguna
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User details fetched successfully",
-		"data":    userResponse,
-	})
+	// Kembalikan respons dengan detail pengguna beserta ETag untuk If-Match
+	c.Set(fiber.HeaderETag, userETag(user))
+	return response.OK(c, "User details fetched successfully", newUserResponse(user))
 }
 
-func CreateUser(c *fiber.Ctx) error {
+func (ctl *UserController) CreateUser(c *fiber.Ctx) error {
 	// Parsing input ke struct DTO
 	var userRequest model.UserRequestDTO
 	if err := c.BodyParser(&userRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
-	}
-
-	// Validasi input
-	if userRequest.Email == "" || userRequest.Password == "" || len(userRequest.Password) < 6 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Email and password are required, and password must be at least 6 characters",
-		})
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request body")
 	}
 
-	// Cek apakah email sudah ada
-	var existingUser model.User
-	if err := database.DB.Where("email = ?", userRequest.Email).First(&existingUser).Error; err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"message": "Email already exists",
-		})
+	user, err := ctl.users.Create(c.UserContext(), userRequest)
+	if err != nil {
+		return err
 	}
 
-	// Hash password
-	hashedPassword := utils.GeneratePassword(userRequest.Password)
+	// Kembalikan respons sukses
+	return response.Created(c, "User created successfully", newUserResponse(user))
+}
 
-	// Buat model user
-	userModel := model.User{
-		Email:        userRequest.Email,
-		PasswordHash: hashedPassword,
-		Fullname:     userRequest.Fullname,
-		Address:      userRequest.Address,
-		Gender:       userRequest.Gender,
-		PhoneNumber:  userRequest.PhoneNumber,
+func (ctl *UserController) UpdateUser(c *fiber.Ctx) error {
+	// Parsing input ke struct DTO
+	var userRequest model.UserRequestDTO
+	if err := c.BodyParser(&userRequest); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request body")
 	}
 
-	// Simpan data ke database
-	response := database.DB.Create(&userModel)
-	if response.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to create user",
-			"error":   response.Error.Error(),
-		})
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
 	}
 
-	// Buat DTO untuk respons
-	userResponse := model.UserResponseDTO{
-		ID:          userModel.ID,
-		Email:       userModel.Email,
-		Fullname:    userModel.Fullname,
-		Address:     userModel.Address,
-		Gender:      userModel.Gender,
-		PhoneNumber: userModel.PhoneNumber,
+	user, err := ctl.users.Update(c.UserContext(), id, userRequest, ctl.ifMatch(c))
+	if err != nil {
+		return err
 	}
 
-	// Kembalikan respons sukses
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "User created successfully",
-		"data":    userResponse,
-	})
+	// Kembalikan respons sukses dengan data tanpa password
+	c.Set(fiber.HeaderETag, userETag(user))
+	return response.OK(c, "User updated successfully", newUserResponse(user))
 }
 
-func UpdateUser(c *fiber.Ctx) error {
-	// Parsing input ke struct DTO
-	var userRequest model.UserRequestDTO
+// PatchUser memperbarui sebagian data pengguna; hanya field yang dikirim yang diubah.
+func (ctl *UserController) PatchUser(c *fiber.Ctx) error {
+	var userRequest model.UserPatchDTO
 	if err := c.BodyParser(&userRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
-	}
-
-	// Ambil ID dari parameter URL
-	id := c.Params("id")
-	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "User ID is required",
-		})
-	}
-
-	// Temukan user berdasarkan ID
-	var dataUser model.User
-	result := database.DB.First(&dataUser, "id = ?", id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"message": "User not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to fetch user data",
-			"error":   result.Error.Error(),
-		})
-	}
-
-	// Perbarui data pengguna langsung pada objek `dataUser`
-	dataUser.Email = userRequest.Email
-	dataUser.Fullname = userRequest.Fullname
-	dataUser.Address = userRequest.Address
-	dataUser.Gender = userRequest.Gender
-	dataUser.PhoneNumber = userRequest.PhoneNumber
-
-	// Hanya set PasswordHash jika password baru disediakan
-	if userRequest.Password != "" {
-		hashedPassword := utils.GeneratePassword(userRequest.Password)
-		dataUser.PasswordHash = hashedPassword
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request body")
 	}
 
-	// Simpan perubahan ke database
-	result = database.DB.Save(&dataUser)
-	if result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to update user",
-			"error":   result.Error.Error(),
-		})
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
 	}
 
-	// Buat response DTO tanpa password
-	userResponse := model.UserResponseDTO{
-		ID:          dataUser.ID,
-		Email:       dataUser.Email,
-		Fullname:    dataUser.Fullname,
-		Address:     dataUser.Address,
-		Gender:      dataUser.Gender,
-		PhoneNumber: dataUser.PhoneNumber,
+	user, err := ctl.users.Patch(c.UserContext(), id, userRequest, ctl.ifMatch(c))
+	if err != nil {
+		return err
 	}
 
-	// Kembalikan respons sukses dengan data tanpa password
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User updated successfully",
-		"data":    userResponse,
-	})
+	c.Set(fiber.HeaderETag, userETag(user))
+	return response.OK(c, "User updated successfully", newUserResponse(user))
 }
 
-func DeleteUser(c *fiber.Ctx) error {
+func (ctl *UserController) DeleteUser(c *fiber.Ctx) error {
 	// Ambil ID dari parameter
-	id := c.Params("id")
-	if id == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "User ID is required",
-		})
-	}
-
-	// Cari data user berdasarkan ID
-	var userData model.User
-	result := database.DB.First(&userData, "id = ?", id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"message": "User not found",
-			})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to fetch user data",
-			"error":   result.Error.Error(),
-		})
+	id, err := userIDParam(c)
+	if err != nil {
+		return err
 	}
 
-	// Jalankan penghapusan data
-	if err := database.DB.Delete(&userData).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Failed to delete user",
-			"error":   err.Error(),
-		})
+	user, err := ctl.users.Delete(c.UserContext(), id, ctl.ifMatch(c))
+	if err != nil {
+		return err
 	}
 
 	// Kembalikan respons sukses
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User deleted successfully",
-		"data":    userData,
-	})
+	return response.OK(c, "User deleted successfully", newUserResponse(user))
+}
+
+// userIDParam mengambil dan memvalidasi parameter :id dari URL.
+func userIDParam(c *fiber.Ctx) (uint, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequest(apperror.CodeValidationFailed, "User ID is required")
+	}
+	return uint(id), nil
+}
+
+// newUserResponse membentuk UserResponseDTO dari model User tanpa password hash.
+func newUserResponse(user model.User) model.UserResponseDTO {
+	return model.UserResponseDTO{
+		ID:          user.ID,
+		Email:       user.Email,
+		Fullname:    user.Fullname,
+		Address:     user.Address,
+		Gender:      user.Gender,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
+
+		Status:          user.Status,
+		StatusReason:    user.StatusReason,
+		StatusChangedAt: user.StatusChangedAt,
+
+		Version: user.Version,
+	}
 }
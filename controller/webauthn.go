@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebAuthnController menangani passkey di bawah /api/v1/auth/webauthn.
+type WebAuthnController struct {
+	webauthn *service.WebAuthnService
+	auth     *service.AuthService
+}
+
+// NewWebAuthnController membuat WebAuthnController.
+func NewWebAuthnController(webauthn *service.WebAuthnService, auth *service.AuthService) *WebAuthnController {
+	return &WebAuthnController{webauthn: webauthn, auth: auth}
+}
+
+// BeginRegistration mengembalikan opsi untuk navigator.credentials.create.
+func (ctl *WebAuthnController) BeginRegistration(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	options, err := ctl.webauthn.BeginRegistration(c.UserContext(), user)
+	if err != nil {
+		return err
+	}
+	noStore(c)
+	return response.OK(c, "Passkey registration started", options)
+}
+
+// FinishRegistration menyimpan passkey dari respons navigator.credentials.create.
+func (ctl *WebAuthnController) FinishRegistration(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	var req model.PasskeyRegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	credential, err := ctl.webauthn.FinishRegistration(c.UserContext(), user, req)
+	if err != nil {
+		return err
+	}
+	return response.Created(c, "Passkey registered successfully", newPasskeyResponse(credential))
+}
+
+// BeginLogin mengembalikan opsi untuk navigator.credentials.get. Body boleh kosong untuk login
+// tanpa password, atau berisi mfa_token dari login password.
+func (ctl *WebAuthnController) BeginLogin(c *fiber.Ctx) error {
+	var req model.PasskeyLoginBeginRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+		}
+	}
+
+	options, err := ctl.webauthn.BeginLogin(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	noStore(c)
+	return response.OK(c, "Passkey login started", options)
+}
+
+// FinishLogin menukar respons navigator.credentials.get dengan token JWT.
+func (ctl *WebAuthnController) FinishLogin(c *fiber.Ctx) error {
+	var req model.PasskeyLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	token, err := ctl.webauthn.FinishLogin(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	noStore(c)
+	// Bentuk respons sama dengan login password
+	return response.OK(c, "Login successful", fiber.Map{
+		"token": token,
+	})
+}
+
+// Credentials mengembalikan passkey milik pengguna yang sedang login.
+func (ctl *WebAuthnController) Credentials(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	credentials, err := ctl.webauthn.List(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]model.PasskeyResponseDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		resp = append(resp, newPasskeyResponse(credential))
+	}
+	return response.OK(c, "Passkeys fetched successfully", resp)
+}
+
+// RenameCredential mengganti nama passkey milik pengguna yang sedang login.
+func (ctl *WebAuthnController) RenameCredential(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	id, err := passkeyID(c)
+	if err != nil {
+		return err
+	}
+	var req model.PasskeyRenameRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.BadRequest(apperror.CodeInvalidRequest, "Invalid request payload")
+	}
+
+	if err := ctl.webauthn.Rename(c.UserContext(), user.ID, id, req.Name); err != nil {
+		return err
+	}
+	return response.OK(c, "Passkey renamed successfully", nil)
+}
+
+// DeleteCredential menghapus passkey milik pengguna yang sedang login.
+func (ctl *WebAuthnController) DeleteCredential(c *fiber.Ctx) error {
+	user, err := currentUser(c, ctl.auth)
+	if err != nil {
+		return err
+	}
+	id, err := passkeyID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctl.webauthn.Delete(c.UserContext(), user.ID, id); err != nil {
+		return err
+	}
+	return response.OK(c, "Passkey removed successfully", nil)
+}
+
+// passkeyID membaca parameter path :id passkey.
+func passkeyID(c *fiber.Ctx) (uint, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequest(apperror.CodeValidationFailed, "Passkey ID is required")
+	}
+	return uint(id), nil
+}
+
+func newPasskeyResponse(credential model.WebAuthnCredential) model.PasskeyResponseDTO {
+	transports := []string{}
+	if credential.Transports != "" {
+		transports = strings.Split(credential.Transports, ",")
+	}
+	return model.PasskeyResponseDTO{
+		ID:             credential.ID,
+		Name:           credential.Name,
+		CredentialID:   credential.CredentialID,
+		AAGUID:         credential.AAGUID,
+		Transports:     transports,
+		BackupEligible: credential.BackupEligible,
+		CreatedAt:      credential.CreatedAt,
+		LastUsedAt:     credential.LastUsedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Driver database yang didukung.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Config berisi pengaturan koneksi database.
+type Config struct {
+	Driver   string `yaml:"driver" toml:"driver"` // postgres (default), mysql, atau sqlite
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"` // Nama database, atau path file untuk sqlite (":memory:" untuk in-memory)
+
+	// Pengaturan TLS untuk postgres dan mysql
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`         // postgres: disable, require, verify-ca, verify-full; mysql: disable, require, verify-ca, verify-full
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"` // Path sertifikat CA untuk verify-ca/verify-full
+	SSLCert     string `yaml:"sslcert" toml:"sslcert"`         // Path sertifikat klien (opsional)
+	SSLKey      string `yaml:"sslkey" toml:"sslkey"`           // Path private key klien (opsional)
+
+	// Pengaturan pool koneksi; 0 berarti memakai default database/sql
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+}
+
+// Validate memeriksa apakah konfigurasi lengkap untuk driver yang dipilih.
+func (cfg Config) Validate() error {
+	switch cfg.Driver {
+	case DriverPostgres, DriverMySQL:
+		if cfg.Host == "" || cfg.User == "" || cfg.Name == "" {
+			return fmt.Errorf("DB_HOST, DB_USER and DB_NAME are required for %s", cfg.Driver)
+		}
+	case DriverSQLite:
+		if cfg.Name == "" {
+			return fmt.Errorf("DB_NAME (file path) is required for sqlite")
+		}
+	default:
+		return fmt.Errorf("unsupported DB_DRIVER %q", cfg.Driver)
+	}
+
+	switch cfg.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("unsupported DB_SSLMODE %q", cfg.SSLMode)
+	}
+
+	if cfg.MaxOpenConns < 0 || cfg.MaxIdleConns < 0 || cfg.ConnMaxLifetime < 0 || cfg.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("DB pool settings must not be negative")
+	}
+	if cfg.MaxOpenConns > 0 && cfg.MaxIdleConns > cfg.MaxOpenConns {
+		return fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", cfg.MaxIdleConns, cfg.MaxOpenConns)
+	}
+	return nil
+}
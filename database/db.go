@@ -2,45 +2,75 @@ package database
 
 import (
 	"fmt"
-	"os"
+	"log/slog"
 
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"go-fiber-user-management/logging"
 
-	"go-fiber-user-management/model"
+	"gorm.io/gorm"
 )
 
-// instance database postgres
-var DB *gorm.DB
-
-// connect to postgres
-func Connect() {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
-
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
+// connect ke database sesuai konfigurasi dan kembalikan instance database.
+// Skema tidak lagi dimigrasi otomatis; jalankan `migrate up` (lihat package migration).
+func Connect(cfg Config) *gorm.DB {
+	db, err := Open(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to connect DB: %v", err))
+	}
+
+	slog.Info("connected to database", "driver", cfg.Driver)
+
+	return db
+}
+
+// Close menutup pool koneksi di balik db.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Open membuka koneksi GORM untuk driver pada konfigurasi.
+func Open(cfg Config) (*gorm.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	dial, err := dialector(cfg)
 	if err != nil {
-		panic("Failed to connect DB")
+		return nil, err
 	}
 
-	fmt.Println("Success connect to DB")
+	db, err := gorm.Open(dial, &gorm.Config{
+		// Query dicatat lewat slog di level debug, tanpa nilai parameter
+		Logger: logging.NewGormLogger(slog.Default()),
+		// Terjemahkan error khusus driver (mis. pelanggaran unique) ke gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	//Run migration DB
-	err = DB.AutoMigrate(&model.User{}, &model.RevokedToken{})
+	sqlDB, err := db.DB()
 	if err != nil {
-		panic("Failed to run migration DB")
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	fmt.Println("Migration DB successfully")
+	// Database sqlite in-memory hanya hidup selama koneksinya terbuka,
+	// jadi pool dibatasi satu koneksi yang tidak pernah ditutup agar semua query melihat data yang sama
+	if cfg.Driver == DriverSQLite && cfg.Name == ":memory:" {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
 
+	return db, nil
 }
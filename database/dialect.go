@@ -0,0 +1,166 @@
+package database
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// mysqlTLSConfigName adalah nama konfigurasi TLS yang didaftarkan ke driver mysql.
+const mysqlTLSConfigName = "user-management"
+
+// dialector membuat gorm.Dialector sesuai driver pada konfigurasi.
+func dialector(cfg Config) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverPostgres:
+		return postgres.Open(postgresDSN(cfg)), nil
+	case DriverMySQL:
+		dsn, err := mysqlDSN(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(sqliteDSN(cfg)), nil
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", cfg.Driver)
+	}
+}
+
+// postgresDSN membentuk DSN key=value untuk pgx.
+func postgresDSN(cfg Config) string {
+	parts := []string{
+		"host=" + quoteDSNValue(cfg.Host),
+		"user=" + quoteDSNValue(cfg.User),
+		"password=" + quoteDSNValue(cfg.Password),
+		"dbname=" + quoteDSNValue(cfg.Name),
+		"sslmode=" + cfg.SSLMode,
+	}
+	if cfg.Port != "" {
+		parts = append(parts, "port="+cfg.Port)
+	}
+	if cfg.SSLRootCert != "" {
+		parts = append(parts, "sslrootcert="+quoteDSNValue(cfg.SSLRootCert))
+	}
+	if cfg.SSLCert != "" {
+		parts = append(parts, "sslcert="+quoteDSNValue(cfg.SSLCert))
+	}
+	if cfg.SSLKey != "" {
+		parts = append(parts, "sslkey="+quoteDSNValue(cfg.SSLKey))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue memberi tanda kutip pada nilai yang mengandung spasi atau karakter khusus.
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// mysqlDSN membentuk DSN untuk go-sql-driver/mysql, termasuk konfigurasi TLS.
+func mysqlDSN(cfg Config) (string, error) {
+	mc := mysqldriver.NewConfig()
+	mc.User = cfg.User
+	mc.Passwd = cfg.Password
+	mc.Net = "tcp"
+	mc.Addr = cfg.Host
+	if cfg.Port != "" {
+		mc.Addr = cfg.Host + ":" + cfg.Port
+	}
+	mc.DBName = cfg.Name
+	mc.ParseTime = true
+	mc.Params = map[string]string{"charset": "utf8mb4"}
+
+	switch cfg.SSLMode {
+	case "disable":
+	case "require":
+		mc.TLSConfig = "skip-verify"
+	case "verify-ca", "verify-full":
+		tlsConfig, err := mysqlTLSConfig(cfg)
+		if err != nil {
+			return "", err
+		}
+		if err := mysqldriver.RegisterTLSConfig(mysqlTLSConfigName, tlsConfig); err != nil {
+			return "", err
+		}
+		mc.TLSConfig = mysqlTLSConfigName
+	}
+	return mc.FormatDSN(), nil
+}
+
+// mysqlTLSConfig membuat tls.Config dari sertifikat CA dan (opsional) sertifikat klien.
+func mysqlTLSConfig(cfg Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+
+	if cfg.SSLRootCert != "" {
+		pem, err := os.ReadFile(cfg.SSLRootCert)
+		if err != nil {
+			return nil, fmt.Errorf("read DB_SSLROOTCERT: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("DB_SSLROOTCERT contains no valid certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.SSLCert != "" || cfg.SSLKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+		if err != nil {
+			return nil, fmt.Errorf("load DB_SSLCERT/DB_SSLKEY: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// verify-ca hanya memeriksa rantai sertifikat, bukan nama host
+	if cfg.SSLMode == "verify-ca" {
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChainOnly(tlsConfig.RootCAs)
+	}
+	return tlsConfig, nil
+}
+
+// verifyChainOnly memverifikasi rantai sertifikat server tanpa mencocokkan nama host.
+func verifyChainOnly(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// sqliteDSN membentuk DSN sqlite dengan foreign key aktif dan busy timeout.
+func sqliteDSN(cfg Config) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	return cfg.Name + "?" + params.Encode()
+}
@@ -0,0 +1,63 @@
+package database
+
+import "testing"
+
+func TestPostgresDSN(t *testing.T) {
+	cfg := Config{
+		Driver: DriverPostgres, Host: "db", Port: "5432", User: "app", Password: "p@ss word",
+		Name: "users", SSLMode: "verify-full", SSLRootCert: "/etc/ca.pem",
+	}
+
+	want := "host=db user=app password='p@ss word' dbname=users sslmode=verify-full port=5432 sslrootcert=/etc/ca.pem"
+	if got := postgresDSN(cfg); got != want {
+		t.Errorf("postgresDSN() = %q, want %q", got, want)
+	}
+}
+
+func TestMySQLDSN(t *testing.T) {
+	tests := []struct {
+		sslMode string
+		want    string
+	}{
+		{"disable", "app:secret@tcp(db:3306)/users?parseTime=true&charset=utf8mb4"},
+		{"require", "app:secret@tcp(db:3306)/users?parseTime=true&tls=skip-verify&charset=utf8mb4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sslMode, func(t *testing.T) {
+			cfg := Config{Driver: DriverMySQL, Host: "db", Port: "3306", User: "app", Password: "secret", Name: "users", SSLMode: tt.sslMode}
+			got, err := mysqlDSN(cfg)
+			if err != nil {
+				t.Fatalf("mysqlDSN() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("mysqlDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"sqlite ok", Config{Driver: DriverSQLite, Name: "app.db", SSLMode: "disable"}, false},
+		{"sqlite without path", Config{Driver: DriverSQLite, SSLMode: "disable"}, true},
+		{"postgres without host", Config{Driver: DriverPostgres, User: "u", Name: "n", SSLMode: "disable"}, true},
+		{"unknown driver", Config{Driver: "oracle", SSLMode: "disable"}, true},
+		{"unknown sslmode", Config{Driver: DriverSQLite, Name: "app.db", SSLMode: "maybe"}, true},
+		{"pool ok", Config{Driver: DriverSQLite, Name: "app.db", SSLMode: "disable", MaxOpenConns: 10, MaxIdleConns: 5}, false},
+		{"idle exceeds open", Config{Driver: DriverSQLite, Name: "app.db", SSLMode: "disable", MaxOpenConns: 5, MaxIdleConns: 10}, true},
+		{"negative lifetime", Config{Driver: DriverSQLite, Name: "app.db", SSLMode: "disable", ConnMaxLifetime: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
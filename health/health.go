@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/migration"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTimeout adalah batas waktu setiap pemeriksaan komponen.
+const DefaultTimeout = 2 * time.Second
+
+// Status komponen dan keseluruhan layanan.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check adalah pemeriksaan satu komponen yang menentukan kesiapan layanan.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// ComponentReport adalah hasil pemeriksaan satu komponen.
+type ComponentReport struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report adalah hasil seluruh pemeriksaan; Status up hanya jika semua komponen up.
+type Report struct {
+	Status     string            `json:"status"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Components []ComponentReport `json:"components"`
+}
+
+// Checker menjalankan sekumpulan Check secara paralel.
+type Checker struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// NewChecker membuat Checker dengan batas waktu per pemeriksaan.
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{timeout: timeout, checks: checks}
+}
+
+// Check menjalankan semua pemeriksaan dan mengembalikan laporannya sesuai urutan pendaftaran.
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{
+		Status:     StatusUp,
+		CheckedAt:  time.Now().UTC(),
+		Components: make([]ComponentReport, len(c.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Components[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run menjalankan satu pemeriksaan dengan batas waktu dan mencatat latensinya.
+func (c *Checker) run(ctx context.Context, check Check) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// Pemeriksaan yang tidak menghormati ctx tetap dibatasi oleh timeout
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- check.Run(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	component := ComponentReport{
+		Name:      check.Name,
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// Database memeriksa bahwa koneksi database dapat di-ping.
+func Database(db *gorm.DB) Check {
+	return Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Migrations memeriksa bahwa tidak ada migrasi yang belum diterapkan.
+func Migrations(migrator *migration.Migrator) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending migration(s), first is %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+			}
+			return nil
+		},
+	}
+}
+
+// SigningKey memeriksa bahwa kunci penandatangan JWT sudah dimuat.
+func SigningKey(secret string) Check {
+	return Check{
+		Name: "signing_key",
+		Run: func(ctx context.Context) error {
+			if secret == "" {
+				return errors.New("JWT signing key is not configured")
+			}
+			return nil
+		},
+	}
+}
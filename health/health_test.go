@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerCheck(t *testing.T) {
+	checker := NewChecker(50*time.Millisecond,
+		Check{Name: "ok", Run: func(ctx context.Context) error { return nil }},
+		Check{Name: "broken", Run: func(ctx context.Context) error { return errors.New("boom") }},
+		// Pemeriksaan yang mengabaikan ctx tetap dihentikan oleh timeout
+		Check{Name: "stuck", Run: func(ctx context.Context) error { time.Sleep(time.Second); return nil }},
+	)
+
+	start := time.Now()
+	report := checker.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("checks took %s, timeout not applied", elapsed)
+	}
+
+	if report.Status != StatusDown {
+		t.Errorf("status = %s, want down", report.Status)
+	}
+	want := []struct{ name, status, err string }{
+		{"ok", StatusUp, ""},
+		{"broken", StatusDown, "boom"},
+		{"stuck", StatusDown, context.DeadlineExceeded.Error()},
+	}
+	for i, w := range want {
+		got := report.Components[i]
+		if got.Name != w.name || got.Status != w.status || got.Error != w.err {
+			t.Errorf("component %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if report := NewChecker(time.Second, SigningKey("secret")).Check(context.Background()); report.Status != StatusUp {
+		t.Errorf("all up report = %+v", report)
+	}
+}
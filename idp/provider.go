@@ -0,0 +1,359 @@
+// Package idp adalah klien OAuth2/OpenID Connect untuk login lewat penyedia identitas
+// eksternal (Google, GitHub, IdP perusahaan). Package oidc adalah sisi server; package ini
+// sisi klien.
+package idp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/config"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// DefaultTimeout adalah batas waktu setiap request ke IdP.
+const DefaultTimeout = 10 * time.Second
+
+// maxResponseSize membatasi body response IdP yang dibaca.
+const maxResponseSize = 1 << 20
+
+// defaultOIDCScopes dipakai provider OpenID Connect yang tidak mengatur scope.
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// Claims adalah identitas pengguna yang dibaca dari ID token dan/atau endpoint userinfo IdP.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider adalah klien untuk satu IdP. Endpoint IdP OpenID Connect dibaca dari discovery
+// document saat pertama kali dibutuhkan, lalu disimpan.
+type Provider struct {
+	name        string
+	config      config.IdentityProvider
+	redirectURL string
+	client      *http.Client
+
+	mu        sync.Mutex
+	endpoints *endpoints
+}
+
+// endpoints adalah URL IdP setelah discovery dan override dari konfigurasi digabungkan.
+type endpoints struct {
+	issuer      string
+	authURL     string
+	tokenURL    string
+	userInfoURL string
+}
+
+// NewProvider membuat klien IdP. redirectURL adalah URL callback aplikasi yang terdaftar di IdP.
+func NewProvider(name string, cfg config.IdentityProvider, redirectURL string, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Provider{name: name, config: cfg, redirectURL: redirectURL, client: client}
+}
+
+// Name mengembalikan nama provider di URL.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// DisplayName mengembalikan nama provider untuk ditampilkan, atau Name jika tidak diatur.
+func (p *Provider) DisplayName() string {
+	if p.config.DisplayName != "" {
+		return p.config.DisplayName
+	}
+	return p.name
+}
+
+// Config mengembalikan konfigurasi provider, termasuk aturan penautan dan provisioning.
+func (p *Provider) Config() config.IdentityProvider {
+	return p.config
+}
+
+// AuthCodeURL membentuk URL authorization endpoint IdP dengan state, nonce, dan PKCE S256.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	ep, err := p.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.redirectURL},
+		"state":                 {state},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	scopes := p.scopes()
+	if len(scopes) > 0 {
+		params.Set("scope", strings.Join(scopes, " "))
+	}
+	if containsString(scopes, "openid") {
+		params.Set("nonce", nonce)
+	}
+
+	u, err := url.Parse(ep.authURL)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid authorization endpoint: %w", p.name, err)
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Exchange menukar authorization code di token endpoint lalu membaca identitas pengguna dari
+// ID token dan endpoint userinfo.
+//
+// Tanda tangan ID token tidak diverifikasi: token diterima langsung dari token endpoint lewat
+// TLS sehingga validasi server TLS sudah cukup (OpenID Connect Core bagian 3.1.3.7). Issuer,
+// audience, masa berlaku, dan nonce tetap diperiksa.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Claims, error) {
+	ep, err := p.resolve(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {p.config.ClientID},
+	}
+	if p.config.ClientSecret != "" {
+		form.Set("client_secret", p.config.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	// GitHub mengirim error token endpoint dengan status 200, sehingga field error selalu diperiksa
+	if err := p.do(req, &token); err != nil && token.Error == "" {
+		return Claims{}, fmt.Errorf("%s token endpoint: %w", p.name, err)
+	}
+	if token.Error != "" {
+		return Claims{}, fmt.Errorf("%s token endpoint: %s: %s", p.name, token.Error, token.ErrorDescription)
+	}
+
+	claims := map[string]interface{}{}
+	if token.IDToken != "" {
+		if claims, err = p.verifyIDToken(ep, token.IDToken, nonce); err != nil {
+			return Claims{}, err
+		}
+	}
+	if ep.userInfoURL != "" {
+		if token.AccessToken == "" {
+			return Claims{}, fmt.Errorf("%s token endpoint: response has no access_token", p.name)
+		}
+		info, err := p.userInfo(ctx, ep.userInfoURL, token.AccessToken)
+		if err != nil {
+			return Claims{}, err
+		}
+		// Userinfo harus milik subject yang sama dengan ID token (OIDC Core bagian 5.3.2)
+		if sub, ok := claims["sub"]; ok && stringClaim(info["sub"]) != stringClaim(sub) {
+			return Claims{}, fmt.Errorf("%s userinfo: sub does not match the ID token", p.name)
+		}
+		for key, value := range info {
+			claims[key] = value
+		}
+	} else if token.IDToken == "" {
+		return Claims{}, fmt.Errorf("%s token endpoint: response has no id_token and no userinfo endpoint is configured", p.name)
+	}
+
+	return p.mapClaims(claims)
+}
+
+// verifyIDToken membaca claim ID token dan memeriksa iss, aud, exp, dan nonce.
+func (p *Provider) verifyIDToken(ep *endpoints, raw, nonce string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(raw, claims); err != nil {
+		return nil, fmt.Errorf("%s id_token: %w", p.name, err)
+	}
+	if ep.issuer != "" && strings.TrimSuffix(stringClaim(claims["iss"]), "/") != ep.issuer {
+		return nil, fmt.Errorf("%s id_token: unexpected issuer %q", p.name, claims["iss"])
+	}
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, fmt.Errorf("%s id_token: audience does not contain the client ID", p.name)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("%s id_token: token has expired", p.name)
+	}
+	if stringClaim(claims["nonce"]) != nonce {
+		return nil, fmt.Errorf("%s id_token: nonce mismatch", p.name)
+	}
+	return claims, nil
+}
+
+// userInfo memanggil endpoint userinfo dengan token akses dari IdP.
+func (p *Provider) userInfo(ctx context.Context, endpoint, accessToken string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	info := map[string]interface{}{}
+	if err := p.do(req, &info); err != nil {
+		return nil, fmt.Errorf("%s userinfo: %w", p.name, err)
+	}
+	return info, nil
+}
+
+// mapClaims membentuk Claims sesuai pemetaan claim di konfigurasi provider.
+func (p *Provider) mapClaims(claims map[string]interface{}) (Claims, error) {
+	result := Claims{
+		Subject: stringClaim(claims[claimName(p.config.SubjectClaim, "sub")]),
+		Email:   strings.TrimSpace(stringClaim(claims[claimName(p.config.EmailClaim, "email")])),
+		Name:    stringClaim(claims[claimName(p.config.NameClaim, "name")]),
+	}
+	if result.Subject == "" {
+		return Claims{}, fmt.Errorf("%s: response has no %q claim", p.name, claimName(p.config.SubjectClaim, "sub"))
+	}
+	// Sebagian IdP mengirim email_verified sebagai string
+	verified := claims["email_verified"]
+	result.EmailVerified = result.Email != "" && (p.config.TrustEmail || verified == true || verified == "true")
+	return result, nil
+}
+
+// resolve mengembalikan endpoint IdP, menjalankan discovery sekali untuk provider dengan issuer.
+// Discovery yang gagal dicoba lagi pada request berikutnya.
+func (p *Provider) resolve(ctx context.Context) (*endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+	ep := &endpoints{
+		issuer:      strings.TrimSuffix(p.config.Issuer, "/"),
+		authURL:     p.config.AuthURL,
+		tokenURL:    p.config.TokenURL,
+		userInfoURL: p.config.UserInfoURL,
+	}
+	if ep.issuer != "" {
+		if err := p.discover(ctx, ep); err != nil {
+			return nil, err
+		}
+	}
+	if ep.authURL == "" || ep.tokenURL == "" {
+		return nil, fmt.Errorf("%s: authorization and token endpoints are unknown", p.name)
+	}
+	p.endpoints = ep
+	return ep, nil
+}
+
+// discover membaca OpenID Provider Metadata dan mengisi endpoint yang tidak diatur di konfigurasi.
+func (p *Provider) discover(ctx context.Context, ep *endpoints) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return err
+	}
+
+	var metadata struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := p.do(req, &metadata); err != nil {
+		return fmt.Errorf("%s discovery: %w", p.name, err)
+	}
+	// Issuer di metadata harus sama dengan yang dikonfigurasi (OIDC Discovery bagian 4.3)
+	if strings.TrimSuffix(metadata.Issuer, "/") != ep.issuer {
+		return fmt.Errorf("%s discovery: issuer %q does not match %q", p.name, metadata.Issuer, ep.issuer)
+	}
+	if ep.authURL == "" {
+		ep.authURL = metadata.AuthorizationEndpoint
+	}
+	if ep.tokenURL == "" {
+		ep.tokenURL = metadata.TokenEndpoint
+	}
+	if ep.userInfoURL == "" {
+		ep.userInfoURL = metadata.UserInfoEndpoint
+	}
+	return nil
+}
+
+// do mengirim request dan men-decode body JSON ke dst. Body tetap di-decode untuk status
+// non-2xx agar field error dari IdP bisa dibaca pemanggil.
+func (p *Provider) do(req *http.Request, dst interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize))
+	decoder.UseNumber()
+	decodeErr := decoder.Decode(dst)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("decode response: %w", decodeErr)
+	}
+	return nil
+}
+
+func (p *Provider) scopes() []string {
+	if len(p.config.Scopes) == 0 && p.config.Issuer != "" {
+		return defaultOIDCScopes
+	}
+	return p.config.Scopes
+}
+
+// stringClaim mengubah nilai claim menjadi string; ID numerik (mis. GitHub) ditulis tanpa eksponen.
+func stringClaim(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func claimName(configured, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package idp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/idp"
+)
+
+// TestGitHubStyleProvider memakai IdP OAuth2 tanpa discovery dan ID token: subject numerik
+// dari userinfo dan error token endpoint dengan status 200.
+func TestGitHubStyleProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("token Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("code") != "good" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 12345678901, "login": "octocat", "name": "The Octocat", "email": "octocat@github.com"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := idp.NewProvider("github", config.IdentityProvider{
+		AuthURL:      "https://github.example/login/oauth/authorize",
+		TokenURL:     server.URL + "/token",
+		UserInfoURL:  server.URL + "/user",
+		ClientID:     "client",
+		Scopes:       []string{"read:user", "user:email"},
+		SubjectClaim: "id",
+	}, "https://app.example.com/callback", nil)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if err != nil || !strings.Contains(authURL, "scope=read%3Auser+user%3Aemail") || strings.Contains(authURL, "nonce=") {
+		t.Fatalf("auth URL = %q, %v", authURL, err)
+	}
+
+	claims, err := provider.Exchange(context.Background(), "good", "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	// Tanpa email_verified dan trust_email, email tidak dianggap terverifikasi
+	if claims.Subject != "12345678901" || claims.Name != "The Octocat" || claims.Email != "octocat@github.com" || claims.EmailVerified {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "bad", "verifier", "nonce"); err == nil ||
+		!strings.Contains(err.Error(), "bad_verification_code") {
+		t.Fatalf("exchange with bad code err = %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted adalah pengganti nilai rahasia di log.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys adalah potongan nama atribut yang nilainya tidak boleh tercatat.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "cookie", "hash"}
+
+// sensitiveValues mengenali rahasia di dalam teks bebas (pesan, error):
+// JWT, header Bearer, dan hash bcrypt.
+var sensitiveValues = []*regexp.Regexp{
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`),
+}
+
+// Redact dipakai sebagai slog.HandlerOptions.ReplaceAttr: atribut dengan nama sensitif
+// (mis. password, token, jwt_secret) disamarkan seluruhnya, dan rahasia yang muncul di
+// dalam teks lain diganti dengan [REDACTED].
+func Redact(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key != slog.MessageKey && isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, RedactString(err.Error()))
+		}
+	}
+	return attr
+}
+
+// RedactString mengganti JWT, token Bearer, dan hash bcrypt di dalam s.
+func RedactString(s string) string {
+	for _, pattern := range sensitiveValues {
+		s = pattern.ReplaceAllString(s, redacted)
+	}
+	return s
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+)
+
+// Driver pengiriman email yang didukung.
+const (
+	DriverLog  = "log"  // Email ditulis ke log, untuk development lokal
+	DriverSMTP = "smtp" // Email dikirim lewat server SMTP
+)
+
+// Config berisi pengaturan pengiriman email.
+type Config struct {
+	Driver   string `yaml:"driver" toml:"driver"` // log atau smtp
+	From     string `yaml:"from" toml:"from"`     // Alamat pengirim, boleh dengan nama ("App <no-reply@example.com>")
+	Host     string `yaml:"host" toml:"host"`     // Host server SMTP
+	Port     int    `yaml:"port" toml:"port"`     // 587 untuk STARTTLS, 465 untuk TLS langsung
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// Validate memeriksa konfigurasi email.
+func (cfg Config) Validate() error {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("MAIL_FROM must be an email address, got %q", cfg.From)
+	}
+	switch cfg.Driver {
+	case DriverLog:
+	case DriverSMTP:
+		if cfg.Host == "" {
+			return errors.New("SMTP_HOST is required for the smtp mail driver")
+		}
+		if cfg.Port <= 0 || cfg.Port > 65535 {
+			return fmt.Errorf("SMTP_PORT must be between 1 and 65535, got %d", cfg.Port)
+		}
+	default:
+		return fmt.Errorf("unsupported MAIL_DRIVER %q: use log or smtp", cfg.Driver)
+	}
+	return nil
+}
+
+// Message adalah email teks yang akan dikirim.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer mengirim email. Implementasi harus aman dipakai bersamaan dari beberapa goroutine.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New membuat Mailer sesuai driver di cfg.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverLog:
+		return Log{}, nil
+	case DriverSMTP:
+		return NewSMTP(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.Driver)
+	}
+}
+
+// Log menulis email ke log alih-alih mengirimnya. Isi email (termasuk magic link dan kode
+// login) ikut tercatat, jadi hanya untuk development lokal.
+type Log struct{}
+
+// Send mencatat email ke logger default.
+func (Log) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "email not sent (log mail driver)", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	from := &mail.Address{Name: "App", Address: "no-reply@example.com"}
+	to := &mail.Address{Address: "user@example.com"}
+
+	raw, err := compose(from, to, Message{To: to.Address, Subject: "Kode masuk Anda", Text: "line one\nline two\n"})
+	if err != nil {
+		t.Fatalf("compose: %v", err)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("parse composed message: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != "<user@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+	if !strings.Contains(string(raw), "line one\r\nline two\r\n") {
+		t.Errorf("body lines not CRLF terminated: %q", raw)
+	}
+
+	if _, err := compose(from, to, Message{Subject: "hi\r\nBcc: victim@example.com"}); err == nil {
+		t.Fatal("subject with line break accepted")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Driver: DriverSMTP, From: "App <no-reply@example.com>", Host: "smtp.example.com", Port: 587}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	for name, cfg := range map[string]Config{
+		"unknown driver": {Driver: "sendgrid", From: valid.From},
+		"invalid from":   {Driver: DriverLog, From: "not an address"},
+		"smtp no host":   {Driver: DriverSMTP, From: valid.From, Port: 587},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
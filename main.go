@@ -1,32 +1,67 @@
 package main
 
 import (
-	"log"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"go-fiber-user-management/config"
 	"go-fiber-user-management/database"
-	"go-fiber-user-management/router"
+	"go-fiber-user-management/migration"
+	"go-fiber-user-management/utils"
 )
 
 func main() {
-	err := godotenv.Load()
+	// Konfigurasi dari default, file CONFIG_FILE, .env, dan variabel lingkungan
+	cfg, err := config.Load()
 	if err != nil {
-		log.Println("Error loading .env file")
+		fatal("failed to load configuration", err)
+	}
+	// Konfigurasi log yang tidak valid dilaporkan oleh Validate di bawah
+	if logger, err := cfg.Log.NewLogger(os.Stdout); err == nil {
+		slog.SetDefault(logger)
 	}
+	utils.SetProviderCanonicalization(cfg.Email.CanonicalizeProviders)
 
-	// Run connection to database
-	database.Connect()
+	// Subcommand CLI, misalnya `go run . migrate up` atau `go run . config print`
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:], cfg.Database.Config))
+		case "config":
+			os.Exit(runConfig(os.Args[2:], cfg))
+		}
+	}
 
-	app := fiber.New()
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", err)
+	}
 
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.SendString("Hello this is JWT Task App")
-	})
+	// SIGINT/SIGTERM membatalkan ctx dan memulai graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// route authentication & task
-	router.SetupRoutes(app)
+	// Run connection to database
+	db := database.Connect(cfg.Database.Config)
+
+	// Migrasi saat boot hanya jika diminta; di production jalankan `migrate up` terpisah
+	if cfg.Database.AutoMigrate {
+		applied, err := migration.New(db, cfg.Database.Driver).Up(ctx)
+		if err != nil {
+			fatal("failed to run migrations", err)
+		}
+		slog.Info("migrations applied", "count", len(applied))
+	}
+
+	if err := serve(ctx, cfg, db); err != nil {
+		fatal("server stopped with error", err)
+	}
+}
 
-	log.Fatal(app.Listen(":3000"))
+// fatal mencatat error lalu keluar dengan kode 1.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// gormPlugin mencatat durasi setiap query GORM ke DBQueryDuration.
+type gormPlugin struct{}
+
+func (gormPlugin) Name() string { return "metrics" }
+
+func (gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("metrics:before_create", startTimer),
+		cb.Create().After("*").Register("metrics:after_create", observeQuery("create")),
+		cb.Query().Before("*").Register("metrics:before_query", startTimer),
+		cb.Query().After("*").Register("metrics:after_query", observeQuery("query")),
+		cb.Update().Before("*").Register("metrics:before_update", startTimer),
+		cb.Update().After("*").Register("metrics:after_update", observeQuery("update")),
+		cb.Delete().Before("*").Register("metrics:before_delete", startTimer),
+		cb.Delete().After("*").Register("metrics:after_delete", observeQuery("delete")),
+		cb.Row().Before("*").Register("metrics:before_row", startTimer),
+		cb.Row().After("*").Register("metrics:after_row", observeQuery("row")),
+		cb.Raw().Before("*").Register("metrics:before_raw", startTimer),
+		cb.Raw().After("*").Register("metrics:after_raw", observeQuery("raw")),
+	)
+}
+
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(startedAtKey, time.Now())
+}
+
+func observeQuery(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		startedAt, ok := tx.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		table := tx.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(startedAt.(time.Time)).Seconds())
+	}
+}
+
+// InstrumentDB memasang pencatatan durasi query pada db dan mendaftarkan statistik pool
+// koneksinya (koneksi terbuka, dipakai, idle, waktu tunggu) dengan label db_name.
+func InstrumentDB(db *gorm.DB, name string) error {
+	if err := db.Use(gormPlugin{}); err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"go-fiber-user-management/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute adalah label rute untuk request yang tidak cocok dengan rute mana pun,
+// agar path acak (mis. dari scanner) tidak menambah kardinalitas label.
+const unmatchedRoute = "unmatched"
+
+// Middleware mencatat jumlah dan latensi request per pola rute (mis. /api/users/:id).
+// Dipasang dengan app.Use sebelum semua rute.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Error belum ditulis ke respons oleh ErrorHandler, jadi statusnya diambil dari error
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apperror.From(err).Status
+		}
+
+		// Label disalin karena string dari Fiber memakai buffer yang dipakai ulang setelah request selesai
+		method := utils.CopyString(c.Method())
+		route := utils.CopyString(c.Route().Path)
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && (fiberErr.Code == fiber.StatusNotFound || fiberErr.Code == fiber.StatusMethodNotAllowed) {
+			// Error bawaan Fiber berarti tidak ada rute yang cocok
+			route = unmatchedRoute
+		}
+
+		HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Handler mengekspos Registry dalam format teks Prometheus.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
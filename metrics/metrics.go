@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"errors"
+
+	"go-fiber-user-management/apperror"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Registry berisi semua metrik aplikasi yang diekspos lewat /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests menghitung request HTTP per method, pola rute, dan status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration mencatat latensi request HTTP per method dan pola rute.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// LoginAttempts menghitung percobaan login per hasil (success atau kode error).
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Login attempts by outcome (success or error code).",
+	}, []string{"outcome"})
+
+	// Registrations menghitung pendaftaran per hasil (success atau kode error).
+	Registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "User registrations by outcome (success or error code).",
+	}, []string{"outcome"})
+
+	// TokenRevocations menghitung token yang dibatalkan lewat logout.
+	TokenRevocations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_token_revocations_total",
+		Help: "Tokens revoked via logout.",
+	})
+
+	// RevokedTokenHits menghitung request yang ditolak karena memakai token yang sudah dibatalkan.
+	RevokedTokenHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_revoked_token_hits_total",
+		Help: "Requests rejected because they presented a revoked token.",
+	})
+
+	// DBQueryDuration mencatat durasi query GORM per operasi dan tabel.
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "GORM query latency by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		LoginAttempts,
+		Registrations,
+		TokenRevocations,
+		RevokedTokenHits,
+		DBQueryDuration,
+	)
+}
+
+// Outcome mengubah error menjadi label hasil: "success", kode apperror, atau "error".
+func Outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return "error"
+}
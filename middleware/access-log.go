@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"go-fiber-user-management/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccessLog mencatat satu baris log per request setelah selesai diproses.
+// Dipasang setelah RequestID agar baris log membawa request_id.
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Error belum ditulis ke respons oleh ErrorHandler, jadi statusnya diambil dari error
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apperror.From(err).Status
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(c.UserContext(), level, "request",
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		)
+		return err
+	}
+}
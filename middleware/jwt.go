@@ -2,66 +2,77 @@ package middleware
 
 import (
 	"strings"
-	"time"
 
-	"go-fiber-user-management/database"
-	"go-fiber-user-management/model"
-	"go-fiber-user-management/utils"
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/service"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
 )
 
-// JWTAuthorization middleware memeriksa keabsahan token JWT.
-// JWTAuthorization middleware for JWT authentication
-func JWTAuthorization(c *fiber.Ctx) error {
-	// Get token from Authorization header
-	tokenString := c.Get("Authorization")
-	if tokenString == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Missing or malformed JWT",
-		})
-	}
+// JWTAuthorization membuat middleware yang memeriksa keabsahan token JWT atau token akses
+// pribadi. Token harus dikirim lewat header Authorization dengan format "Bearer <token>".
+func JWTAuthorization(auth *service.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Get token from Authorization header
+		tokenString := c.Get("Authorization")
+		if tokenString == "" {
+			return apperror.Unauthorized(apperror.CodeTokenMissing, "Missing or malformed JWT")
+		}
 
-	// Memisahkan token dari kata "Bearer"
-	if strings.HasPrefix(tokenString, "Bearer ") {
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-	} else {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid Authorization format",
-		})
-	}
+		// Memisahkan token dari kata "Bearer"
+		if strings.HasPrefix(tokenString, "Bearer ") {
+			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+		} else {
+			return apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid Authorization format")
+		}
 
-	// Cek jika token telah dibatalkan di dalam basis data
-	var revokedToken model.RevokedToken
-	if err := database.DB.Where("token = ?", tokenString).First(&revokedToken).Error; err == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Token has been revoked",
-		})
-	}
+		// Validasi token: belum dibatalkan, tanda tangan valid, belum kedaluwarsa, akun aktif
+		claims, user, err := auth.Authorize(c.UserContext(), tokenString)
+		if err != nil {
+			if metrics.Outcome(err) == apperror.CodeTokenRevoked {
+				metrics.RevokedTokenHits.Inc()
+			}
+			return err
+		}
 
-	// Verify token using VerifyToken function
-	claims, err := utils.VerifyToken(tokenString)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid or expired token",
-		})
-	}
+		// Store claims and the authenticated user in context for later use
+		c.Locals("jwt", claims)
+		c.Locals("user", user)
+
+		// Request dengan token impersonation dicatat di audit trail sebelum diproses
+		if err := auth.AuditImpersonation(c.UserContext(), claims, c.Method()+" "+c.Path(), c.IP()); err != nil {
+			return err
+		}
 
-	// Check if token is expired
-	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(time.Now()) {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Token has expired",
-		})
+		// If valid, proceed to the next handler
+		return c.Next()
 	}
+}
 
-	// Store claims in context for later use
-	c.Locals("jwt", claims)
+// RejectAPITokens membuat middleware yang menolak request dengan token akses pribadi, untuk
+// operasi yang bisa memperpanjang akses (membuat token, menambah passkey, menautkan IdP).
+// Harus dipasang setelah JWTAuthorization yang menyimpan klaim di c.Locals("jwt").
+func RejectAPITokens() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, _ := c.Locals("jwt").(jwt.MapClaims)
+		if _, ok := claims[service.ClaimAPITokenID]; ok {
+			return apperror.Forbidden(apperror.CodeForbidden, "This operation requires signing in, API tokens are not accepted")
+		}
+		return c.Next()
+	}
+}
 
-	// If valid, proceed to the next handler
-	return c.Next()
+// RejectImpersonation membuat middleware yang menolak token impersonation untuk operasi sensitif
+// (mengelola kredensial, token, dan identitas tertaut, atau memulai impersonation lain).
+// Harus dipasang setelah JWTAuthorization yang menyimpan klaim di c.Locals("jwt").
+func RejectImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, _ := c.Locals("jwt").(jwt.MapClaims)
+		if service.IsImpersonation(claims) {
+			return apperror.Forbidden(apperror.CodeImpersonationNotAllowed, "This operation is not allowed while impersonating a user")
+		}
+		return c.Next()
+	}
 }
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimit membatasi request dengan key yang sama menjadi max per window. Request dengan key
+// kosong tidak dibatasi, dan window 0 menonaktifkan batas. Penghitung disimpan di memori proses, sehingga setiap instance
+// menghitung sendiri. Batas yang tercapai menghasilkan 429 rate_limited dengan header Retry-After.
+func RateLimit(max int, window time.Duration, key func(c *fiber.Ctx) string) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		Next: func(c *fiber.Ctx) bool {
+			return window <= 0 || key(c) == ""
+		},
+		KeyGenerator: key,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apperror.New(fiber.StatusTooManyRequests, apperror.CodeRateLimited, "Too many requests, try again later")
+		},
+	})
+}
+
+// ClientIP adalah key RateLimit per alamat IP klien.
+func ClientIP(c *fiber.Ctx) string {
+	return c.IP()
+}
+
+// ClientEmail adalah key RateLimit per alamat IP klien dan email di body request. Email
+// dinormalisasi seperti saat pencarian pengguna sehingga variasi huruf besar atau alias tidak
+// melewati batas, sementara klien lain tetap bisa meminta untuk email yang sama.
+func ClientEmail(c *fiber.Ctx) string {
+	var body struct {
+		Email string `json:"email" form:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Email == "" {
+		return ""
+	}
+	return c.IP() + "|" + utils.NormalizeEmail(body.Email)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"regexp"
+
+	"go-fiber-user-management/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID adalah header yang membawa ID request dari klien atau proxy.
+const HeaderRequestID = "X-Request-ID"
+
+// validRequestID membatasi ID dari klien agar aman dicatat di log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID memakai X-Request-ID dari request (jika valid) atau membuat ID baru, mengirimkannya
+// kembali di respons, dan menyimpannya di user context agar ikut tercatat di semua log dan query.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := utils.CopyString(c.Get(HeaderRequestID))
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(HeaderRequestID, id)
+		c.Locals("request_id", id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole membuat middleware yang hanya meneruskan pengguna dengan salah satu role yang diberikan.
+// Harus dipasang setelah JWTAuthorization yang menyimpan pengguna di c.Locals("user").
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(model.User)
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeUnauthorized, "Authentication required")
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				return c.Next()
+			}
+		}
+		return apperror.Forbidden(apperror.CodeForbidden, "You do not have permission to access this resource")
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+// RequireScope membuat middleware yang hanya meneruskan token yang membawa semua scope yang diberikan.
+// Token tanpa klaim scope (diterbitkan sebelum scope diperkenalkan) hanya dianggap membawa scope
+// profil. Scope juga harus tersedia untuk role pemilik token saat ini (model.RoleScopes), sehingga
+// token milik admin yang diturunkan tidak lagi berlaku untuk scope admin. Harus dipasang setelah
+// JWTAuthorization yang menyimpan klaim di c.Locals("jwt") dan pengguna di c.Locals("user").
+func RequireScope(scopes ...string) fiber.Handler {
+	// Format RFC 6750 bagian 3.1 agar klien tahu scope apa yang harus diminta
+	challenge := fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " "))
+
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("jwt").(jwt.MapClaims)
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeUnauthorized, "Authentication required")
+		}
+
+		user, ok := c.Locals("user").(model.User)
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeUnauthorized, "Authentication required")
+		}
+
+		granted := model.GrantedScope(claims["scope"])
+		for _, scope := range scopes {
+			if !model.HasScope(granted, scope) {
+				c.Set(fiber.HeaderWWWAuthenticate, challenge)
+				return apperror.Forbidden(apperror.CodeInsufficientScope,
+					fmt.Sprintf("Token was not issued with the %s scope", scope))
+			}
+			if !slices.Contains(model.RoleScopes(user.Role), scope) {
+				return apperror.Forbidden(apperror.CodeInsufficientScope,
+					fmt.Sprintf("The %s scope is not available for role %s", scope, user.Role))
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RequireRoleScopes membuat middleware yang hanya meneruskan token yang membawa semua scope
+// untuk role pemiliknya (model.RoleScopes), yaitu token yang tidak dipersempit. Dipakai untuk
+// operasi yang bisa menerbitkan kredensial baru. Harus dipasang setelah JWTAuthorization.
+func RequireRoleScopes() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(model.User)
+		if !ok {
+			return apperror.Unauthorized(apperror.CodeUnauthorized, "Authentication required")
+		}
+		return RequireScope(model.RoleScopes(user.Role)...)(c)
+	}
+}
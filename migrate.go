@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"go-fiber-user-management/database"
+	"go-fiber-user-management/migration"
+)
+
+const migrateUsage = `Usage: migrate <command> [options]
+
+Commands:
+  up                    apply all pending migrations
+  down [-steps N|-all]  revert the last N migrations (default 1)
+  status                list migrations and whether they are applied
+  create [-dir D] NAME  create empty up/down scripts for every driver
+`
+
+// runMigrate menjalankan subcommand `migrate` dan mengembalikan kode exit.
+func runMigrate(args []string, cfg database.Config) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	all := flags.Bool("all", false, "revert all migrations")
+	dir := flags.String("dir", "migration/sql", "directory containing <driver>/ migration scripts")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if command == "create" {
+		if flags.NArg() != 1 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			return 2
+		}
+		files, err := migration.Create(*dir, flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "create migration: %v\n", err)
+			return 1
+		}
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return 0
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect database: %v\n", err)
+		return 1
+	}
+	migrator := migration.New(db, cfg.Driver)
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		n := *steps
+		if *all {
+			n = 0
+		}
+		reverted, err := migrator.Down(ctx, n)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
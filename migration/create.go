@@ -0,0 +1,56 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Drivers adalah driver yang masing-masing memiliki direktori skrip migrasi.
+var Drivers = []string{"postgres", "mysql", "sqlite"}
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create membuat pasangan file up/down kosong dengan versi berikutnya untuk setiap driver
+// di dir/<driver>/ dan mengembalikan path file yang dibuat.
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name is required")
+	}
+
+	// Versi berikutnya dihitung dari semua driver agar nomornya tetap sejajar
+	var next uint64 = 1
+	for _, driver := range Drivers {
+		migrations, err := loadDir(os.DirFS(dir), driver)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, m := range migrations {
+			if m.Version >= next {
+				next = m.Version + 1
+			}
+		}
+	}
+
+	var created []string
+	for _, driver := range Drivers {
+		driverDir := filepath.Join(dir, driver)
+		if err := os.MkdirAll(driverDir, 0o755); err != nil {
+			return created, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(driverDir, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			content := fmt.Sprintf("-- %04d_%s (%s, %s)\n", next, name, driver, direction)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return created, err
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-fiber-user-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// backfillNormalizedEmails mengisi users.email_normalized memakai utils.NormalizeEmail dan
+// menggagalkan migrasi jika ada beberapa akun dengan email ternormalisasi yang sama.
+// Duplikat harus diselesaikan secara manual (gabungkan atau ubah email) sebelum migrasi diulang.
+func backfillNormalizedEmails(ctx context.Context, tx *gorm.DB) error {
+	var rows []struct {
+		ID    uint
+		Email string
+	}
+	if err := tx.WithContext(ctx).Table("users").Select("id", "email").Order("id").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	owners := map[string][]uint{}
+	for _, row := range rows {
+		normalized := utils.NormalizeEmail(row.Email)
+		owners[normalized] = append(owners[normalized], row.ID)
+
+		err := tx.WithContext(ctx).Table("users").Where("id = ?", row.ID).
+			Update("email_normalized", normalized).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var duplicates []string
+	for email, ids := range owners {
+		if len(ids) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s (user ids %v)", email, ids))
+		}
+	}
+	if len(duplicates) > 0 {
+		sort.Strings(duplicates)
+		return fmt.Errorf("duplicate emails must be resolved before enforcing uniqueness: %s",
+			strings.Join(duplicates, "; "))
+	}
+	return nil
+}
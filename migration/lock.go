@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// lockName mengidentifikasi kunci migrasi yang dipakai bersama oleh semua instance.
+const lockName = "schema_migrations"
+
+// lockTimeoutSeconds adalah batas waktu menunggu kunci di MySQL.
+const lockTimeoutSeconds = 60
+
+// withLock menjalankan fn sambil memegang kunci migrasi, sehingga beberapa instance yang
+// dijalankan bersamaan tidak menerapkan migrasi yang sama dua kali.
+// Postgres memakai advisory lock, MySQL memakai GET_LOCK, dan SQLite mengandalkan
+// kunci file bawaannya karena hanya diakses oleh satu proses.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if m.driver != "postgres" && m.driver != "mysql" {
+		return fn()
+	}
+
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+
+	// Kunci sesi terikat pada satu koneksi, jadi pakai koneksi khusus dari pool
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch m.driver {
+	case "postgres":
+		key := advisoryLockKey()
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+	case "mysql":
+		var acquired int
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeoutSeconds).Scan(&acquired); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if acquired != 1 {
+			return fmt.Errorf("acquire migration lock: timed out after %ds", lockTimeoutSeconds)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	}
+
+	return fn()
+}
+
+// advisoryLockKey menurunkan kunci advisory Postgres (int64) dari lockName.
+func advisoryLockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(lockName))
+	return int64(h.Sum64())
+}
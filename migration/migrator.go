@@ -0,0 +1,208 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tableName adalah tabel yang mencatat versi migrasi yang sudah dijalankan.
+const tableName = "schema_migrations"
+
+// beforeUp berisi langkah Go yang dijalankan di dalam transaksi sebelum skrip up migrasi
+// tertentu, untuk perubahan data yang tidak bisa ditulis sebagai SQL portabel.
+var beforeUp = map[string]func(ctx context.Context, tx *gorm.DB) error{
+	"0004_unique_email_normalized": backfillNormalizedEmails,
+}
+
+// Status adalah keadaan satu migrasi pada database.
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// appliedMigration adalah baris di tabel schema_migrations.
+type appliedMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (appliedMigration) TableName() string {
+	return tableName
+}
+
+// Migrator menjalankan migrasi berversi untuk satu database.
+type Migrator struct {
+	db     *gorm.DB
+	driver string
+	source fs.FS
+}
+
+// New membuat Migrator yang memakai skrip migrasi bawaan untuk driver.
+func New(db *gorm.DB, driver string) *Migrator {
+	return &Migrator{db: db, driver: driver, source: embedded}
+}
+
+// WithSource mengganti sumber skrip migrasi (struktur direktori sql/<driver>/).
+func (m *Migrator) WithSource(source fs.FS) *Migrator {
+	m.source = source
+	return m
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan, masing-masing di dalam transaksi.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func() error {
+		pending, err := m.pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range pending {
+			if err := m.apply(ctx, mig, mig.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down membatalkan sejumlah migrasi terakhir (steps <= 0 berarti semua).
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func() error {
+		migrations, applied, err := m.load(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if steps > 0 && len(reverted) >= steps {
+				break
+			}
+			mig := migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.apply(ctx, mig, mig.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status mengembalikan keadaan semua migrasi yang dikenal.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, applied, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, mig := range migrations {
+		status := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending mengembalikan migrasi yang belum diterapkan.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	return m.pending(ctx)
+}
+
+func (m *Migrator) pending(ctx context.Context) ([]Migration, error) {
+	migrations, applied, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// load membaca skrip migrasi dan versi yang sudah tercatat di database.
+func (m *Migrator) load(ctx context.Context) ([]Migration, map[uint64]appliedMigration, error) {
+	migrations, err := Load(m.source, m.driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	var rows []appliedMigration
+	if err := m.db.WithContext(ctx).Order("version").Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	applied := make(map[uint64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return migrations, applied, nil
+}
+
+// ensureTable membuat tabel schema_migrations jika belum ada.
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	return m.db.WithContext(ctx).Exec(
+		"CREATE TABLE IF NOT EXISTS " + tableName + " (" +
+			"version BIGINT PRIMARY KEY, " +
+			"name VARCHAR(255) NOT NULL, " +
+			"applied_at TIMESTAMP NOT NULL)",
+	).Error
+}
+
+// apply menjalankan skrip migrasi dan mencatat (up) atau menghapus (down) versinya dalam satu transaksi.
+// Catatan: MySQL melakukan commit implisit untuk DDL, sehingga rollback tidak mencakup perubahan skema.
+func (m *Migrator) apply(ctx context.Context, mig Migration, script string, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if hook, ok := beforeUp[fmt.Sprintf("%04d_%s", mig.Version, mig.Name)]; ok && up {
+			if err := hook(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		if up {
+			return tx.Create(&appliedMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now().UTC()}).Error
+		}
+		return tx.Delete(&appliedMigration{}, "version = ?", mig.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s %s: %w", mig.Version, mig.Name, direction, err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package migration_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"go-fiber-user-management/database"
+	"go-fiber-user-management/migration"
+
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Open(database.Config{
+		Driver:  database.DriverSQLite,
+		Name:    filepath.Join(t.TempDir(), "migrate.db"),
+		SSLMode: "disable",
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return db
+}
+
+func TestUpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	migrator := migration.New(db, database.DriverSQLite)
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("up applied no migrations")
+	}
+	if !db.Migrator().HasTable("users") || !db.Migrator().HasTable("revoked_tokens") {
+		t.Fatal("tables were not created")
+	}
+
+	// Menjalankan ulang tidak menerapkan apa pun
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Fatalf("second up = %v, %v", again, err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt == nil {
+			t.Errorf("migration %04d_%s not applied", s.Version, s.Name)
+		}
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != applied[len(applied)-1].Version {
+		t.Fatalf("down 1 = %v, %v", reverted, err)
+	}
+	if pending, _ := migrator.Pending(ctx); len(pending) != 1 {
+		t.Fatalf("pending after down = %d, want 1", len(pending))
+	}
+
+	if _, err := migrator.Down(ctx, 0); err != nil {
+		t.Fatalf("down all: %v", err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Fatal("users table still exists after down all")
+	}
+}
+
+func TestUniqueEmailMigrationRejectsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	migrator := migration.New(db, database.DriverSQLite)
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	// Kembali ke sebelum 0004 lalu isi data lama yang emailnya hanya beda huruf besar/kecil
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	steps := 0
+	for _, status := range statuses {
+		if status.Version >= 4 {
+			steps++
+		}
+	}
+	if _, err := migrator.Down(ctx, steps); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	for _, email := range []string{"dup@mail.com", "Dup@Mail.com", "other@mail.com"} {
+		err := db.Exec("INSERT INTO users (email, password_hash, fullname) VALUES (?, 'hash', 'Legacy')", email).Error
+		if err != nil {
+			t.Fatalf("seed %s: %v", email, err)
+		}
+	}
+
+	_, err = migrator.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "dup@mail.com") {
+		t.Fatalf("up with duplicates = %v, want duplicate email error", err)
+	}
+	if pending, _ := migrator.Pending(ctx); len(pending) != steps {
+		t.Fatalf("pending after failed up = %d, want %d", len(pending), steps)
+	}
+
+	// Setelah duplikat diselesaikan, migrasi berhasil dan email_normalized terisi
+	if err := db.Exec("UPDATE users SET email = 'dup2@mail.com' WHERE email = 'Dup@Mail.com'").Error; err != nil {
+		t.Fatalf("resolve duplicate: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("up after resolving: %v", err)
+	}
+	var normalized string
+	db.Raw("SELECT email_normalized FROM users WHERE email = 'other@mail.com'").Scan(&normalized)
+	if normalized != "other@mail.com" {
+		t.Fatalf("email_normalized = %q", normalized)
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	source := fstest.MapFS{
+		"sql/sqlite/0001_ok.up.sql":    {Data: []byte("CREATE TABLE ok_table (id INTEGER);")},
+		"sql/sqlite/0001_ok.down.sql":  {Data: []byte("DROP TABLE ok_table;")},
+		"sql/sqlite/0002_bad.up.sql":   {Data: []byte("CREATE TABLE bad_table (id INTEGER);\nTHIS IS NOT SQL;")},
+		"sql/sqlite/0002_bad.down.sql": {Data: []byte("DROP TABLE bad_table;")},
+		"sql/postgres/0001_x.up.sql":   {Data: []byte("SELECT 1;")},
+		"sql/postgres/0001_x.down.sql": {Data: []byte("SELECT 1;")},
+	}
+	migrator := migration.New(db, database.DriverSQLite).WithSource(source)
+
+	applied, err := migrator.Up(ctx)
+	if err == nil {
+		t.Fatal("expected error from invalid migration")
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Fatalf("applied = %v, want only 0001", applied)
+	}
+	if db.Migrator().HasTable("bad_table") {
+		t.Error("failed migration was not rolled back")
+	}
+	if pending, _ := migrator.Pending(ctx); len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("pending = %v, want 0002", pending)
+	}
+}
+
+func TestLoadRequiresUpAndDown(t *testing.T) {
+	source := fstest.MapFS{
+		"sql/sqlite/0001_only_up.up.sql": {Data: []byte("SELECT 1;")},
+	}
+	if _, err := migration.Load(source, "sqlite"); err == nil {
+		t.Fatal("expected error for migration without down script")
+	}
+}
+
+func TestEmbeddedMigrationsMatchAcrossDrivers(t *testing.T) {
+	var want []string
+	for i, driver := range migration.Drivers {
+		migrations, err := migration.Load(migration.Embedded(), driver)
+		if err != nil {
+			t.Fatalf("load %s: %v", driver, err)
+		}
+
+		var names []string
+		for _, m := range migrations {
+			names = append(names, m.Name)
+		}
+		if i == 0 {
+			want = names
+			continue
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("%s migrations = %v, want %v", driver, names, want)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := migration.Create(dir, "Add Phone Index")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(first) != 2*len(migration.Drivers) {
+		t.Fatalf("created %d files, want %d", len(first), 2*len(migration.Drivers))
+	}
+	if filepath.Base(first[0]) != "0001_add_phone_index.up.sql" {
+		t.Errorf("first file = %s", filepath.Base(first[0]))
+	}
+
+	second, err := migration.Create(dir, "second")
+	if err != nil {
+		t.Fatalf("create second: %v", err)
+	}
+	if filepath.Base(second[0]) != "0002_second.up.sql" {
+		t.Errorf("second file = %s", filepath.Base(second[0]))
+	}
+}
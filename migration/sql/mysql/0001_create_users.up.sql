@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    password_hash LONGTEXT NOT NULL,
+    fullname LONGTEXT NOT NULL,
+    address LONGTEXT,
+    gender LONGTEXT,
+    phone_number LONGTEXT,
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    status_reason LONGTEXT,
+    status_changed_at DATETIME(3) NULL,
+    version BIGINT UNSIGNED NOT NULL DEFAULT 1,
+    INDEX idx_users_status (status)
+) DEFAULT CHARSET = utf8mb4;
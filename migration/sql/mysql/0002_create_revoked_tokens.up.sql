@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+    deleted_at DATETIME(3) NULL,
+    token VARCHAR(768) NOT NULL,
+    UNIQUE INDEX idx_revoked_tokens_token (token),
+    INDEX idx_revoked_tokens_deleted_at (deleted_at)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+DROP INDEX idx_users_email_normalized ON users;
+ALTER TABLE users MODIFY COLUMN email_normalized VARCHAR(255) NULL;
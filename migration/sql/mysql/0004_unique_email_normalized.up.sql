@@ -0,0 +1,3 @@
+-- email_normalized diisi oleh hook Go sebelum skrip ini (lihat migration/email.go)
+ALTER TABLE users MODIFY COLUMN email_normalized VARCHAR(255) NOT NULL;
+CREATE UNIQUE INDEX idx_users_email_normalized ON users (email_normalized);
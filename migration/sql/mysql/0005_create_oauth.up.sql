@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash TEXT,
+    name TEXT NOT NULL,
+    redirect_uris TEXT NOT NULL,
+    grant_types VARCHAR(255) NOT NULL,
+    scopes TEXT NOT NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_oauth_clients_client_id (client_id)
+) DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    code_hash VARCHAR(64) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    code_challenge_method VARCHAR(10) NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_oauth_authorization_codes_code_hash (code_hash),
+    INDEX idx_oauth_authorization_codes_expires_at (expires_at)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE oauth_authorization_codes ADD COLUMN nonce VARCHAR(255) NOT NULL DEFAULT '';
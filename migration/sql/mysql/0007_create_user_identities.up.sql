@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_user_identities_provider_subject (provider, subject),
+    UNIQUE INDEX idx_user_identities_user_provider (user_id, provider)
+) DEFAULT CHARSET = utf8mb4;
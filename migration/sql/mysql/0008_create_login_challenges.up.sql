@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    challenge_id VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    device_hash VARCHAR(64) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at DATETIME(3) NOT NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_login_challenges_challenge_id (challenge_id),
+    INDEX idx_login_challenges_user_id (user_id),
+    INDEX idx_login_challenges_expires_at (expires_at)
+) DEFAULT CHARSET = utf8mb4;
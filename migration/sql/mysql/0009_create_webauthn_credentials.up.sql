@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    credential_id VARCHAR(768) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    public_key BLOB NOT NULL,
+    algorithm BIGINT NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports VARCHAR(255) NOT NULL DEFAULT '',
+    aaguid VARCHAR(36) NOT NULL DEFAULT '',
+    user_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME(3) NULL,
+    last_used_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_webauthn_credentials_credential_id (credential_id),
+    INDEX idx_webauthn_credentials_user_id (user_id)
+) DEFAULT CHARSET = utf8mb4;
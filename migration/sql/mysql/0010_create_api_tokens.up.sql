@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    scopes TEXT NOT NULL,
+    expires_at DATETIME(3) NOT NULL,
+    last_used_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    UNIQUE INDEX idx_api_tokens_token_hash (token_hash),
+    INDEX idx_api_tokens_user_id (user_id),
+    INDEX idx_api_tokens_expires_at (expires_at)
+) DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    actor_id BIGINT UNSIGNED NOT NULL,
+    subject_id BIGINT UNSIGNED NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    detail TEXT NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    INDEX idx_audit_events_action (action),
+    INDEX idx_audit_events_actor_id (actor_id),
+    INDEX idx_audit_events_subject_id (subject_id),
+    INDEX idx_audit_events_session_id (session_id),
+    INDEX idx_audit_events_created_at (created_at)
+) DEFAULT CHARSET = utf8mb4;
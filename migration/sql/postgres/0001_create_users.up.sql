@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    password_hash TEXT NOT NULL,
+    fullname TEXT NOT NULL,
+    address TEXT,
+    gender TEXT,
+    phone_number TEXT,
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    status_reason TEXT,
+    status_changed_at TIMESTAMPTZ,
+    version BIGINT NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
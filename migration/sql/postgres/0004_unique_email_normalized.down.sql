@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_email_normalized;
+ALTER TABLE users ALTER COLUMN email_normalized DROP NOT NULL;
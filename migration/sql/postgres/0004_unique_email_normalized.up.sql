@@ -0,0 +1,3 @@
+-- email_normalized diisi oleh hook Go sebelum skrip ini (lihat migration/email.go)
+ALTER TABLE users ALTER COLUMN email_normalized SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users (email_normalized);
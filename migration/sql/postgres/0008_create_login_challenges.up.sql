@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    challenge_id VARCHAR(64) NOT NULL,
+    user_id BIGINT NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    device_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_challenges_challenge_id ON login_challenges (challenge_id);
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user_id ON login_challenges (user_id);
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires_at ON login_challenges (expires_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(50) NOT NULL,
+    actor_id BIGINT NOT NULL,
+    subject_id BIGINT NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    detail TEXT NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_subject_id ON audit_events (subject_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_session_id ON audit_events (session_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
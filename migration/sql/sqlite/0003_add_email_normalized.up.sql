@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN email_normalized VARCHAR(255);
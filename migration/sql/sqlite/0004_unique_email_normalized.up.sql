@@ -0,0 +1,3 @@
+-- email_normalized diisi oleh hook Go sebelum skrip ini (lihat migration/email.go).
+-- SQLite tidak bisa mengubah kolom menjadi NOT NULL, jadi hanya index unik yang dibuat.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_normalized ON users (email_normalized);
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// APIToken adalah token akses pribadi berumur panjang untuk otomasi. Token hanya disimpan
+// sebagai hash; Prefix adalah awal token yang ditampilkan agar pemilik bisa mengenalinya.
+type APIToken struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null;index"`
+	Name       string    `gorm:"size:100;not null"`
+	Prefix     string    `gorm:"size:16;not null"`
+	TokenHash  string    `gorm:"size:64;not null;uniqueIndex"` // SHA-256 token
+	Scopes     string    `gorm:"type:text;not null"`           // Dipisahkan spasi
+	ExpiresAt  time.Time `gorm:"not null;index"`
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// TableName memakai nama tabel api_tokens.
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// APITokenRequestDTO adalah body POST /api/v1/auth/tokens.
+type APITokenRequestDTO struct {
+	Name   string   `json:"name" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required"`
+	// ExpiresIn adalah masa berlaku dalam detik; 0 berarti api_tokens.default_ttl
+	ExpiresIn int64 `json:"expires_in,omitempty"`
+}
+
+// APITokenResponseDTO adalah token akses pribadi tanpa nilai rahasianya.
+type APITokenResponseDTO struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APITokenCreatedDTO dikembalikan sekali saat token dibuat; Token tidak bisa diambil lagi.
+type APITokenCreatedDTO struct {
+	Token    string              `json:"token"`
+	APIToken APITokenResponseDTO `json:"api_token"`
+}
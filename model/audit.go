@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Aksi yang dicatat di audit trail.
+const (
+	AuditImpersonationStart   = "impersonation.start"   // Admin mulai masuk sebagai pengguna; Detail berisi alasan
+	AuditImpersonationRequest = "impersonation.request" // Request dengan token impersonation; Detail berisi method dan path
+	AuditImpersonationEnd     = "impersonation.end"     // Impersonation diakhiri lewat endpoint end
+)
+
+// AuditEvent adalah satu catatan audit trail. Catatan hanya ditambahkan, tidak pernah diubah atau dihapus.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	Action    string    `gorm:"size:50;not null;index"`
+	ActorID   uint      `gorm:"not null;index"`         // Admin yang melakukan aksi
+	SubjectID uint      `gorm:"not null;index"`         // Pengguna yang terdampak
+	SessionID string    `gorm:"size:64;not null;index"` // Mengelompokkan catatan satu sesi impersonation
+	Detail    string    `gorm:"type:text;not null"`
+	IP        string    `gorm:"size:45;not null"`
+	RequestID string    `gorm:"size:128;not null"`
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName memakai nama tabel audit_events.
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditFilter membatasi catatan yang diambil; field kosong tidak memfilter.
+type AuditFilter struct {
+	Action    string
+	ActorID   uint
+	SubjectID uint
+	SessionID string
+	Limit     int // Jumlah catatan terbaru yang diambil
+}
+
+// Actor adalah admin di balik token impersonation, dari klaim act.
+type Actor struct {
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"session_id"`
+}
+
+// ImpersonationRequestDTO adalah body POST /api/v1/users/:id/impersonate.
+type ImpersonationRequestDTO struct {
+	// Reason dicatat di audit trail, mis. nomor tiket dukungan
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ImpersonationResponseDTO berisi token untuk bertindak sebagai pengguna.
+type ImpersonationResponseDTO struct {
+	Token     string          `json:"token"`
+	SessionID string          `json:"session_id"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	User      UserResponseDTO `json:"user"`
+}
+
+// AuditEventResponseDTO adalah satu catatan audit trail.
+type AuditEventResponseDTO struct {
+	ID        uint      `json:"id"`
+	Action    string    `json:"action"`
+	ActorID   uint      `json:"actor_id"`
+	SubjectID uint      `json:"subject_id"`
+	SessionID string    `json:"session_id"`
+	Detail    string    `json:"detail"`
+	IP        string    `json:"ip"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// UserIdentity menautkan subject dari IdP eksternal (Google, GitHub, IdP OpenID Connect
+// perusahaan) ke User. Satu subject hanya tertaut ke satu pengguna, dan satu pengguna
+// hanya punya satu identitas per provider.
+type UserIdentity struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	Provider string `gorm:"size:64;not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_provider"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	// Email dari provider saat identitas ditautkan, hanya sebagai informasi
+	Email     string `gorm:"size:255;not null;default:''"`
+	CreatedAt time.Time
+}
+
+// TableName memakai nama tabel user_identities.
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// IdentityResponseDTO adalah identitas eksternal yang tertaut ke akun pengguna.
+type IdentityResponseDTO struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdentityProviderDTO adalah provider login yang tersedia untuk halaman login.
+type IdentityProviderDTO struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// IdentityLinkRequest adalah body opsional POST /api/v1/auth/oauth/:provider/link.
+type IdentityLinkRequest struct {
+	// ReturnTo adalah URL frontend tujuan setelah callback; harus terdaftar di identity.return_urls
+	ReturnTo string `json:"return_to,omitempty"`
+}
+
+// IdentityLinkResponse berisi URL IdP yang harus dibuka browser untuk menautkan identitas.
+type IdentityLinkResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// IdentityCallbackResponse adalah hasil callback. Token hanya ada untuk login; penautan dari
+// akun yang sudah login hanya mengembalikan identitasnya.
+type IdentityCallbackResponse struct {
+	Token    string              `json:"token,omitempty"`
+	Created  bool                `json:"created"` // Akun baru dibuat lewat provisioning
+	Linked   bool                `json:"linked"`  // Identitas baru ditautkan pada callback ini
+	Identity IdentityResponseDTO `json:"identity"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// Metode pengiriman login tanpa password.
+const (
+	LoginMethodLink = "link" // Magic link yang dibuka dari email
+	LoginMethodCode = "code" // Kode 6 digit yang diketik di perangkat yang meminta
+)
+
+// Jenis challenge ceremony passkey, disimpan di tabel yang sama dengan challenge email.
+const (
+	LoginMethodPasskey  = "passkey"  // Assertion WebAuthn untuk login
+	LoginMethodMFA      = "mfa"      // Token MFA setelah password benar, ditukar dengan assertion passkey
+	PasskeyRegistration = "register" // Registrasi passkey baru oleh pengguna yang sudah login
+)
+
+// LoginChallenge adalah permintaan login tanpa password yang menunggu diverifikasi. Rahasia
+// (token magic link atau kode) dan rahasia perangkat hanya disimpan sebagai hash, dan baris
+// dihapus begitu dipakai sehingga challenge hanya berlaku sekali. Ceremony passkey memakai
+// hash challenge WebAuthn sebagai ChallengeID tanpa SecretHash dan DeviceHash.
+type LoginChallenge struct {
+	ID          uint   `gorm:"primaryKey"`
+	ChallengeID string `gorm:"size:64;not null;uniqueIndex"` // ID publik yang dikirim ke klien
+	UserID      uint   `gorm:"not null;index"`
+	Method      string `gorm:"size:10;not null"`
+	SecretHash  string `gorm:"size:64;not null"` // HMAC token magic link atau kode
+	DeviceHash  string `gorm:"size:64;not null"` // Hash rahasia perangkat yang meminta login
+	// Attempts menghitung percobaan verifikasi, termasuk yang sedang berjalan
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName memakai nama tabel login_challenges.
+func (LoginChallenge) TableName() string {
+	return "login_challenges"
+}
+
+// PasswordlessRequestDTO adalah body POST /api/v1/auth/passwordless.
+type PasswordlessRequestDTO struct {
+	Email string `json:"email" validate:"required,email"`
+	// Method adalah link (default) atau code
+	Method string `json:"method,omitempty"`
+	// DeviceSecret dari permintaan sebelumnya di perangkat yang sama; challenge perangkat itu
+	// diganti. Boleh kosong jika cookie perangkat terkirim
+	DeviceSecret string `json:"device_secret,omitempty"`
+}
+
+// PasswordlessChallengeDTO dikembalikan setelah permintaan login tanpa password, juga untuk
+// email yang tidak terdaftar agar keberadaan akun tidak bocor.
+type PasswordlessChallengeDTO struct {
+	ChallengeID string `json:"challenge_id"`
+	Method      string `json:"method"`
+	// DeviceSecret harus dikirim kembali saat verifikasi dari perangkat yang sama; browser
+	// juga menerimanya sebagai cookie HttpOnly
+	DeviceSecret string `json:"device_secret"`
+	ExpiresIn    int64  `json:"expires_in"` // Detik
+}
+
+// PasswordlessVerifyDTO adalah body POST /api/v1/auth/passwordless/verify. Secret berisi token
+// dari magic link atau kode dari email; DeviceSecret boleh kosong jika cookie perangkat terkirim.
+type PasswordlessVerifyDTO struct {
+	ChallengeID  string `json:"challenge_id" validate:"required"`
+	Secret       string `json:"secret" validate:"required"`
+	DeviceSecret string `json:"device_secret,omitempty"`
+}
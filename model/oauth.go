@@ -0,0 +1,172 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Grant type OAuth2 yang didukung oleh server otorisasi.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+)
+
+// PKCEMethodS256 adalah satu-satunya code_challenge_method yang diterima.
+const PKCEMethodS256 = "S256"
+
+// OAuthClient adalah aplikasi (SPA, aplikasi partner, atau layanan lain) yang terdaftar
+// untuk mendapatkan token lewat server otorisasi. Daftar disimpan dipisahkan spasi.
+type OAuthClient struct {
+	ID       uint   `gorm:"primaryKey"`
+	ClientID string `gorm:"size:64;not null;uniqueIndex"`
+	// Hash bcrypt dari client secret; kosong untuk public client (mis. SPA) yang hanya memakai PKCE
+	SecretHash   string
+	Name         string `gorm:"not null"`
+	RedirectURIs string `gorm:"type:text;not null"`
+	GrantTypes   string `gorm:"size:255;not null"`
+	Scopes       string `gorm:"type:text;not null"` // Scope yang boleh diminta klien
+	CreatedAt    time.Time
+}
+
+// TableName memakai nama tabel oauth_clients alih-alih o_auth_clients bawaan GORM.
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// Confidential menandakan klien memiliki secret dan wajib mengautentikasi diri di token endpoint.
+func (c OAuthClient) Confidential() bool {
+	return c.SecretHash != ""
+}
+
+// AllowsGrant memeriksa apakah klien boleh memakai grant type tertentu.
+func (c OAuthClient) AllowsGrant(grant string) bool {
+	return containsField(c.GrantTypes, grant)
+}
+
+// AllowsRedirectURI memeriksa apakah uri persis sama dengan salah satu redirect URI terdaftar.
+func (c OAuthClient) AllowsRedirectURI(uri string) bool {
+	return containsField(c.RedirectURIs, uri)
+}
+
+// AllowsScope memeriksa apakah setiap scope di scope (dipisahkan spasi) terdaftar untuk klien.
+func (c OAuthClient) AllowsScope(scope string) bool {
+	for _, s := range strings.Fields(scope) {
+		if !containsField(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsField(list, value string) bool {
+	for _, item := range strings.Fields(list) {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthAuthorizationCode adalah kode otorisasi sekali pakai yang ditukar dengan token.
+// Hanya hash SHA-256 dari kode yang disimpan.
+type OAuthAuthorizationCode struct {
+	ID                  uint      `gorm:"primaryKey"`
+	CodeHash            string    `gorm:"size:64;not null;uniqueIndex"`
+	ClientID            string    `gorm:"size:64;not null"`
+	UserID              uint      `gorm:"not null"`
+	RedirectURI         string    `gorm:"type:text;not null"`
+	Scope               string    `gorm:"type:text;not null"`
+	CodeChallenge       string    `gorm:"size:128;not null"`
+	CodeChallengeMethod string    `gorm:"size:10;not null"`
+	Nonce               string    `gorm:"size:255;not null;default:''"` // Nonce OpenID Connect untuk ID token
+	ExpiresAt           time.Time `gorm:"not null;index"`
+	CreatedAt           time.Time
+}
+
+// TableName memakai nama tabel oauth_authorization_codes.
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// OAuthClientRequestDTO mendefinisikan body POST /api/v1/oauth/clients.
+type OAuthClientRequestDTO struct {
+	Name         string   `json:"name" validate:"required"`
+	RedirectURIs []string `json:"redirect_uris,omitempty"`
+	GrantTypes   []string `json:"grant_types" validate:"required"`
+	Scopes       []string `json:"scopes,omitempty"`
+	// Confidential membuat client secret; wajib untuk client_credentials
+	Confidential bool `json:"confidential,omitempty"`
+}
+
+// OAuthClientResponseDTO adalah data klien yang dikirim ke admin. ClientSecret hanya ada
+// sekali, pada response pendaftaran.
+type OAuthClientResponseDTO struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthAuthorizationRequest adalah parameter authorization endpoint (query GET atau form POST).
+type OAuthAuthorizationRequest struct {
+	ResponseType        string `query:"response_type" form:"response_type"`
+	ClientID            string `query:"client_id" form:"client_id"`
+	RedirectURI         string `query:"redirect_uri" form:"redirect_uri"`
+	Scope               string `query:"scope" form:"scope"`
+	State               string `query:"state" form:"state"`
+	CodeChallenge       string `query:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method" form:"code_challenge_method"`
+	Nonce               string `query:"nonce" form:"nonce"` // OpenID Connect; dikembalikan di ID token
+}
+
+// OAuthTokenRequest adalah body form token endpoint untuk semua grant type.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" validate:"required"`
+	Code         string `form:"code" json:"code,omitempty"`
+	RedirectURI  string `form:"redirect_uri" json:"redirect_uri,omitempty"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier,omitempty"`
+	Scope        string `form:"scope" json:"scope,omitempty"`
+	ClientID     string `form:"client_id" json:"client_id,omitempty"`
+	ClientSecret string `form:"client_secret" json:"client_secret,omitempty"`
+}
+
+// OAuthTokenResponse adalah response sukses token endpoint (RFC 6749 bagian 5.1).
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	// IDToken hanya diterbitkan untuk authorization code dengan scope openid
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// OAuthTokenActionRequest adalah body form endpoint introspection dan revocation.
+type OAuthTokenActionRequest struct {
+	Token         string `form:"token" json:"token" validate:"required"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint,omitempty"`
+	ClientID      string `form:"client_id" json:"client_id,omitempty"`
+	ClientSecret  string `form:"client_secret" json:"client_secret,omitempty"`
+}
+
+// OAuthIntrospection adalah response introspection (RFC 7662). Token yang tidak aktif
+// hanya membawa active=false.
+type OAuthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+}
+
+// OAuthError adalah body error token, introspection, dan revocation endpoint (RFC 6749 bagian 5.2).
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -7,5 +7,5 @@ import (
 // RevokedToken represents a revoked token in the database
 type RevokedToken struct {
 	gorm.Model
-	Token string `gorm:"not null;uniqueIndex"` // Token yang dibatalkan
+	Token string `gorm:"size:768;not null;uniqueIndex"` // Token yang dibatalkan (768 karakter agar index muat di MySQL utf8mb4)
 }
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+// Scope API yang membatasi apa yang boleh dilakukan sebuah token. Role pengguna tetap
+// berlaku; scope hanya bisa mempersempit akses, tidak menambahnya.
+const (
+	ScopeProfileRead  = "profile:read"  // Membaca profil, identitas tertaut, dan passkey sendiri
+	ScopeProfileWrite = "profile:write" // Mengganti nama atau menghapus passkey dan identitas tertaut sendiri
+	ScopeUsersRead    = "users:read"    // Membaca data pengguna (admin)
+	ScopeUsersWrite   = "users:write"   // Membuat, mengubah, dan menghapus pengguna (admin)
+)
+
+// APIScopes adalah semua scope API yang dikenal, dalam urutan tampilan.
+var APIScopes = []string{ScopeProfileRead, ScopeProfileWrite, ScopeUsersRead, ScopeUsersWrite}
+
+// ProfileScopes adalah scope untuk data milik pengguna sendiri, yang tersedia untuk setiap role.
+var ProfileScopes = []string{ScopeProfileRead, ScopeProfileWrite}
+
+// RoleScopes mengembalikan scope API yang tersedia untuk role: semua scope untuk admin, dan
+// hanya ProfileScopes untuk role lain.
+func RoleScopes(role string) []string {
+	if role == RoleAdmin {
+		return APIScopes
+	}
+	return ProfileScopes
+}
+
+// DefaultScope mengembalikan RoleScopes dipisahkan spasi, scope bawaan token hasil login.
+func DefaultScope(role string) string {
+	return strings.Join(RoleScopes(role), " ")
+}
+
+// GrantedScope mengembalikan klaim scope token. Token tanpa klaim scope (diterbitkan sebelum
+// scope diperkenalkan) hanya mendapat ProfileScopes, apa pun role pemiliknya.
+func GrantedScope(claim interface{}) string {
+	if scope, ok := claim.(string); ok {
+		return scope
+	}
+	return strings.Join(ProfileScopes, " ")
+}
+
+// HasScope memeriksa apakah scope (dipisahkan spasi) memuat want.
+func HasScope(scope, want string) bool {
+	return slices.Contains(strings.Fields(scope), want)
+}
+
+// IsAPIScope memeriksa apakah scope adalah scope API yang dikenal.
+func IsAPIScope(scope string) bool {
+	return slices.Contains(APIScopes, scope)
+}
+
+// SplitScope memecah scope yang dipisahkan spasi menjadi slice (kosong jika scope kosong).
+func SplitScope(scope string) []string {
+	fields := strings.Fields(scope)
+	if fields == nil {
+		return []string{}
+	}
+	return fields
+}
@@ -0,0 +1,40 @@
+package model
+
+// Jenis operasi yang didukung oleh endpoint batch pengguna.
+const (
+	BatchOpSuspend    = "suspend"
+	BatchOpReactivate = "reactivate"
+	BatchOpDisable    = "disable"
+	BatchOpDelete     = "delete"
+	BatchOpSetRole    = "set_role"
+)
+
+// Mode eksekusi batch: atomic (semua atau tidak sama sekali) atau best_effort.
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+// UserBatchOperation mendefinisikan satu operasi di dalam permintaan batch.
+type UserBatchOperation struct {
+	Op     string `json:"op"`               // Jenis operasi (suspend, reactivate, disable, delete, set_role)
+	ID     uint   `json:"id"`               // ID pengguna yang menjadi target
+	Role   string `json:"role,omitempty"`   // Peran baru, hanya untuk set_role
+	Reason string `json:"reason,omitempty"` // Alasan perubahan status (opsional)
+}
+
+// UserBatchRequest mendefinisikan struktur permintaan untuk POST /api/v1/users/batch.
+type UserBatchRequest struct {
+	Mode       string               `json:"mode"` // atomic atau best_effort (default: atomic)
+	Operations []UserBatchOperation `json:"operations"`
+}
+
+// UserBatchResult adalah hasil eksekusi satu operasi batch.
+type UserBatchResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	ID      uint   `json:"id"`
+	Status  int    `json:"status"`         // Kode status HTTP untuk operasi ini
+	Code    string `json:"code,omitempty"` // Kode error stabil jika operasi gagal
+	Message string `json:"message"`
+}
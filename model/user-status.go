@@ -0,0 +1,79 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status akun pengguna.
+const (
+	StatusPending   = "pending"   // Akun terdaftar tetapi belum diaktifkan oleh admin
+	StatusActive    = "active"    // Akun aktif dan boleh login
+	StatusSuspended = "suspended" // Akun ditangguhkan sementara oleh admin
+	StatusLocked    = "locked"    // Akun dikunci oleh admin karena alasan keamanan, mis. dicurigai diambil alih
+	StatusDisabled  = "disabled"  // Akun dinonaktifkan secara permanen oleh admin
+)
+
+// statusTransitions mendefinisikan perpindahan status yang diizinkan.
+var statusTransitions = map[string][]string{
+	StatusPending:   {StatusActive, StatusDisabled},
+	StatusActive:    {StatusSuspended, StatusLocked, StatusDisabled},
+	StatusSuspended: {StatusActive, StatusDisabled},
+	StatusLocked:    {StatusActive, StatusDisabled},
+	StatusDisabled:  {StatusActive},
+}
+
+// IsValidStatus memeriksa apakah status termasuk status yang dikenal.
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+// CanTransitionStatus memeriksa apakah status boleh berpindah dari `from` ke `to`.
+func CanTransitionStatus(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionStatus mengubah status pengguna beserta alasan dan waktunya.
+// Mengembalikan error jika perpindahan status tidak diizinkan.
+func (u *User) TransitionStatus(to, reason string) error {
+	from := u.Status
+	if from == "" {
+		from = StatusActive
+	}
+	if !CanTransitionStatus(from, to) {
+		return fmt.Errorf("cannot change status from %s to %s", from, to)
+	}
+
+	now := time.Now()
+	u.Status = to
+	u.StatusReason = reason
+	u.StatusChangedAt = &now
+	return nil
+}
+
+// StatusMessage mengembalikan pesan yang menjelaskan kenapa akun dengan status ini tidak boleh dipakai.
+func StatusMessage(status string) string {
+	switch status {
+	case StatusPending:
+		return "Akun belum diaktifkan"
+	case StatusSuspended:
+		return "Akun ditangguhkan"
+	case StatusLocked:
+		return "Akun dikunci"
+	case StatusDisabled:
+		return "Akun dinonaktifkan"
+	default:
+		return "Status akun tidak valid"
+	}
+}
+
+// UserStatusRequest mendefinisikan body opsional untuk endpoint perubahan status.
+type UserStatusRequest struct {
+	Reason string `json:"reason"` // Alasan perubahan status
+}
@@ -1,14 +1,25 @@
 package model
 
+import "time"
+
 // Representasi model User di database.
 type User struct {
-	ID           uint   `gorm:"primaryKey" json:"id"`
-	Email        string `gorm:"not null" json:"email"`
-	PasswordHash string `gorm:"not null" json:"password_hash,omitempty"`
-	Fullname     string `gorm:"not null" json:"fullname"` // Nama lengkap pengguna
-	Address      string `json:"address,omitempty"`        // Alamat pengguna (opsional)
-	Gender       string `json:"gender,omitempty"`         // Jenis kelamin pengguna (opsional)
-	PhoneNumber  string `json:"phone_number,omitempty"`   // Nomor telepon pengguna (opsional)
+	ID    uint   `gorm:"primaryKey" json:"id"`
+	Email string `gorm:"size:255;not null" json:"email"`
+	// Email ternormalisasi (lihat utils.NormalizeEmail), unik dan dipakai untuk pencarian
+	EmailNormalized string `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	PasswordHash    string `gorm:"not null" json:"password_hash,omitempty"`
+	Fullname        string `gorm:"not null" json:"fullname"`                  // Nama lengkap pengguna
+	Address         string `json:"address,omitempty"`                         // Alamat pengguna (opsional)
+	Gender          string `json:"gender,omitempty"`                          // Jenis kelamin pengguna (opsional)
+	PhoneNumber     string `json:"phone_number,omitempty"`                    // Nomor telepon pengguna (opsional)
+	Role            string `gorm:"size:20;not null;default:user" json:"role"` // Peran pengguna (user/admin)
+
+	Status          string     `gorm:"size:20;not null;default:active;index" json:"status"` // Status akun (lihat user-status.go)
+	StatusReason    string     `json:"status_reason,omitempty"`                             // Alasan perubahan status terakhir
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`                         // Waktu perubahan status terakhir
+
+	Version uint `gorm:"not null;default:1" json:"version"` // Versi baris untuk optimistic locking
 }
 
 // UserResponseDTO untuk data transfer object for ketika update profile.
@@ -19,6 +30,16 @@ type UserResponseDTO struct {
 	Address     string `json:"address,omitempty"`
 	Gender      string `json:"gender,omitempty"`
 	PhoneNumber string `json:"phone_number,omitempty"`
+	Role        string `json:"role"`
+
+	Status          string     `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+
+	Version uint `json:"version"`
+
+	// Act adalah admin yang sedang bertindak sebagai pengguna ini; hanya ada di profil token impersonation
+	Act *Actor `json:"act,omitempty"`
 }
 
 // UserRequestDTO untuk data transfer object for ketika update profile.
@@ -31,8 +52,38 @@ type UserRequestDTO struct {
 	PhoneNumber string `json:"phone_number,omitempty"`          // Nomor telepon pengguna (opsional)
 }
 
+// UserPatchDTO untuk pembaruan sebagian; field nil tidak diubah.
+type UserPatchDTO struct {
+	Email       *string `json:"email,omitempty"`
+	Password    *string `json:"password,omitempty"`
+	Fullname    *string `json:"fullname,omitempty"`
+	Address     *string `json:"address,omitempty"`
+	Gender      *string `json:"gender,omitempty"`
+	PhoneNumber *string `json:"phone_number,omitempty"`
+}
+
 // authenticationRequest mendefinisikan struktur permintaan untuk pendaftaran dan login.
 type AuthenticationRequest struct {
 	Email    string `json:"email" validate:"required,email"`    // Email harus berupa format email yang valid
 	Password string `json:"password" validate:"required,min=6"` // Password harus memiliki panjang minimal 6 karakter
+	// Scope membatasi token hasil login ke sebagian scope API (dipisahkan spasi); kosong berarti
+	// semua scope untuk role pengguna
+	Scope string `json:"scope,omitempty"`
+}
+
+// TokenExchangeRequest adalah body POST /api/v1/auth/exchange.
+type TokenExchangeRequest struct {
+	// Scope adalah scope API token baru (dipisahkan spasi), harus bagian dari scope token yang dipakai
+	Scope string `json:"scope" validate:"required"`
+}
+
+// Peran pengguna yang dikenal oleh aplikasi.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// IsValidRole memeriksa apakah peran termasuk peran yang dikenal.
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
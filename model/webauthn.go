@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-user-management/webauthn"
+)
+
+// WebAuthnCredential adalah passkey atau security key milik pengguna. Satu pengguna boleh
+// punya beberapa kredensial; CredentialID unik di seluruh server.
+type WebAuthnCredential struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;index"`
+	CredentialID string `gorm:"size:768;not null;uniqueIndex"` // base64url credential ID (768 karakter agar index muat di MySQL utf8mb4)
+	Name         string `gorm:"size:100;not null"`
+	PublicKey    []byte `gorm:"not null"` // Kunci publik COSE
+	Algorithm    int64  `gorm:"not null"`
+	// SignCount dari authenticator untuk mendeteksi kredensial yang digandakan; passkey yang
+	// disinkronkan biasanya selalu 0
+	SignCount  int64  `gorm:"not null;default:0"`
+	Transports string `gorm:"size:255;not null;default:''"`              // Dipisahkan koma, mis. internal,hybrid
+	AAGUID     string `gorm:"column:aaguid;size:36;not null;default:''"` // Model authenticator
+	// UserVerified mencatat apakah registrasi memakai PIN atau biometrik
+	UserVerified   bool `gorm:"not null;default:false"`
+	BackupEligible bool `gorm:"not null;default:false"` // Passkey yang disinkronkan antar perangkat
+	CreatedAt      time.Time
+	LastUsedAt     *time.Time
+}
+
+// TableName memakai nama tabel webauthn_credentials.
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// PasskeyResponseDTO adalah passkey yang terdaftar, tanpa kunci publik.
+type PasskeyResponseDTO struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	CredentialID   string     `json:"credential_id"`
+	AAGUID         string     `json:"aaguid,omitempty"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backup_eligible"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
+
+// PasskeyRegisterRequest adalah body POST /api/v1/auth/webauthn/register/finish. Credential
+// adalah hasil PublicKeyCredential.toJSON() dari navigator.credentials.create.
+type PasskeyRegisterRequest struct {
+	Name       string                       `json:"name,omitempty"`
+	Credential webauthn.AttestationResponse `json:"credential" validate:"required"`
+}
+
+// PasskeyLoginBeginRequest adalah body opsional POST /api/v1/auth/webauthn/login/begin.
+type PasskeyLoginBeginRequest struct {
+	// MFAToken dari error second_factor_required login password; kosong untuk login tanpa password
+	MFAToken string `json:"mfa_token,omitempty"`
+}
+
+// PasskeyLoginRequest adalah body POST /api/v1/auth/webauthn/login/finish. Credential adalah
+// hasil PublicKeyCredential.toJSON() dari navigator.credentials.get.
+type PasskeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential" validate:"required"`
+}
+
+// PasskeyRenameRequest adalah body PATCH /api/v1/auth/webauthn/credentials/:id.
+type PasskeyRenameRequest struct {
+	Name string `json:"name" validate:"required"`
+}
@@ -0,0 +1,114 @@
+package oidc
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Scope OpenID Connect (OIDC Core bagian 5.4). ScopeOpenID wajib ada agar ID token diterbitkan.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+	ScopeAddress = "address"
+)
+
+// Scopes adalah scope yang didukung (scopes_supported).
+var Scopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone, ScopeAddress}
+
+// Address adalah claim address; alamat pengguna disimpan sebagai satu teks bebas.
+type Address struct {
+	Formatted string `json:"formatted"`
+}
+
+// UserInfo adalah claim pengguna yang dikirim di /userinfo dan ID token, disaring sesuai scope.
+type UserInfo struct {
+	Sub         string   `json:"sub"`
+	Name        string   `json:"name,omitempty"`         // Scope profile
+	Gender      string   `json:"gender,omitempty"`       // Scope profile
+	Email       string   `json:"email,omitempty"`        // Scope email
+	PhoneNumber string   `json:"phone_number,omitempty"` // Scope phone
+	Address     *Address `json:"address,omitempty"`      // Scope address
+}
+
+// HasScope memeriksa apakah want ada di daftar scope yang dipisahkan spasi.
+func HasScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject mengembalikan claim sub pengguna: ID yang stabil dan tidak pernah dipakai ulang.
+func Subject(user model.User) string {
+	return strconv.FormatUint(uint64(user.ID), 10)
+}
+
+// UserClaims membentuk claim pengguna yang boleh dilihat klien dengan scope tertentu.
+// sub selalu ada; claim lain hanya untuk scope yang diberikan.
+func UserClaims(user model.User, scope string) UserInfo {
+	info := UserInfo{Sub: Subject(user)}
+	if HasScope(scope, ScopeProfile) {
+		info.Name = user.Fullname
+		info.Gender = user.Gender
+	}
+	if HasScope(scope, ScopeEmail) {
+		info.Email = user.Email
+	}
+	if HasScope(scope, ScopePhone) {
+		info.PhoneNumber = user.PhoneNumber
+	}
+	if HasScope(scope, ScopeAddress) && user.Address != "" {
+		info.Address = &Address{Formatted: user.Address}
+	}
+	return info
+}
+
+// IDToken berisi data untuk menerbitkan satu ID token.
+type IDToken struct {
+	Issuer   string
+	ClientID string
+	User     model.User
+	Scope    string
+	Nonce    string    // Dikembalikan apa adanya jika dikirim di authorization request
+	AuthTime time.Time // Waktu pengguna login di halaman consent
+	TTL      time.Duration
+}
+
+// Claims membentuk claim ID token (OIDC Core bagian 2) beserta claim pengguna sesuai scope.
+func (t IDToken) Claims() jwt.MapClaims {
+	now := time.Now()
+	info := UserClaims(t.User, t.Scope)
+
+	claims := jwt.MapClaims{
+		"iss":       strings.TrimSuffix(t.Issuer, "/"),
+		"sub":       info.Sub,
+		"aud":       t.ClientID,
+		"azp":       t.ClientID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(t.TTL).Unix(),
+		"auth_time": t.AuthTime.Unix(),
+	}
+	if t.Nonce != "" {
+		claims["nonce"] = t.Nonce
+	}
+	for key, value := range map[string]string{
+		"name": info.Name, "gender": info.Gender, "email": info.Email, "phone_number": info.PhoneNumber,
+	} {
+		if value != "" {
+			claims[key] = value
+		}
+	}
+	if info.Address != nil {
+		claims["address"] = map[string]string{"formatted": info.Address.Formatted}
+	}
+	return claims
+}
@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"strings"
+
+	"go-fiber-user-management/model"
+)
+
+// Path endpoint yang diumumkan di discovery document, relatif terhadap issuer.
+const (
+	DiscoveryPath     = "/.well-known/openid-configuration"
+	JWKSPath          = "/.well-known/jwks.json"
+	UserInfoPath      = "/userinfo"
+	AuthorizePath     = "/oauth/authorize"
+	TokenPath         = "/oauth/token"
+	IntrospectionPath = "/oauth/introspect"
+	RevocationPath    = "/oauth/revoke"
+)
+
+// Discovery adalah OpenID Provider Metadata (OpenID Connect Discovery 1.0 bagian 3).
+type Discovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// NewDiscovery membentuk discovery document untuk issuer (URL publik server).
+func NewDiscovery(issuer string) Discovery {
+	issuer = strings.TrimSuffix(issuer, "/")
+	return Discovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + AuthorizePath,
+		TokenEndpoint:                     issuer + TokenPath,
+		UserInfoEndpoint:                  issuer + UserInfoPath,
+		JWKSURI:                           issuer + JWKSPath,
+		IntrospectionEndpoint:             issuer + IntrospectionPath,
+		RevocationEndpoint:                issuer + RevocationPath,
+		ScopesSupported:                   Scopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{model.GrantAuthorizationCode, model.GrantClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{Algorithm},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{model.PKCEMethodS256},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "azp", "exp", "iat", "auth_time", "nonce",
+			"name", "gender", "email", "phone_number", "address",
+		},
+	}
+}
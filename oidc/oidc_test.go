@@ -0,0 +1,82 @@
+package oidc_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/oidc"
+)
+
+func TestUserClaimsFollowScope(t *testing.T) {
+	user := model.User{ID: 7, Email: "user@mail.com", Fullname: "User", PhoneNumber: "0812", Address: "Jl. Merdeka 1"}
+
+	tests := []struct {
+		scope string
+		want  oidc.UserInfo
+	}{
+		{"openid", oidc.UserInfo{Sub: "7"}},
+		{"openid email", oidc.UserInfo{Sub: "7", Email: "user@mail.com"}},
+		{"openid profile phone", oidc.UserInfo{Sub: "7", Name: "User", PhoneNumber: "0812"}},
+	}
+	for _, tt := range tests {
+		if got := oidc.UserClaims(user, tt.scope); got != tt.want {
+			t.Errorf("UserClaims(%q) = %+v, want %+v", tt.scope, got, tt.want)
+		}
+	}
+	if got := oidc.UserClaims(user, "openid address"); got.Address == nil || got.Address.Formatted != user.Address {
+		t.Errorf("address claim = %+v", got.Address)
+	}
+}
+
+func writeKey(t *testing.T, bits int) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return path
+}
+
+func TestLoadSigner(t *testing.T) {
+	signer, err := oidc.LoadSigner(writeKey(t, 2048))
+	if err != nil {
+		t.Fatalf("load signer: %v", err)
+	}
+
+	token, err := signer.Sign(oidc.IDToken{
+		Issuer: "https://auth.example.com", ClientID: "client", User: model.User{ID: 1},
+		Scope: "openid", Nonce: "abc", AuthTime: time.Now(), TTL: time.Minute,
+	}.Claims())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	claims, err := signer.Verify(token)
+	if err != nil || claims["nonce"] != "abc" || claims["aud"] != "client" {
+		t.Fatalf("verify = %v, %v", claims, err)
+	}
+	if jwks := signer.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != signer.KeyID() {
+		t.Fatalf("jwks = %+v", jwks)
+	}
+
+	// Token yang ditandatangani kunci lain ditolak
+	if _, err := oidc.Ephemeral().Verify(token); err == nil {
+		t.Fatal("token verified with a different key")
+	}
+
+	if _, err := oidc.LoadSigner(writeKey(t, 1024)); err == nil {
+		t.Fatal("1024-bit key accepted")
+	}
+}
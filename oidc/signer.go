@@ -0,0 +1,128 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Algorithm adalah algoritma tanda tangan ID token (id_token_signing_alg_values_supported).
+const Algorithm = "RS256"
+
+// Signer menandatangani ID token dengan kunci RSA dan menerbitkan kunci publiknya sebagai JWKS,
+// sehingga aplikasi lain bisa memverifikasi ID token tanpa mengetahui secret server.
+type Signer struct {
+	key *rsa.PrivateKey
+	kid string
+}
+
+// NewSigner membuat Signer dari kunci privat RSA. Key ID adalah thumbprint RFC 7638 kunci publik.
+func NewSigner(key *rsa.PrivateKey) *Signer {
+	return &Signer{key: key, kid: thumbprint(&key.PublicKey)}
+}
+
+// LoadSigner membaca kunci privat RSA berformat PEM (PKCS#1 atau PKCS#8) dari path.
+func LoadSigner(path string) (*Signer, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key %s: %w", path, err)
+	}
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("signing key %s must be at least 2048 bits, got %d", path, key.N.BitLen())
+	}
+	return NewSigner(key), nil
+}
+
+var ephemeral struct {
+	once   sync.Once
+	signer *Signer
+}
+
+// Ephemeral mengembalikan Signer dengan kunci yang dibuat sekali per proses. Dipakai jika
+// OAUTH_SIGNING_KEY_FILE tidak diset (pengembangan dan test).
+func Ephemeral() *Signer {
+	ephemeral.once.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(fmt.Sprintf("oidc: generate signing key: %v", err))
+		}
+		ephemeral.signer = NewSigner(key)
+	})
+	return ephemeral.signer
+}
+
+// KeyID mengembalikan kid yang ditulis di header setiap ID token.
+func (s *Signer) KeyID() string {
+	return s.kid
+}
+
+// Sign menandatangani claims dengan RS256.
+func (s *Signer) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+	return token.SignedString(s.key)
+}
+
+// Verify memeriksa tanda tangan dan masa berlaku ID token lalu mengembalikan klaimnya.
+func (s *Signer) Verify(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return &s.key.PublicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("token tidak valid")
+	}
+	return claims, nil
+}
+
+// JWK adalah kunci publik RSA dalam format JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSet adalah isi jwks_uri.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan kunci publik untuk memverifikasi ID token.
+func (s *Signer) JWKS() JWKSet {
+	n, e := publicParams(&s.key.PublicKey)
+	return JWKSet{Keys: []JWK{{Kty: "RSA", Use: "sig", Alg: Algorithm, Kid: s.kid, N: n, E: e}}}
+}
+
+// publicParams mengembalikan modulus dan eksponen kunci publik dalam base64url.
+func publicParams(key *rsa.PublicKey) (string, string) {
+	return base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+}
+
+// thumbprint menghitung JWK thumbprint SHA-256 (RFC 7638): member wajib diurutkan
+// secara leksikografis tanpa spasi.
+func thumbprint(key *rsa.PublicKey) string {
+	n, e := publicParams(key)
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+//go:embed swagger.html
+var swaggerPage string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerPage))
+
+// integrityPattern adalah format nilai Subresource Integrity, mis. sha384-<base64>.
+var integrityPattern = regexp.MustCompile(`^sha(256|384|512)-[A-Za-z0-9+/]+={0,2}$`)
+
+// DocsConfig mengatur halaman /docs. Tanpa SwaggerUIURL, /docs memakai halaman bawaan yang
+// tidak memuat skrip pihak ketiga.
+type DocsConfig struct {
+	// SwaggerUIURL adalah direktori berisi swagger-ui.css dan swagger-ui-bundle.js, mis.
+	// https://unpkg.com/swagger-ui-dist@5.17.14 atau /static/swagger-ui untuk salinan lokal
+	SwaggerUIURL string `yaml:"swagger_ui_url" toml:"swagger_ui_url"`
+	// Hash SRI kedua aset; wajib jika SwaggerUIURL berada di origin lain
+	ScriptIntegrity string `yaml:"script_integrity" toml:"script_integrity"`
+	StyleIntegrity  string `yaml:"style_integrity" toml:"style_integrity"`
+}
+
+// Validate memeriksa konfigurasi halaman dokumentasi.
+func (cfg DocsConfig) Validate() error {
+	if cfg.SwaggerUIURL == "" {
+		return nil
+	}
+	u, err := url.Parse(cfg.SwaggerUIURL)
+	switch {
+	case err != nil, u.RawQuery != "", u.Fragment != "":
+		return fmt.Errorf("DOCS_SWAGGER_UI_URL must be a URL without query or fragment, got %q", cfg.SwaggerUIURL)
+	case u.IsAbs() && (u.Scheme != "https" && u.Scheme != "http" || u.Host == ""):
+		return fmt.Errorf("DOCS_SWAGGER_UI_URL must be an http(s) URL, got %q", cfg.SwaggerUIURL)
+	case !u.IsAbs() && (u.Host != "" || !strings.HasPrefix(u.Path, "/")):
+		return fmt.Errorf("DOCS_SWAGGER_UI_URL must be absolute or start with /, got %q", cfg.SwaggerUIURL)
+	}
+
+	var errs []error
+	// Aset dari origin lain hanya dimuat jika isinya dipin dengan SRI
+	for _, field := range []struct{ key, value string }{
+		{"DOCS_SCRIPT_INTEGRITY", cfg.ScriptIntegrity},
+		{"DOCS_STYLE_INTEGRITY", cfg.StyleIntegrity},
+	} {
+		switch {
+		case field.value == "" && u.IsAbs():
+			errs = append(errs, fmt.Errorf("%s is required when DOCS_SWAGGER_UI_URL is on another origin", field.key))
+		case field.value != "" && !integrityPattern.MatchString(field.value):
+			errs = append(errs, fmt.Errorf("%s must look like sha384-<base64>, got %q", field.key, field.value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handler mengirim dokumen OpenAPI dalam JSON. Dokumen dibentuk sekali dari rute yang
+// terdaftar di aplikasi saat request pertama, ketika semua rute sudah didaftarkan.
+func Handler() fiber.Handler {
+	var (
+		once sync.Once
+		doc  Document
+	)
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			doc = Build(c.App().GetRoutes(true))
+		})
+		return c.JSON(doc)
+	}
+}
+
+// Docs mengirim halaman dokumentasi yang membaca /openapi.json: Swagger UI dari
+// cfg.SwaggerUIURL jika diisi, atau halaman bawaan jika tidak. cfg harus sudah divalidasi.
+func Docs(cfg DocsConfig) fiber.Handler {
+	page := docsPage
+	if cfg.SwaggerUIURL != "" {
+		var buf bytes.Buffer
+		err := swaggerTemplate.Execute(&buf, map[string]string{
+			"Base":            strings.TrimSuffix(cfg.SwaggerUIURL, "/"),
+			"ScriptIntegrity": cfg.ScriptIntegrity,
+			"StyleIntegrity":  cfg.StyleIntegrity,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("openapi: render swagger page: %v", err))
+		}
+		page = buf.Bytes()
+	}
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(page)
+	}
+}
@@ -0,0 +1,393 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Version adalah versi dokumen API yang dilaporkan di info.version.
+const Version = "1.0.0"
+
+// Document adalah dokumen OpenAPI 3.1 yang dikirim di /openapi.json.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Tags       []Tag                           `json:"tags,omitempty"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info berisi metadata API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag mengelompokkan operasi di Swagger UI/Redoc.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operation menjelaskan satu method pada satu path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter adalah parameter path, query, atau header.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody menjelaskan body request JSON.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response menjelaskan satu kode status response.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header menjelaskan header response.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType membungkus schema untuk satu content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components berisi schema bersama dan skema keamanan.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme menjelaskan cara autentikasi.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Problem adalah body error application/problem+json (RFC 7807) dari apperror.Handler.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+	Code     string `json:"code"`
+}
+
+// Nama skema keamanan di components.securitySchemes.
+const (
+	bearerAuth  = "bearerAuth"  // Token JWT atau token akses pribadi
+	clientBasic = "clientBasic" // Kredensial klien OAuth2
+)
+
+// oauthServerError adalah kode error RFC 6749 untuk kegagalan internal.
+const oauthServerError = "server_error"
+
+// fiberParam mengenali parameter path Fiber (":id") untuk diubah menjadi "{id}".
+var fiberParam = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+// Path mengubah pola rute Fiber menjadi path OpenAPI, mis. /api/v1/users/:id menjadi /api/v1/users/{id}.
+func Path(route string) string {
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return fiberParam.ReplaceAllString(route, "{$1}")
+}
+
+// Key membentuk kunci operasi "METHOD /path" dari method dan pola rute Fiber.
+func Key(method, route string) string {
+	return strings.ToUpper(method) + " " + Path(route)
+}
+
+// Build membentuk dokumen dari rute yang terdaftar di aplikasi. Hanya rute yang punya
+// deskripsi di registry operasi yang masuk dokumen; gunakan Undocumented dan Unrouted
+// untuk mendeteksi rute dan dokumen yang tidak lagi sejalan.
+func Build(routes []fiber.Route) Document {
+	registry := &schemaRegistry{schemas: map[string]*Schema{}, enums: enums}
+
+	doc := Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "Go Fiber User Management API",
+			Version: Version,
+			Description: "Autentikasi JWT dan manajemen pengguna. Respons sukses dibungkus envelope " +
+				"{success, message, data}; error dikirim sebagai application/problem+json dengan kode stabil di field code. " +
+				"Path /api tanpa versi adalah alias usang untuk versi yang dipilih lewat header API-Version (default v1).",
+		},
+		Tags:  tags,
+		Paths: map[string]map[string]Operation{},
+		Components: Components{
+			Schemas: registry.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description: "Token JWT dari POST /api/v1/auth/login, atau token akses pribadi (umt_...) dari " +
+						"POST /api/v1/auth/tokens, dikirim sebagai Authorization: Bearer <token>.",
+				},
+			},
+		},
+	}
+	registry.response(Problem{})
+	registry.response(response.Envelope{})
+
+	for _, key := range routeKeys(routes) {
+		spec, ok := operations[key]
+		if !ok || spec.hidden {
+			continue
+		}
+		method, path, _ := strings.Cut(key, " ")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]Operation{}
+		}
+		doc.Paths[path][strings.ToLower(method)] = spec.build(registry)
+	}
+	return doc
+}
+
+// Undocumented mengembalikan rute yang terdaftar tetapi tidak punya deskripsi operasi.
+func Undocumented(routes []fiber.Route) []string {
+	var missing []string
+	for _, key := range routeKeys(routes) {
+		if _, ok := operations[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// Unrouted mengembalikan operasi yang dideskripsikan tetapi rutenya tidak terdaftar.
+func Unrouted(routes []fiber.Route) []string {
+	registered := map[string]bool{}
+	for _, key := range routeKeys(routes) {
+		registered[key] = true
+	}
+
+	var stale []string
+	for key := range operations {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// Access adalah syarat akses satu operasi seperti yang tertulis di dokumen.
+type Access struct {
+	Auth   bool     // Memerlukan token
+	Admin  bool     // Hanya untuk admin
+	Scopes []string // Scope token yang diperlukan
+	// RoleScopes berarti token harus membawa semua scope untuk role pemiliknya (model.RoleScopes)
+	RoleScopes bool
+}
+
+// AccessOf mengembalikan syarat akses operasi dengan kunci Key(method, route). ok bernilai
+// false untuk rute tanpa deskripsi, rute tersembunyi, dan endpoint OAuth2 yang memakai
+// kredensial klien, sehingga test dapat membandingkannya dengan middleware yang terpasang.
+func AccessOf(key string) (access Access, ok bool) {
+	spec, ok := operations[key]
+	if !ok || spec.hidden || spec.oauth {
+		return Access{}, false
+	}
+	return Access{Auth: spec.auth, Admin: spec.admin, Scopes: spec.scopes, RoleScopes: spec.roleScopes}, true
+}
+
+// routeKeys mengambil kunci operasi unik dari rute Fiber. Rute HEAD yang ditambahkan
+// otomatis untuk setiap GET dilewati.
+func routeKeys(routes []fiber.Route) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		key := Key(route.Method, route.Path)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// build mengubah deskripsi operasi menjadi Operation lengkap dengan schema response dan error.
+func (spec operationSpec) build(registry *schemaRegistry) Operation {
+	op := Operation{
+		OperationID: spec.id,
+		Summary:     spec.summary,
+		Description: spec.description,
+		Tags:        []string{spec.tag},
+		Parameters:  spec.params,
+		Responses:   map[string]Response{},
+	}
+
+	if spec.request != nil {
+		contentType := fiber.MIMEApplicationJSON
+		if spec.oauth {
+			contentType = fiber.MIMEApplicationForm
+		}
+		op.RequestBody = &RequestBody{
+			Required: !spec.optionalBody,
+			Content:  map[string]MediaType{contentType: {Schema: registry.request(spec.request)}},
+		}
+	}
+	if spec.oauth {
+		// Public client cukup mengirim client_id di form sehingga Basic bersifat opsional
+		op.Security = []map[string][]string{{clientBasic: {}}, {}}
+		spec.buildOAuthResponses(op, registry)
+		return op
+	}
+
+	errorCodes := map[int][]string{}
+	for status, codes := range spec.errors {
+		errorCodes[status] = append(errorCodes[status], codes...)
+	}
+	if spec.auth {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		errorCodes[fiber.StatusUnauthorized] = append(errorCodes[fiber.StatusUnauthorized],
+			apperror.CodeTokenMissing, apperror.CodeTokenInvalid, apperror.CodeTokenRevoked, apperror.CodeTokenExpired)
+		errorCodes[fiber.StatusForbidden] = append(errorCodes[fiber.StatusForbidden], apperror.CodeAccountInactive)
+	}
+	scopes := spec.scopes
+	if spec.roleScopes {
+		// Dokumen mencantumkan scope yang dimiliki setiap role; admin juga memerlukan scope users
+		scopes = model.ProfileScopes
+		op.Description = strings.TrimSpace(op.Description + " Token harus membawa semua scope untuk role pemiliknya: " +
+			strings.Join(model.RoleScopes(model.RoleUser), ", ") + ", ditambah " +
+			strings.Join(model.RoleScopes(model.RoleAdmin)[len(model.ProfileScopes):], ", ") + " untuk admin.")
+	}
+	if len(scopes) > 0 {
+		// OpenAPI 3.1 mengizinkan daftar scope (role) untuk skema selain oauth2
+		op.Security = []map[string][]string{{bearerAuth: scopes}}
+		errorCodes[fiber.StatusForbidden] = append(errorCodes[fiber.StatusForbidden], apperror.CodeInsufficientScope)
+	}
+	if spec.admin {
+		errorCodes[fiber.StatusForbidden] = append(errorCodes[fiber.StatusForbidden], apperror.CodeForbidden)
+	}
+	errorCodes[fiber.StatusInternalServerError] = append(errorCodes[fiber.StatusInternalServerError], apperror.CodeInternal)
+
+	// Respons sukses memakai envelope standar dengan schema data sesuai operasi
+	success := &Schema{Ref: "#/components/schemas/Envelope"}
+	if spec.bare {
+		success = registry.response(spec.data)
+	} else if spec.data != nil {
+		success = &Schema{AllOf: []*Schema{
+			success,
+			{Type: "object", Properties: map[string]*Schema{"data": registry.response(spec.data)}},
+		}}
+	}
+	for _, status := range spec.statuses() {
+		// 302 adalah redirect tanpa body, tujuannya di header Location
+		if status == fiber.StatusFound {
+			op.Responses[fmt.Sprint(status)] = Response{
+				Description: http.StatusText(status),
+				Headers: map[string]Header{fiber.HeaderLocation: {
+					Description: "URL tujuan redirect.",
+					Schema:      &Schema{Type: "string", Format: "uri"},
+				}},
+			}
+			continue
+		}
+		resp := Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: success}},
+		}
+		if spec.etag {
+			resp.Headers = map[string]Header{fiber.HeaderETag: {
+				Description: "Versi pengguna untuk dikirim kembali di If-Match.",
+				Schema:      &Schema{Type: "string"},
+			}}
+		}
+		op.Responses[fmt.Sprint(status)] = resp
+	}
+
+	for status, codes := range errorCodes {
+		problem := &Schema{Ref: "#/components/schemas/Problem"}
+		if extension, ok := spec.extensions[status]; ok {
+			problem = &Schema{AllOf: []*Schema{problem, registry.response(extension)}}
+		}
+		op.Responses[fmt.Sprint(status)] = Response{
+			Description: http.StatusText(status) + ". Kode: " + strings.Join(unique(codes), ", ") + ".",
+			Content:     map[string]MediaType{apperror.ContentTypeProblem: {Schema: problem}},
+		}
+	}
+	return op
+}
+
+// buildOAuthResponses mengisi response endpoint RFC 6749: body sukses tanpa envelope dan
+// error berformat OAuthError.
+func (spec operationSpec) buildOAuthResponses(op Operation, registry *schemaRegistry) {
+	for _, status := range spec.statuses() {
+		resp := Response{Description: http.StatusText(status)}
+		if spec.data != nil {
+			resp.Content = map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: registry.response(spec.data)}}
+		}
+		op.Responses[fmt.Sprint(status)] = resp
+	}
+
+	oauthError := registry.response(model.OAuthError{})
+	errorCodes := merge(spec.errors, map[int][]string{fiber.StatusInternalServerError: {oauthServerError}})
+	for status, codes := range errorCodes {
+		op.Responses[fmt.Sprint(status)] = Response{
+			Description: http.StatusText(status) + ". Kode: " + strings.Join(unique(codes), ", ") + ".",
+			Content:     map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: oauthError}},
+		}
+	}
+}
+
+func (spec operationSpec) statuses() []int {
+	if len(spec.success) == 0 {
+		return []int{fiber.StatusOK}
+	}
+	return spec.success
+}
+
+// unique membuang kode yang sama tanpa mengubah urutan kemunculannya.
+func unique(codes []string) []string {
+	seen := map[string]bool{}
+	result := codes[:0:0]
+	for _, code := range codes {
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	return result
+}
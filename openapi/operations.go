@@ -0,0 +1,747 @@
+package openapi
+
+import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/health"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/oidc"
+	"go-fiber-user-management/webauthn"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// operationSpec mendeskripsikan satu rute. Schema request dan data response dibentuk dari
+// nilai contoh request dan data lewat refleksi, sehingga perubahan DTO langsung ikut ke dokumen.
+type operationSpec struct {
+	id          string
+	summary     string
+	description string
+	tag         string
+	params      []Parameter
+
+	request      interface{} // Tipe body request; nil jika tanpa body
+	optionalBody bool
+	data         interface{} // Tipe field data di envelope sukses; nil jika tanpa data
+	success      []int       // Kode status sukses (default 200)
+	etag         bool        // Response sukses membawa header ETag
+	bare         bool        // Response sukses berisi data langsung, tanpa envelope (format standar eksternal)
+
+	auth bool // Memerlukan token JWT
+	// scopes adalah scope token yang diperlukan (lihat middleware.RequireScope)
+	scopes []string
+	// roleScopes menandai operasi yang memerlukan semua scope untuk role pemilik token
+	// (lihat middleware.RequireRoleScopes)
+	roleScopes bool
+	// oauth menandai endpoint RFC 6749: body form, response tanpa envelope, error berformat
+	// OAuthError, dan autentikasi klien lewat HTTP Basic atau field form
+	oauth  bool
+	admin  bool // Hanya untuk admin
+	errors map[int][]string
+	// extensions adalah member tambahan problem+json per kode status
+	extensions map[int]interface{}
+
+	hidden bool // Rute terdaftar tetapi sengaja tidak dimasukkan ke dokumen
+}
+
+// LivenessData adalah data response /healthz.
+type LivenessData struct {
+	Status string `json:"status"`
+}
+
+// ReadinessData adalah data response /readyz: status per komponen.
+type ReadinessData struct {
+	Status     string            `json:"status"`
+	Components map[string]string `json:"components"`
+}
+
+// TokenData adalah data response login.
+type TokenData struct {
+	Token string `json:"token"`
+	// Scope adalah scope API token (dipisahkan spasi); hanya dikirim oleh login password dan exchange
+	Scope string `json:"scope,omitempty"`
+}
+
+// BatchRolledBack adalah member tambahan problem+json saat batch atomic dibatalkan.
+type BatchRolledBack struct {
+	Results []model.UserBatchResult `json:"results"`
+}
+
+// SecondFactorRequired adalah member tambahan problem+json saat login password perlu
+// dikonfirmasi dengan passkey.
+type SecondFactorRequired struct {
+	// MFAToken ditukar di POST /api/v1/auth/webauthn/login/begin
+	MFAToken string `json:"mfa_token"`
+}
+
+// NotReady adalah member tambahan problem+json saat layanan belum siap.
+type NotReady struct {
+	Components map[string]string `json:"components"`
+}
+
+// auditActions adalah aksi yang dicatat di audit trail.
+var auditActions = []string{model.AuditImpersonationStart, model.AuditImpersonationRequest, model.AuditImpersonationEnd}
+
+var tags = []Tag{
+	{Name: "health", Description: "Probe liveness/readiness dan laporan kesehatan."},
+	{Name: "auth", Description: "Pendaftaran, login, profil, dan logout."},
+	{Name: "identities", Description: "Login lewat IdP eksternal (Google, GitHub, IdP OpenID Connect) dan penautan identitas."},
+	{Name: "passkeys", Description: "Passkey WebAuthn untuk login tanpa password, faktor kedua, dan pengelolaannya."},
+	{Name: "users", Description: "Manajemen pengguna oleh admin."},
+	{Name: "audit", Description: "Audit trail impersonation pengguna oleh admin."},
+	{Name: "oauth", Description: "Server otorisasi OAuth2: token, introspection, revocation, dan pendaftaran klien."},
+	{Name: "oidc", Description: "OpenID Connect: discovery, kunci publik ID token, dan userinfo."},
+}
+
+// enums membatasi nilai field DTO, dengan kunci "NamaTipe.nama_json".
+var enums = map[string][]string{
+	"UserResponseDTO.role":                    {model.RoleUser, model.RoleAdmin},
+	"UserResponseDTO.status":                  {model.StatusPending, model.StatusActive, model.StatusSuspended, model.StatusLocked, model.StatusDisabled},
+	"UserBatchRequest.mode":                   {model.BatchModeAtomic, model.BatchModeBestEffort},
+	"UserBatchOperation.op":                   {model.BatchOpSuspend, model.BatchOpReactivate, model.BatchOpDisable, model.BatchOpDelete, model.BatchOpSetRole},
+	"UserBatchOperation.role":                 {model.RoleUser, model.RoleAdmin},
+	"Report.status":                           {health.StatusUp, health.StatusDown},
+	"ComponentReport.status":                  {health.StatusUp, health.StatusDown},
+	"LivenessData.status":                     {health.StatusUp},
+	"OAuthTokenRequest.grant_type":            {model.GrantAuthorizationCode, model.GrantClientCredentials},
+	"OAuthClientRequestDTO.grant_types":       {model.GrantAuthorizationCode, model.GrantClientCredentials},
+	"OAuthClientResponseDTO.grant_types":      {model.GrantAuthorizationCode, model.GrantClientCredentials},
+	"OAuthTokenActionRequest.token_type_hint": {"access_token"},
+	"ReadinessData.status":                    {health.StatusUp},
+	"PasswordlessRequestDTO.method":           {model.LoginMethodLink, model.LoginMethodCode},
+	"PasswordlessChallengeDTO.method":         {model.LoginMethodLink, model.LoginMethodCode},
+	"APITokenRequestDTO.scopes":               model.APIScopes,
+	"APITokenResponseDTO.scopes":              model.APIScopes,
+	"AuditEventResponseDTO.action":            auditActions,
+}
+
+var (
+	userIDParam = Parameter{
+		Name: "id", In: "path", Required: true,
+		Description: "ID pengguna.",
+		Schema:      &Schema{Type: "integer"},
+	}
+	ifMatchHeader = Parameter{
+		Name: fiber.HeaderIfMatch, In: "header",
+		Description: "ETag dari GET sebelumnya untuk optimistic locking. Wajib jika server dijalankan dengan REQUIRE_IF_MATCH.",
+		Schema:      &Schema{Type: "string"},
+	}
+	providerParam = Parameter{
+		Name: "provider", In: "path", Required: true,
+		Description: "Nama IdP eksternal sesuai konfigurasi identity.providers, mis. google.",
+		Schema:      &Schema{Type: "string"},
+	}
+	returnToQuery = Parameter{
+		Name: "return_to", In: "query",
+		Description: "URL frontend dari identity.return_urls. Jika diisi, callback mengalihkan ke URL ini dengan hasil di fragment.",
+		Schema:      &Schema{Type: "string", Format: "uri"},
+	}
+	apiTokenIDParam = Parameter{
+		Name: "id", In: "path", Required: true,
+		Description: "ID token akses pribadi dari daftar token.",
+		Schema:      &Schema{Type: "integer"},
+	}
+	passkeyIDParam = Parameter{
+		Name: "id", In: "path", Required: true,
+		Description: "ID passkey dari daftar passkey.",
+		Schema:      &Schema{Type: "integer"},
+	}
+	statusQuery = Parameter{
+		Name: "status", In: "query",
+		Description: "Filter status dipisahkan koma, mis. active,suspended.",
+		Schema:      &Schema{Type: "string"},
+	}
+)
+
+// Kode error yang dipakai bersama oleh beberapa operasi pengguna.
+var (
+	preconditionErrors = map[int][]string{
+		fiber.StatusConflict:             {apperror.CodeVersionConflict},
+		fiber.StatusPreconditionRequired: {apperror.CodePreconditionRequired},
+	}
+	userLookupErrors = map[int][]string{
+		fiber.StatusBadRequest: {apperror.CodeValidationFailed},
+		fiber.StatusNotFound:   {apperror.CodeUserNotFound},
+	}
+	passwordlessVerifyErrors = map[int][]string{
+		fiber.StatusBadRequest:      {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+		fiber.StatusUnauthorized:    {apperror.CodeInvalidChallenge, apperror.CodeChallengeExpired, apperror.CodeInvalidCode},
+		fiber.StatusForbidden:       {apperror.CodeDeviceMismatch, apperror.CodeAccountInactive},
+		fiber.StatusNotFound:        {apperror.CodeUserNotFound},
+		fiber.StatusTooManyRequests: {apperror.CodeTooManyAttempts},
+	}
+	// sessionOnlyErrors adalah error operasi yang menolak token akses pribadi
+	sessionOnlyErrors = map[int][]string{
+		fiber.StatusForbidden: {apperror.CodeForbidden, apperror.CodeImpersonationNotAllowed},
+	}
+	notImpersonatedErrors = map[int][]string{
+		fiber.StatusForbidden: {apperror.CodeImpersonationNotAllowed},
+	}
+	passkeyWriteErrors = map[int][]string{
+		fiber.StatusBadRequest: {apperror.CodeValidationFailed},
+		fiber.StatusNotFound:   {apperror.CodePasskeyNotFound, apperror.CodeUserNotFound},
+	}
+)
+
+// merge menggabungkan beberapa peta kode error menjadi satu.
+func merge(maps ...map[int][]string) map[int][]string {
+	merged := map[int][]string{}
+	for _, m := range maps {
+		for status, codes := range m {
+			merged[status] = append(merged[status], codes...)
+		}
+	}
+	return merged
+}
+
+// operations adalah deskripsi setiap rute yang didaftarkan router, dengan kunci
+// Key(method, route). Rute baru tanpa entri di sini membuat test drift gagal.
+var operations = map[string]operationSpec{
+	"GET /":             {hidden: true},
+	"GET /metrics":      {hidden: true}, // Format teks Prometheus, bukan JSON
+	"GET /openapi.json": {hidden: true},
+	"GET /docs":         {hidden: true},
+	// Halaman consent HTML untuk browser, bukan API
+	"GET /oauth/authorize":  {hidden: true},
+	"POST /oauth/authorize": {hidden: true},
+
+	"GET /healthz": {
+		id: "liveness", tag: "health",
+		summary:     "Liveness probe",
+		description: "Menandakan proses masih berjalan tanpa memeriksa dependensi.",
+		data:        LivenessData{},
+	},
+	"GET /readyz": {
+		id: "readiness", tag: "health",
+		summary:     "Readiness probe",
+		description: "Memeriksa database, migrasi, dan kunci JWT. Mengembalikan 503 jika ada komponen yang gagal.",
+		data:        ReadinessData{},
+		errors:      map[int][]string{fiber.StatusServiceUnavailable: {apperror.CodeNotReady}},
+		extensions:  map[int]interface{}{fiber.StatusServiceUnavailable: NotReady{}},
+	},
+	"GET /api/v1/health": {
+		id: "healthReport", tag: "health",
+		summary:     "Laporan kesehatan lengkap",
+		description: "Latensi dan error setiap komponen. Selalu 200; status layanan ada di data.status.",
+		data:        health.Report{},
+		auth:        true, admin: true,
+	},
+
+	"POST /api/v1/auth/login": {
+		id: "login", tag: "auth",
+		summary: "Login dan dapatkan token JWT",
+		description: "Jika webauthn.require_for_password_login aktif dan akun punya passkey, respons 401 " +
+			"second_factor_required membawa mfa_token untuk login dengan passkey. " +
+			"scope membatasi token ke sebagian scope API, mis. \"users:read\" untuk token hanya-baca.",
+		request: model.AuthenticationRequest{},
+		data:    TokenData{},
+		errors: map[int][]string{
+			fiber.StatusBadRequest:   {apperror.CodeInvalidRequest, apperror.CodeValidationFailed, apperror.CodeInvalidScope},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidCredentials, apperror.CodeSecondFactorRequired},
+			fiber.StatusForbidden:    {apperror.CodeAccountInactive},
+			fiber.StatusNotFound:     {apperror.CodeUserNotFound},
+		},
+		extensions: map[int]interface{}{fiber.StatusUnauthorized: SecondFactorRequired{}},
+	},
+	"POST /api/v1/auth/exchange": {
+		id: "exchangeToken", tag: "auth",
+		summary: "Tukar token dengan token ber-scope lebih sempit",
+		description: "Token baru membawa scope yang diminta, yang harus bagian dari scope token yang dipakai, " +
+			"dan berakhir bersamaan dengan token tersebut. Tidak bisa dipanggil dengan token akses pribadi.",
+		request: model.TokenExchangeRequest{},
+		data:    TokenData{},
+		auth:    true,
+		errors: merge(sessionOnlyErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed, apperror.CodeInvalidScope},
+		}),
+	},
+	"POST /api/v1/auth/register": {
+		id: "register", tag: "auth",
+		summary: "Daftarkan pengguna baru",
+		request: model.UserRequestDTO{},
+		data:    model.UserResponseDTO{},
+		success: []int{fiber.StatusCreated},
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+			fiber.StatusConflict:   {apperror.CodeEmailExists},
+		},
+	},
+	"GET /api/v1/auth/profile": {
+		id: "getProfile", tag: "auth",
+		summary:     "Profil pengguna yang sedang login",
+		description: "Dengan token impersonation, act berisi admin yang sedang bertindak sebagai pengguna ini.",
+		data:        model.UserResponseDTO{},
+		etag:        true,
+		auth:        true,
+		scopes:      []string{model.ScopeProfileRead},
+		errors:      map[int][]string{fiber.StatusNotFound: {apperror.CodeUserNotFound}},
+	},
+	"POST /api/v1/auth/impersonation/end": {
+		id: "endImpersonation", tag: "auth",
+		summary:     "Akhiri impersonation",
+		description: "Dipanggil dengan token impersonation; token dibatalkan dan akhir sesi dicatat di audit trail.",
+		auth:        true,
+		errors:      map[int][]string{fiber.StatusBadRequest: {apperror.CodeNotImpersonating}},
+	},
+	"GET /api/v1/auth/logout": {
+		id: "logout", tag: "auth",
+		summary:     "Logout",
+		description: "Membatalkan token yang dipakai sehingga tidak bisa digunakan lagi.",
+		auth:        true,
+	},
+
+	"POST /api/v1/auth/passwordless": {
+		id: "requestPasswordlessLogin", tag: "auth",
+		summary: "Minta magic link atau kode login lewat email",
+		description: "Mengirim magic link (method link) atau kode 6 digit (method code) ke email yang terdaftar. " +
+			"Respons selalu 202 dengan challenge_id dan device_secret, juga untuk email yang tidak terdaftar. " +
+			"device_secret juga diset sebagai cookie login_device dan harus ikut saat verifikasi. " +
+			"Permintaan ulang dengan device_secret yang sama menggantikan challenge perangkat itu saja. " +
+			"Permintaan dibatasi passwordless.ip_limit per alamat IP dan satu per passwordless.cooldown per IP dan email.",
+		request: model.PasswordlessRequestDTO{},
+		data:    model.PasswordlessChallengeDTO{},
+		success: []int{fiber.StatusAccepted},
+		errors: map[int][]string{
+			fiber.StatusBadRequest:      {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+			fiber.StatusTooManyRequests: {apperror.CodeRateLimited},
+		},
+	},
+	"POST /api/v1/auth/passwordless/verify": {
+		id: "verifyPasswordlessLogin", tag: "auth",
+		summary: "Tukar kode atau token magic link dengan token JWT",
+		description: "secret berisi kode dari email atau token dari magic link. Challenge hanya berlaku sekali dan " +
+			"ditolak setelah passwordless.max_attempts percobaan. Percobaan dari perangkat lain ditolak " +
+			"dengan device_mismatch tanpa mengurangi jatah.",
+		request: model.PasswordlessVerifyDTO{},
+		data:    TokenData{},
+		errors:  passwordlessVerifyErrors,
+	},
+	"GET /api/v1/auth/passwordless/verify": {
+		id: "verifyPasswordlessLink", tag: "auth",
+		summary:     "Tujuan magic link",
+		description: "Dibuka dari magic link di browser yang meminta login; perangkat dikenali dari cookie login_device.",
+		params: []Parameter{
+			{Name: "challenge_id", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+			{Name: "token", In: "query", Required: true, Description: "Token dari magic link.", Schema: &Schema{Type: "string"}},
+		},
+		data:   TokenData{},
+		errors: passwordlessVerifyErrors,
+	},
+	"POST /api/v1/auth/tokens": {
+		id: "createAPIToken", tag: "auth",
+		summary: "Buat token akses pribadi",
+		description: "Token berumur panjang untuk otomasi (CI, skrip), dikirim sebagai Authorization: Bearer <token> " +
+			"seperti JWT. Nilai token hanya ada di respons ini. Tidak bisa dipanggil dengan token akses pribadi.",
+		request:    model.APITokenRequestDTO{},
+		data:       model.APITokenCreatedDTO{},
+		success:    []int{fiber.StatusCreated},
+		auth:       true,
+		roleScopes: true,
+		errors: merge(sessionOnlyErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed, apperror.CodeInvalidScope},
+			fiber.StatusNotFound:   {apperror.CodeUserNotFound},
+			fiber.StatusConflict:   {apperror.CodeAPITokenLimit},
+		}),
+	},
+	"GET /api/v1/auth/tokens": {
+		id: "listAPITokens", tag: "auth",
+		summary:    "Token akses pribadi milik pengguna",
+		data:       []model.APITokenResponseDTO{},
+		auth:       true,
+		roleScopes: true,
+		errors:     merge(sessionOnlyErrors, map[int][]string{fiber.StatusNotFound: {apperror.CodeUserNotFound}}),
+	},
+	"DELETE /api/v1/auth/tokens/{id}": {
+		id: "revokeAPIToken", tag: "auth",
+		summary:    "Cabut token akses pribadi",
+		params:     []Parameter{apiTokenIDParam},
+		auth:       true,
+		roleScopes: true,
+		errors: merge(sessionOnlyErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeValidationFailed},
+			fiber.StatusNotFound:   {apperror.CodeAPITokenNotFound, apperror.CodeUserNotFound},
+		}),
+	},
+	"POST /api/v1/auth/webauthn/register/begin": {
+		id: "beginPasskeyRegistration", tag: "passkeys",
+		summary:     "Mulai registrasi passkey",
+		description: "Mengembalikan opsi untuk navigator.credentials.create (parseCreationOptionsFromJSON).",
+		data:        webauthn.CreationOptions{},
+		auth:        true,
+		roleScopes:  true,
+		errors:      merge(sessionOnlyErrors, map[int][]string{fiber.StatusNotFound: {apperror.CodeUserNotFound}}),
+	},
+	"POST /api/v1/auth/webauthn/register/finish": {
+		id: "finishPasskeyRegistration", tag: "passkeys",
+		summary:     "Simpan passkey baru",
+		description: "credential adalah hasil PublicKeyCredential.toJSON() dari navigator.credentials.create.",
+		request:     model.PasskeyRegisterRequest{},
+		data:        model.PasskeyResponseDTO{},
+		success:     []int{fiber.StatusCreated},
+		auth:        true,
+		roleScopes:  true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed, apperror.CodeInvalidCeremony,
+				apperror.CodeChallengeExpired, apperror.CodePasskeyVerificationFailed},
+			fiber.StatusForbidden: {apperror.CodeForbidden, apperror.CodeImpersonationNotAllowed},
+			fiber.StatusNotFound:  {apperror.CodeUserNotFound},
+			fiber.StatusConflict:  {apperror.CodePasskeyExists},
+		},
+	},
+	"POST /api/v1/auth/webauthn/login/begin": {
+		id: "beginPasskeyLogin", tag: "passkeys",
+		summary: "Mulai login dengan passkey",
+		description: "Tanpa body untuk login tanpa password (verifikasi pengguna wajib), atau dengan mfa_token dari " +
+			"login password untuk konfirmasi faktor kedua. Mengembalikan opsi untuk navigator.credentials.get.",
+		request:      model.PasskeyLoginBeginRequest{},
+		optionalBody: true,
+		data:         webauthn.RequestOptions{},
+		errors: map[int][]string{
+			fiber.StatusBadRequest:   {apperror.CodeInvalidRequest},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidChallenge},
+		},
+	},
+	"POST /api/v1/auth/webauthn/login/finish": {
+		id: "finishPasskeyLogin", tag: "passkeys",
+		summary:     "Tukar assertion passkey dengan token JWT",
+		description: "credential adalah hasil PublicKeyCredential.toJSON() dari navigator.credentials.get.",
+		request:     model.PasskeyLoginRequest{},
+		data:        TokenData{},
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidCeremony, apperror.CodeChallengeExpired,
+				apperror.CodePasskeyVerificationFailed},
+			fiber.StatusForbidden: {apperror.CodeAccountInactive},
+			fiber.StatusNotFound:  {apperror.CodeUserNotFound},
+		},
+	},
+	"GET /api/v1/auth/webauthn/credentials": {
+		id: "listPasskeys", tag: "passkeys",
+		summary: "Passkey milik pengguna yang sedang login",
+		data:    []model.PasskeyResponseDTO{},
+		auth:    true,
+		scopes:  []string{model.ScopeProfileRead},
+		errors:  map[int][]string{fiber.StatusNotFound: {apperror.CodeUserNotFound}},
+	},
+	"PATCH /api/v1/auth/webauthn/credentials/{id}": {
+		id: "renamePasskey", tag: "passkeys",
+		summary: "Ganti nama passkey",
+		params:  []Parameter{passkeyIDParam},
+		request: model.PasskeyRenameRequest{},
+		auth:    true,
+		scopes:  []string{model.ScopeProfileWrite},
+		errors: merge(passkeyWriteErrors, notImpersonatedErrors,
+			map[int][]string{fiber.StatusBadRequest: {apperror.CodeInvalidRequest}}),
+	},
+	"DELETE /api/v1/auth/webauthn/credentials/{id}": {
+		id: "deletePasskey", tag: "passkeys",
+		summary: "Hapus passkey",
+		params:  []Parameter{passkeyIDParam},
+		auth:    true,
+		scopes:  []string{model.ScopeProfileWrite},
+		errors:  merge(passkeyWriteErrors, notImpersonatedErrors),
+	},
+	"GET /api/v1/auth/oauth/providers": {
+		id: "listIdentityProviders", tag: "identities",
+		summary: "Daftar IdP eksternal untuk login",
+		data:    []model.IdentityProviderDTO{},
+	},
+	"GET /api/v1/auth/oauth/{provider}/start": {
+		id: "startIdentityLogin", tag: "identities",
+		summary: "Mulai login lewat IdP eksternal",
+		description: "Mengalihkan browser ke IdP dengan state, nonce, dan PKCE, serta menyimpan state di cookie " +
+			"identity_state yang diperiksa oleh callback.",
+		params:  []Parameter{providerParam, returnToQuery},
+		success: []int{fiber.StatusFound},
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeValidationFailed},
+			fiber.StatusNotFound:   {apperror.CodeProviderNotFound},
+			fiber.StatusBadGateway: {apperror.CodeIdentityProviderError},
+		},
+	},
+	"GET /api/v1/auth/oauth/{provider}/callback": {
+		id: "identityCallback", tag: "identities",
+		summary: "Callback IdP eksternal",
+		description: "Menukar kode di IdP lalu login dengan identitas yang tertaut, menautkan ke akun dengan email terverifikasi " +
+			"yang sama (link_by_email), atau membuat akun baru (auto_provision). Untuk alur penautan, token tidak diterbitkan. " +
+			"Jika start memakai return_to, hasil atau error dikirim lewat redirect 302 ke URL tersebut di fragment.",
+		params: []Parameter{
+			providerParam,
+			{Name: "code", In: "query", Description: "Authorization code dari IdP.", Schema: &Schema{Type: "string"}},
+			{Name: "state", In: "query", Required: true, Description: "State dari start.", Schema: &Schema{Type: "string"}},
+		},
+		data:    model.IdentityCallbackResponse{},
+		success: []int{fiber.StatusOK, fiber.StatusFound},
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeInvalidState},
+			fiber.StatusForbidden:  {apperror.CodeAccessDenied, apperror.CodeProvisioningDisabled, apperror.CodeAccountInactive},
+			fiber.StatusNotFound:   {apperror.CodeProviderNotFound, apperror.CodeUserNotFound},
+			fiber.StatusConflict:   {apperror.CodeEmailExists, apperror.CodeIdentityLinked},
+			fiber.StatusBadGateway: {apperror.CodeIdentityProviderError},
+		},
+	},
+	"POST /api/v1/auth/oauth/{provider}/link": {
+		id: "linkIdentity", tag: "identities",
+		summary:      "Tautkan IdP eksternal ke akun",
+		description:  "Mengembalikan authorization_url yang harus dibuka browser; callback menautkan identitas ke akun pemilik token.",
+		params:       []Parameter{providerParam},
+		request:      model.IdentityLinkRequest{},
+		optionalBody: true,
+		data:         model.IdentityLinkResponse{},
+		auth:         true,
+		roleScopes:   true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+			fiber.StatusForbidden:  {apperror.CodeForbidden, apperror.CodeImpersonationNotAllowed},
+			fiber.StatusNotFound:   {apperror.CodeProviderNotFound, apperror.CodeUserNotFound},
+			fiber.StatusBadGateway: {apperror.CodeIdentityProviderError},
+		},
+	},
+	"GET /api/v1/auth/identities": {
+		id: "listIdentities", tag: "identities",
+		summary: "Identitas eksternal yang tertaut ke akun",
+		data:    []model.IdentityResponseDTO{},
+		auth:    true,
+		scopes:  []string{model.ScopeProfileRead},
+		errors:  map[int][]string{fiber.StatusNotFound: {apperror.CodeUserNotFound}},
+	},
+	"DELETE /api/v1/auth/identities/{provider}": {
+		id: "unlinkIdentity", tag: "identities",
+		summary:     "Lepas identitas eksternal dari akun",
+		description: "Identitas terakhir milik akun tanpa password tidak bisa dilepas.",
+		params:      []Parameter{providerParam},
+		auth:        true,
+		scopes:      []string{model.ScopeProfileWrite},
+		errors: merge(notImpersonatedErrors, map[int][]string{
+			fiber.StatusNotFound: {apperror.CodeIdentityNotFound, apperror.CodeUserNotFound},
+			fiber.StatusConflict: {apperror.CodeLastLoginMethod},
+		}),
+	},
+
+	"GET /api/v1/users": {
+		id: "listUsers", tag: "users",
+		summary: "Daftar pengguna",
+		params:  []Parameter{statusQuery},
+		data:    []model.UserResponseDTO{},
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersRead},
+		errors: map[int][]string{fiber.StatusBadRequest: {apperror.CodeValidationFailed}},
+	},
+	"POST /api/v1/users": {
+		id: "createUser", tag: "users",
+		summary: "Buat pengguna",
+		request: model.UserRequestDTO{},
+		data:    model.UserResponseDTO{},
+		success: []int{fiber.StatusCreated},
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+			fiber.StatusConflict:   {apperror.CodeEmailExists},
+		},
+	},
+	"POST /api/v1/users/batch": {
+		id: "batchUsers", tag: "users",
+		summary: "Operasi massal pengguna",
+		description: "Mode atomic (default) menjalankan semua operasi dalam satu transaksi dan membalas 422 jika ada yang gagal; " +
+			"mode best_effort menjalankan setiap operasi terpisah dan membalas 207 Multi-Status.",
+		request: model.UserBatchRequest{},
+		data:    []model.UserBatchResult{},
+		success: []int{fiber.StatusOK, fiber.StatusMultiStatus},
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: map[int][]string{
+			fiber.StatusBadRequest:          {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+			fiber.StatusUnprocessableEntity: {apperror.CodeBatchRolledBack},
+		},
+		extensions: map[int]interface{}{fiber.StatusUnprocessableEntity: BatchRolledBack{}},
+	},
+	"GET /api/v1/users/{id}": {
+		id: "getUser", tag: "users",
+		summary: "Detail pengguna",
+		params:  []Parameter{userIDParam},
+		data:    model.UserResponseDTO{},
+		etag:    true,
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersRead},
+		errors: userLookupErrors,
+	},
+	"PUT /api/v1/users/{id}": {
+		id: "updateUser", tag: "users",
+		summary: "Perbarui pengguna",
+		params:  []Parameter{userIDParam, ifMatchHeader},
+		request: model.UserRequestDTO{},
+		data:    model.UserResponseDTO{},
+		etag:    true,
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: merge(userLookupErrors, preconditionErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest},
+			fiber.StatusConflict:   {apperror.CodeEmailExists},
+		}),
+	},
+	"PATCH /api/v1/users/{id}": {
+		id: "patchUser", tag: "users",
+		summary:     "Perbarui sebagian data pengguna",
+		description: "Hanya field yang dikirim yang diubah.",
+		params:      []Parameter{userIDParam, ifMatchHeader},
+		request:     model.UserPatchDTO{},
+		data:        model.UserResponseDTO{},
+		etag:        true,
+		auth:        true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: merge(userLookupErrors, preconditionErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest},
+			fiber.StatusConflict:   {apperror.CodeEmailExists},
+		}),
+	},
+	"POST /api/v1/users/{id}/impersonate": {
+		id: "impersonateUser", tag: "users",
+		summary: "Masuk sebagai pengguna untuk dukungan",
+		description: "Hanya admin di impersonation.admins. Token berlaku singkat (impersonation.ttl), membawa klaim act " +
+			"berisi admin, tidak bisa mengubah kredensial pengguna, dan setiap request-nya dicatat di audit trail.",
+		params:  []Parameter{userIDParam},
+		request: model.ImpersonationRequestDTO{},
+		data:    model.ImpersonationResponseDTO{},
+		success: []int{fiber.StatusCreated},
+		auth:    true, admin: true,
+		roleScopes: true,
+		errors: merge(userLookupErrors, sessionOnlyErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest},
+		}),
+	},
+	"GET /api/v1/audit-events": {
+		id: "listAuditEvents", tag: "audit",
+		summary: "Audit trail impersonation, terbaru lebih dulu",
+		params: []Parameter{
+			{Name: "action", In: "query", Schema: &Schema{Type: "string", Enum: auditActions}},
+			{Name: "actor_id", In: "query", Description: "ID admin.", Schema: &Schema{Type: "integer"}},
+			{Name: "subject_id", In: "query", Description: "ID pengguna yang di-impersonate.", Schema: &Schema{Type: "integer"}},
+			{Name: "session_id", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "limit", In: "query", Description: "Jumlah catatan, default 100, maks 500.", Schema: &Schema{Type: "integer"}},
+		},
+		data: []model.AuditEventResponseDTO{},
+		auth: true, admin: true,
+		scopes: []string{model.ScopeUsersRead},
+		errors: map[int][]string{fiber.StatusBadRequest: {apperror.CodeValidationFailed}},
+	},
+	"DELETE /api/v1/users/{id}": {
+		id: "deleteUser", tag: "users",
+		summary: "Hapus pengguna",
+		params:  []Parameter{userIDParam, ifMatchHeader},
+		data:    model.UserResponseDTO{},
+		auth:    true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: merge(userLookupErrors, preconditionErrors),
+	},
+	"POST /oauth/token": {
+		id: "oauthToken", tag: "oauth",
+		summary: "Tukar authorization code atau client credentials dengan token akses",
+		description: "authorization_code wajib membawa code_verifier PKCE (S256); client_credentials hanya untuk klien confidential. " +
+			"Token yang diterbitkan adalah JWT yang sama dengan login dan membawa klaim client_id dan scope. " +
+			"Pada authorization_code, scope boleh dikirim untuk mempersempit scope yang disetujui pengguna.",
+		request: model.OAuthTokenRequest{},
+		data:    model.OAuthTokenResponse{},
+		oauth:   true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeInvalidGrant, apperror.CodeInvalidScope,
+				apperror.CodeUnauthorizedClient, apperror.CodeUnsupportedGrantType},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidClient},
+		},
+	},
+	"POST /oauth/introspect": {
+		id: "oauthIntrospect", tag: "oauth",
+		summary:     "Introspection token (RFC 7662)",
+		description: "Hanya untuk klien confidential. Token yang tidak valid, dibatalkan, atau kedaluwarsa dilaporkan sebagai active=false.",
+		request:     model.OAuthTokenActionRequest{},
+		data:        model.OAuthIntrospection{},
+		oauth:       true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest:   {apperror.CodeInvalidRequest, apperror.CodeUnauthorizedClient},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidClient},
+		},
+	},
+	"POST /oauth/revoke": {
+		id: "oauthRevoke", tag: "oauth",
+		summary:     "Batalkan token (RFC 7009)",
+		description: "Selalu 200 untuk token yang tidak dikenal; token milik klien lain ditolak.",
+		request:     model.OAuthTokenActionRequest{},
+		oauth:       true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest:   {apperror.CodeInvalidRequest, apperror.CodeUnauthorizedClient},
+			fiber.StatusUnauthorized: {apperror.CodeInvalidClient},
+		},
+	},
+	"GET /.well-known/openid-configuration": {
+		id: "oidcDiscovery", tag: "oidc",
+		summary: "OpenID Provider Metadata",
+		data:    oidc.Discovery{},
+		bare:    true,
+	},
+	"GET /.well-known/jwks.json": {
+		id: "oidcJWKS", tag: "oidc",
+		summary:     "Kunci publik ID token",
+		description: "JSON Web Key Set untuk memverifikasi tanda tangan RS256 ID token.",
+		data:        oidc.JWKSet{},
+		bare:        true,
+	},
+	"GET /userinfo":  userInfoOperation("userInfo"),
+	"POST /userinfo": userInfoOperation("userInfoPost"),
+	"GET /api/v1/oauth/clients": {
+		id: "listOAuthClients", tag: "oauth",
+		summary: "Daftar klien OAuth2",
+		data:    []model.OAuthClientResponseDTO{},
+		auth:    true, admin: true,
+	},
+	"POST /api/v1/oauth/clients": {
+		id: "registerOAuthClient", tag: "oauth",
+		summary:     "Daftarkan klien OAuth2",
+		description: "client_secret hanya dikirim sekali di response ini, dan hanya untuk klien confidential.",
+		request:     model.OAuthClientRequestDTO{},
+		data:        model.OAuthClientResponseDTO{},
+		success:     []int{fiber.StatusCreated},
+		auth:        true, admin: true,
+		errors: map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest, apperror.CodeValidationFailed},
+		},
+	},
+
+	"POST /api/v1/users/{id}/suspend":    statusOperation("suspendUser", "Tangguhkan akun pengguna"),
+	"POST /api/v1/users/{id}/lock":       statusOperation("lockUser", "Kunci akun pengguna karena alasan keamanan"),
+	"POST /api/v1/users/{id}/reactivate": statusOperation("reactivateUser", "Aktifkan kembali akun pengguna"),
+	"POST /api/v1/users/{id}/disable":    statusOperation("disableUser", "Nonaktifkan akun pengguna"),
+}
+
+// userInfoOperation mendeskripsikan endpoint userinfo, yang menerima GET maupun POST.
+func userInfoOperation(id string) operationSpec {
+	return operationSpec{
+		id: id, tag: "oidc",
+		summary:     "Claim pengguna pemilik token",
+		description: "Token harus diterbitkan lewat /oauth/token dengan scope openid. Claim disaring sesuai scope profile, email, phone, dan address.",
+		data:        oidc.UserInfo{},
+		bare:        true,
+		auth:        true,
+		errors: map[int][]string{
+			fiber.StatusForbidden: {apperror.CodeInsufficientScope},
+			fiber.StatusNotFound:  {apperror.CodeUserNotFound},
+		},
+	}
+}
+
+// statusOperation mendeskripsikan endpoint perubahan status akun; body berisi alasan opsional.
+func statusOperation(id, summary string) operationSpec {
+	return operationSpec{
+		id: id, tag: "users",
+		summary:      summary,
+		params:       []Parameter{userIDParam},
+		request:      model.UserStatusRequest{},
+		optionalBody: true,
+		data:         model.UserResponseDTO{},
+		etag:         true,
+		auth:         true, admin: true,
+		scopes: []string{model.ScopeUsersWrite},
+		errors: merge(userLookupErrors, map[int][]string{
+			fiber.StatusBadRequest: {apperror.CodeInvalidRequest},
+			fiber.StatusConflict:   {apperror.CodeInvalidStatusTransition},
+		}),
+	}
+}
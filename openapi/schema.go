@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema adalah JSON Schema (dialek OpenAPI 3.1) untuk body request dan response.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRegistry membentuk schema dari tipe Go lewat refleksi. Setiap struct bernama
+// disimpan sekali di components.schemas dan dirujuk dengan $ref.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	// enums membatasi nilai field tertentu, dengan kunci "NamaTipe.nama_json"
+	enums map[string][]string
+}
+
+// request membentuk schema untuk body request: field wajib ditentukan oleh tag validate:"required".
+func (r *schemaRegistry) request(v interface{}) *Schema {
+	return r.schemaFor(reflect.TypeOf(v), true)
+}
+
+// response membentuk schema untuk data response: field tanpa omitempty selalu ada.
+func (r *schemaRegistry) response(v interface{}) *Schema {
+	return r.schemaFor(reflect.TypeOf(v), false)
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type, request bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t, request)
+		}
+		if _, ok := r.schemas[t.Name()]; !ok {
+			// Daftarkan lebih dulu agar tipe rekursif tidak berputar tanpa akhir
+			r.schemas[t.Name()] = &Schema{}
+			*r.schemas[t.Name()] = *r.structSchema(t, request)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		// Byte di DTO (mis. webauthn.URLEncoded) diserialisasi sebagai string base64url
+		return &Schema{Type: "string", Format: "base64url"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem(), request)}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem(), request)}
+	case t.Kind() == reflect.String:
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// interface{} dan tipe lain: nilai JSON apa pun
+		return &Schema{}
+	}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := r.schemaFor(field.Type, request)
+		rules := strings.Split(field.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			switch {
+			case rule == "email":
+				property.Format = "email"
+			case strings.HasPrefix(rule, "min=") && property.Type == "string":
+				if n, err := strconv.Atoi(strings.TrimPrefix(rule, "min=")); err == nil {
+					property.MinLength = &n
+				}
+			}
+		}
+		if values, ok := r.enums[t.Name()+"."+name]; ok {
+			if property.Type == "array" && property.Items != nil {
+				// Enum pada field slice membatasi setiap elemennya
+				items := *property.Items
+				items.Enum = values
+				property.Items = &items
+			} else {
+				property.Enum = values
+			}
+		}
+		schema.Properties[name] = property
+
+		required := !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer
+		if request {
+			required = contains(rules, "required")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormAPITokenRepository adalah implementasi APITokenRepository di atas GORM.
+type gormAPITokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAPITokenRepository membuat APITokenRepository yang memakai koneksi GORM.
+func NewGormAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &gormAPITokenRepository{db: db}
+}
+
+func (r *gormAPITokenRepository) Create(ctx context.Context, token *model.APIToken) error {
+	return translateError(r.db.WithContext(ctx).Create(token).Error)
+}
+
+func (r *gormAPITokenRepository) FindByHash(ctx context.Context, tokenHash string) (model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	return token, translateError(err)
+}
+
+func (r *gormAPITokenRepository) ListByUser(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	tokens := []model.APIToken{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&tokens).Error
+	return tokens, translateError(err)
+}
+
+func (r *gormAPITokenRepository) RecordUse(ctx context.Context, id uint, usedAt time.Time) error {
+	return translateError(r.db.WithContext(ctx).Model(&model.APIToken{}).Where("id = ?", id).
+		Update("last_used_at", usedAt).Error)
+}
+
+func (r *gormAPITokenRepository) Delete(ctx context.Context, userID, id uint) error {
+	return affected(r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.APIToken{}))
+}
+
+func (r *gormAPITokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.APIToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormAuditRepository adalah implementasi AuditRepository di atas GORM.
+type gormAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAuditRepository membuat AuditRepository yang memakai koneksi GORM.
+func NewGormAuditRepository(db *gorm.DB) AuditRepository {
+	return &gormAuditRepository{db: db}
+}
+
+func (r *gormAuditRepository) Record(ctx context.Context, event *model.AuditEvent) error {
+	return translateError(r.db.WithContext(ctx).Create(event).Error)
+}
+
+func (r *gormAuditRepository) List(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.SubjectID != 0 {
+		query = query.Where("subject_id = ?", filter.SubjectID)
+	}
+	if filter.SessionID != "" {
+		query = query.Where("session_id = ?", filter.SessionID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	events := []model.AuditEvent{}
+	err := query.Order("id DESC").Find(&events).Error
+	return events, translateError(err)
+}
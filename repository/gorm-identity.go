@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormIdentityRepository adalah implementasi IdentityRepository di atas GORM.
+type gormIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewGormIdentityRepository membuat IdentityRepository yang memakai koneksi GORM.
+func NewGormIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &gormIdentityRepository{db: db}
+}
+
+func (r *gormIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return translateError(r.db.WithContext(ctx).Create(identity).Error)
+}
+
+func (r *gormIdentityRepository) Find(ctx context.Context, provider, subject string) (model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return identity, translateError(err)
+}
+
+func (r *gormIdentityRepository) ListByUser(ctx context.Context, userID uint) ([]model.UserIdentity, error) {
+	identities := []model.UserIdentity{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("provider").Find(&identities).Error
+	return identities, translateError(err)
+}
+
+func (r *gormIdentityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND provider = ?", userID, provider).Delete(&model.UserIdentity{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
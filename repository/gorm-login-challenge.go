@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormLoginChallengeRepository adalah implementasi LoginChallengeRepository di atas GORM.
+type gormLoginChallengeRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLoginChallengeRepository membuat LoginChallengeRepository yang memakai koneksi GORM.
+func NewGormLoginChallengeRepository(db *gorm.DB) LoginChallengeRepository {
+	return &gormLoginChallengeRepository{db: db}
+}
+
+func (r *gormLoginChallengeRepository) Create(ctx context.Context, challenge *model.LoginChallenge) error {
+	return translateError(r.db.WithContext(ctx).Create(challenge).Error)
+}
+
+func (r *gormLoginChallengeRepository) Find(ctx context.Context, challengeID string) (model.LoginChallenge, error) {
+	var challenge model.LoginChallenge
+	err := r.db.WithContext(ctx).Where("challenge_id = ?", challengeID).First(&challenge).Error
+	return challenge, translateError(err)
+}
+
+func (r *gormLoginChallengeRepository) RecordAttempt(ctx context.Context, id uint) (int, error) {
+	var attempts int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// UPDATE attempts = attempts + 1 mengunci baris sehingga percobaan bersamaan berurutan
+		result := tx.Model(&model.LoginChallenge{}).Where("id = ?", id).
+			UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&model.LoginChallenge{}).Where("id = ?", id).Pluck("attempts", &attempts).Error
+	})
+	return attempts, translateError(err)
+}
+
+func (r *gormLoginChallengeRepository) Consume(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&model.LoginChallenge{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	// Hanya request yang berhasil menghapus baris yang boleh memakai challenge
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormLoginChallengeRepository) ListByUser(ctx context.Context, userID uint, method string) ([]model.LoginChallenge, error) {
+	var challenges []model.LoginChallenge
+	err := r.db.WithContext(ctx).Where("user_id = ? AND method = ?", userID, method).Find(&challenges).Error
+	return challenges, translateError(err)
+}
+
+func (r *gormLoginChallengeRepository) DeleteByUser(ctx context.Context, userID uint, method string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND method = ?", userID, method).Delete(&model.LoginChallenge{})
+	return translateError(result.Error)
+}
+
+func (r *gormLoginChallengeRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.LoginChallenge{})
+	return result.RowsAffected, result.Error
+}
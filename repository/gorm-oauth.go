@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormOAuthRepository adalah implementasi OAuthRepository di atas GORM.
+type gormOAuthRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOAuthRepository membuat OAuthRepository yang memakai koneksi GORM.
+func NewGormOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &gormOAuthRepository{db: db}
+}
+
+func (r *gormOAuthRepository) CreateClient(ctx context.Context, client *model.OAuthClient) error {
+	return translateError(r.db.WithContext(ctx).Create(client).Error)
+}
+
+func (r *gormOAuthRepository) FindClient(ctx context.Context, clientID string) (model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error
+	return client, translateError(err)
+}
+
+func (r *gormOAuthRepository) ListClients(ctx context.Context) ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	err := r.db.WithContext(ctx).Order("id").Find(&clients).Error
+	return clients, translateError(err)
+}
+
+func (r *gormOAuthRepository) SaveAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	return translateError(r.db.WithContext(ctx).Create(code).Error)
+}
+
+func (r *gormOAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+			return err
+		}
+		// Hanya request yang berhasil menghapus baris yang boleh memakai kode
+		result := tx.Delete(&model.OAuthAuthorizationCode{}, code.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.OAuthAuthorizationCode{}, translateError(err)
+	}
+	return code, nil
+}
+
+func (r *gormOAuthRepository) PurgeExpiredCodes(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.OAuthAuthorizationCode{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormTokenRepository adalah implementasi TokenRepository di atas GORM.
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTokenRepository membuat TokenRepository yang memakai koneksi GORM.
+func NewGormTokenRepository(db *gorm.DB) TokenRepository {
+	return &gormTokenRepository{db: db}
+}
+
+func (r *gormTokenRepository) Revoke(ctx context.Context, token string) error {
+	err := r.db.WithContext(ctx).Create(&model.RevokedToken{Token: token}).Error
+	// Membatalkan token yang sudah dibatalkan tidak dianggap error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil
+	}
+	return err
+}
+
+func (r *gormTokenRepository) IsRevoked(ctx context.Context, token string) (bool, error) {
+	var revokedToken model.RevokedToken
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&revokedToken).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *gormTokenRepository) PurgeRevokedBefore(ctx context.Context, before time.Time) (int64, error) {
+	// Unscoped agar baris benar-benar dihapus, bukan soft delete
+	result := r.db.WithContext(ctx).Unscoped().Where("created_at < ?", before).Delete(&model.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
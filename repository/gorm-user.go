@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/utils"
+
+	"gorm.io/gorm"
+)
+
+// gormUserRepository adalah implementasi UserRepository di atas GORM.
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository membuat UserRepository yang memakai koneksi GORM.
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) List(ctx context.Context, filter UserFilter) ([]model.User, error) {
+	query := r.db.WithContext(ctx).Order("id")
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	var users []model.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id uint) (model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Where("email_normalized = ?", utils.NormalizeEmail(email)).First(&user).Error
+	return user, translateError(err)
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *model.User) error {
+	user.EmailNormalized = utils.NormalizeEmail(user.Email)
+	return translateError(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *gormUserRepository) UpdateVersioned(ctx context.Context, user *model.User, columns ...string) error {
+	expected := user.Version
+	user.Version++
+	if containsString(columns, "email") {
+		user.EmailNormalized = utils.NormalizeEmail(user.Email)
+		columns = append(columns, "email_normalized")
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND version = ?", user.ID, expected).
+		Select(append(columns, "version")).
+		Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		user.Version = expected
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *gormUserRepository) DeleteVersioned(ctx context.Context, id uint, version uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND version = ?", id, version).Delete(&model.User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *gormUserRepository) Transaction(ctx context.Context, fn func(users UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormUserRepository{db: tx})
+	})
+}
+
+// translateError menyeragamkan error GORM menjadi error repository.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
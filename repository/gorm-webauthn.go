@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-fiber-user-management/model"
+
+	"gorm.io/gorm"
+)
+
+// gormWebAuthnRepository adalah implementasi WebAuthnRepository di atas GORM.
+type gormWebAuthnRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWebAuthnRepository membuat WebAuthnRepository yang memakai koneksi GORM.
+func NewGormWebAuthnRepository(db *gorm.DB) WebAuthnRepository {
+	return &gormWebAuthnRepository{db: db}
+}
+
+func (r *gormWebAuthnRepository) Create(ctx context.Context, credential *model.WebAuthnCredential) error {
+	return translateError(r.db.WithContext(ctx).Create(credential).Error)
+}
+
+func (r *gormWebAuthnRepository) FindByCredentialID(ctx context.Context, credentialID string) (model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+	err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&credential).Error
+	return credential, translateError(err)
+}
+
+func (r *gormWebAuthnRepository) ListByUser(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error) {
+	credentials := []model.WebAuthnCredential{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&credentials).Error
+	return credentials, translateError(err)
+}
+
+func (r *gormWebAuthnRepository) Rename(ctx context.Context, userID, id uint, name string) error {
+	// MySQL tidak menghitung baris yang nilainya tidak berubah, jadi keberadaan diperiksa terpisah
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var credential model.WebAuthnCredential
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&credential).Error; err != nil {
+			return err
+		}
+		return tx.Model(&credential).Update("name", name).Error
+	})
+	return translateError(err)
+}
+
+func (r *gormWebAuthnRepository) RecordUse(ctx context.Context, id uint, signCount int64, usedAt time.Time) error {
+	return affected(r.db.WithContext(ctx).Model(&model.WebAuthnCredential{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sign_count": signCount, "last_used_at": usedAt}))
+}
+
+func (r *gormWebAuthnRepository) Delete(ctx context.Context, userID, id uint) error {
+	return affected(r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.WebAuthnCredential{}))
+}
+
+// affected menerjemahkan hasil UPDATE/DELETE yang tidak mengenai baris menjadi ErrNotFound.
+func affected(result *gorm.DB) error {
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryAPITokenRepository adalah APITokenRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryAPITokenRepository struct {
+	mu     sync.Mutex
+	tokens []model.APIToken
+	nextID uint
+}
+
+// NewMemoryAPITokenRepository membuat APITokenRepository in-memory yang kosong.
+func NewMemoryAPITokenRepository() APITokenRepository {
+	return &memoryAPITokenRepository{nextID: 1}
+}
+
+func (r *memoryAPITokenRepository) Create(ctx context.Context, token *model.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return ErrDuplicate
+		}
+	}
+	token.ID = r.nextID
+	r.nextID++
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *memoryAPITokenRepository) FindByHash(ctx context.Context, tokenHash string) (model.APIToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return model.APIToken{}, ErrNotFound
+}
+
+func (r *memoryAPITokenRepository) ListByUser(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := []model.APIToken{}
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryAPITokenRepository) RecordUse(ctx context.Context, id uint, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.tokens {
+		if r.tokens[i].ID == id {
+			r.tokens[i].LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (r *memoryAPITokenRepository) Delete(ctx context.Context, userID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, token := range r.tokens {
+		if token.ID == id && token.UserID == userID {
+			r.tokens = append(r.tokens[:i], r.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *memoryAPITokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.tokens[:0]
+	for _, token := range r.tokens {
+		if !token.ExpiresAt.Before(before) {
+			kept = append(kept, token)
+		}
+	}
+	purged := int64(len(r.tokens) - len(kept))
+	r.tokens = kept
+	return purged, nil
+}
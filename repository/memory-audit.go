@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryAuditRepository adalah AuditRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryAuditRepository struct {
+	mu     sync.Mutex
+	events []model.AuditEvent
+}
+
+// NewMemoryAuditRepository membuat AuditRepository in-memory yang kosong.
+func NewMemoryAuditRepository() AuditRepository {
+	return &memoryAuditRepository{}
+}
+
+func (r *memoryAuditRepository) Record(ctx context.Context, event *model.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = uint(len(r.events) + 1)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryAuditRepository) List(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []model.AuditEvent{}
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		switch {
+		case filter.Action != "" && event.Action != filter.Action,
+			filter.ActorID != 0 && event.ActorID != filter.ActorID,
+			filter.SubjectID != 0 && event.SubjectID != filter.SubjectID,
+			filter.SessionID != "" && event.SessionID != filter.SessionID:
+			continue
+		}
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryIdentityRepository adalah IdentityRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryIdentityRepository struct {
+	mu         sync.Mutex
+	identities []model.UserIdentity
+	nextID     uint
+}
+
+// NewMemoryIdentityRepository membuat IdentityRepository in-memory yang kosong.
+func NewMemoryIdentityRepository() IdentityRepository {
+	return &memoryIdentityRepository{nextID: 1}
+}
+
+func (r *memoryIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Constraint unik yang sama dengan tabel user_identities
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider &&
+			(existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return ErrDuplicate
+		}
+	}
+	identity.ID = r.nextID
+	r.nextID++
+	if identity.CreatedAt.IsZero() {
+		identity.CreatedAt = time.Now()
+	}
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryIdentityRepository) Find(ctx context.Context, provider, subject string) (model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return model.UserIdentity{}, ErrNotFound
+}
+
+func (r *memoryIdentityRepository) ListByUser(ctx context.Context, userID uint) ([]model.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identities := []model.UserIdentity{}
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Provider < identities[j].Provider })
+	return identities, nil
+}
+
+func (r *memoryIdentityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryLoginChallengeRepository adalah LoginChallengeRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryLoginChallengeRepository struct {
+	mu         sync.Mutex
+	challenges map[uint]model.LoginChallenge
+	nextID     uint
+}
+
+// NewMemoryLoginChallengeRepository membuat LoginChallengeRepository in-memory yang kosong.
+func NewMemoryLoginChallengeRepository() LoginChallengeRepository {
+	return &memoryLoginChallengeRepository{challenges: map[uint]model.LoginChallenge{}, nextID: 1}
+}
+
+func (r *memoryLoginChallengeRepository) Create(ctx context.Context, challenge *model.LoginChallenge) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.challenges {
+		if existing.ChallengeID == challenge.ChallengeID {
+			return ErrDuplicate
+		}
+	}
+	challenge.ID = r.nextID
+	r.nextID++
+	if challenge.CreatedAt.IsZero() {
+		challenge.CreatedAt = time.Now()
+	}
+	r.challenges[challenge.ID] = *challenge
+	return nil
+}
+
+func (r *memoryLoginChallengeRepository) Find(ctx context.Context, challengeID string) (model.LoginChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, challenge := range r.challenges {
+		if challenge.ChallengeID == challengeID {
+			return challenge, nil
+		}
+	}
+	return model.LoginChallenge{}, ErrNotFound
+}
+
+func (r *memoryLoginChallengeRepository) RecordAttempt(ctx context.Context, id uint) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	challenge, ok := r.challenges[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	challenge.Attempts++
+	r.challenges[id] = challenge
+	return challenge.Attempts, nil
+}
+
+func (r *memoryLoginChallengeRepository) Consume(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.challenges[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.challenges, id)
+	return nil
+}
+
+func (r *memoryLoginChallengeRepository) ListByUser(ctx context.Context, userID uint, method string) ([]model.LoginChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var challenges []model.LoginChallenge
+	for _, challenge := range r.challenges {
+		if challenge.UserID == userID && challenge.Method == method {
+			challenges = append(challenges, challenge)
+		}
+	}
+	return challenges, nil
+}
+
+func (r *memoryLoginChallengeRepository) DeleteByUser(ctx context.Context, userID uint, method string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, challenge := range r.challenges {
+		if challenge.UserID == userID && challenge.Method == method {
+			delete(r.challenges, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryLoginChallengeRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, challenge := range r.challenges {
+		if challenge.ExpiresAt.Before(before) {
+			delete(r.challenges, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryOAuthRepository adalah OAuthRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryOAuthRepository struct {
+	mu      sync.Mutex
+	clients map[string]model.OAuthClient
+	codes   map[string]model.OAuthAuthorizationCode // code hash -> kode
+	nextID  uint
+}
+
+// NewMemoryOAuthRepository membuat OAuthRepository in-memory yang kosong.
+func NewMemoryOAuthRepository() OAuthRepository {
+	return &memoryOAuthRepository{
+		clients: map[string]model.OAuthClient{},
+		codes:   map[string]model.OAuthAuthorizationCode{},
+		nextID:  1,
+	}
+}
+
+func (r *memoryOAuthRepository) CreateClient(ctx context.Context, client *model.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[client.ClientID]; ok {
+		return ErrDuplicate
+	}
+	client.ID = r.nextID
+	r.nextID++
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = time.Now()
+	}
+	r.clients[client.ClientID] = *client
+	return nil
+}
+
+func (r *memoryOAuthRepository) FindClient(ctx context.Context, clientID string) (model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	client, ok := r.clients[clientID]
+	if !ok {
+		return model.OAuthClient{}, ErrNotFound
+	}
+	return client, nil
+}
+
+func (r *memoryOAuthRepository) ListClients(ctx context.Context) ([]model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clients := make([]model.OAuthClient, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ID < clients[j].ID })
+	return clients, nil
+}
+
+func (r *memoryOAuthRepository) SaveAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codes[code.CodeHash]; ok {
+		return ErrDuplicate
+	}
+	code.ID = r.nextID
+	r.nextID++
+	if code.CreatedAt.IsZero() {
+		code.CreatedAt = time.Now()
+	}
+	r.codes[code.CodeHash] = *code
+	return nil
+}
+
+func (r *memoryOAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (model.OAuthAuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	code, ok := r.codes[codeHash]
+	if !ok {
+		return model.OAuthAuthorizationCode{}, ErrNotFound
+	}
+	delete(r.codes, codeHash)
+	return code, nil
+}
+
+func (r *memoryOAuthRepository) PurgeExpiredCodes(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for hash, code := range r.codes {
+		if code.ExpiresAt.Before(before) {
+			delete(r.codes, hash)
+			purged++
+		}
+	}
+	return purged, nil
+}
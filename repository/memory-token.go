@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryTokenRepository adalah TokenRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time // token -> waktu dibatalkan
+}
+
+// NewMemoryTokenRepository membuat TokenRepository in-memory yang kosong.
+func NewMemoryTokenRepository() TokenRepository {
+	return &memoryTokenRepository{tokens: map[string]time.Time{}}
+}
+
+func (r *memoryTokenRepository) Revoke(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[token]; !ok {
+		r.tokens[token] = time.Now()
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) IsRevoked(ctx context.Context, token string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.tokens[token]
+	return ok, nil
+}
+
+func (r *memoryTokenRepository) PurgeRevokedBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for token, revokedAt := range r.tokens {
+		if revokedAt.Before(before) {
+			delete(r.tokens, token)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/utils"
+)
+
+// memoryUserStore adalah state bersama untuk MemoryUserRepository dan transaksinya.
+type memoryUserStore struct {
+	mu     sync.RWMutex
+	users  map[uint]model.User
+	nextID uint
+}
+
+// memoryUserRepository adalah UserRepository in-memory yang aman untuk dipakai bersamaan.
+// Ditujukan untuk pengujian dan pengembangan lokal tanpa database.
+type memoryUserRepository struct {
+	store *memoryUserStore
+	inTx  bool // true jika dipanggil dari dalam Transaction, lock sudah dipegang
+}
+
+// NewMemoryUserRepository membuat UserRepository in-memory yang kosong.
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{store: &memoryUserStore{users: map[uint]model.User{}, nextID: 1}}
+}
+
+func (r *memoryUserRepository) rlock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.store.mu.RLock()
+	return r.store.mu.RUnlock
+}
+
+func (r *memoryUserRepository) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.store.mu.Lock()
+	return r.store.mu.Unlock
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, filter UserFilter) ([]model.User, error) {
+	defer r.rlock()()
+
+	users := make([]model.User, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		if len(filter.Statuses) > 0 && !containsString(filter.Statuses, user.Status) {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id uint) (model.User, error) {
+	defer r.rlock()()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return model.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	defer r.rlock()()
+
+	if user, ok := r.findByNormalizedEmail(utils.NormalizeEmail(email)); ok {
+		return user, nil
+	}
+	return model.User{}, ErrNotFound
+}
+
+// findByNormalizedEmail mencari pengguna berdasarkan email ternormalisasi; lock harus sudah dipegang.
+func (r *memoryUserRepository) findByNormalizedEmail(normalized string) (model.User, bool) {
+	for _, user := range r.store.users {
+		if user.EmailNormalized == normalized {
+			return user, true
+		}
+	}
+	return model.User{}, false
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	defer r.lock()()
+
+	user.EmailNormalized = utils.NormalizeEmail(user.Email)
+	if _, taken := r.findByNormalizedEmail(user.EmailNormalized); taken {
+		return ErrDuplicate
+	}
+
+	user.ID = r.store.nextID
+	r.store.nextID++
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateVersioned(ctx context.Context, user *model.User, columns ...string) error {
+	defer r.lock()()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok || stored.Version != user.Version {
+		return ErrVersionConflict
+	}
+
+	if containsString(columns, "email") {
+		normalized := utils.NormalizeEmail(user.Email)
+		if other, taken := r.findByNormalizedEmail(normalized); taken && other.ID != user.ID {
+			return ErrDuplicate
+		}
+		user.EmailNormalized = normalized
+		columns = append(columns, "email_normalized")
+	}
+
+	applyUserColumns(&stored, *user, columns)
+	stored.Version++
+	user.Version = stored.Version
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteVersioned(ctx context.Context, id uint, version uint) error {
+	defer r.lock()()
+
+	stored, ok := r.store.users[id]
+	if !ok || stored.Version != version {
+		return ErrVersionConflict
+	}
+	delete(r.store.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) Transaction(ctx context.Context, fn func(users UserRepository) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Simpan salinan state untuk rollback
+	snapshot := make(map[uint]model.User, len(r.store.users))
+	for id, user := range r.store.users {
+		snapshot[id] = user
+	}
+	nextID := r.store.nextID
+
+	if err := fn(&memoryUserRepository{store: r.store, inTx: true}); err != nil {
+		r.store.users = snapshot
+		r.store.nextID = nextID
+		return err
+	}
+	return nil
+}
+
+// applyUserColumns menyalin kolom yang dipilih (nama kolom database) dari src ke dst.
+func applyUserColumns(dst *model.User, src model.User, columns []string) {
+	for _, column := range columns {
+		switch column {
+		case "email":
+			dst.Email = src.Email
+		case "email_normalized":
+			dst.EmailNormalized = src.EmailNormalized
+		case "password_hash":
+			dst.PasswordHash = src.PasswordHash
+		case "fullname":
+			dst.Fullname = src.Fullname
+		case "address":
+			dst.Address = src.Address
+		case "gender":
+			dst.Gender = src.Gender
+		case "phone_number":
+			dst.PhoneNumber = src.PhoneNumber
+		case "role":
+			dst.Role = src.Role
+		case "status":
+			dst.Status = src.Status
+		case "status_reason":
+			dst.StatusReason = src.StatusReason
+		case "status_changed_at":
+			dst.StatusChangedAt = src.StatusChangedAt
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+// memoryWebAuthnRepository adalah WebAuthnRepository in-memory yang aman untuk dipakai bersamaan.
+type memoryWebAuthnRepository struct {
+	mu          sync.Mutex
+	credentials []model.WebAuthnCredential
+	nextID      uint
+}
+
+// NewMemoryWebAuthnRepository membuat WebAuthnRepository in-memory yang kosong.
+func NewMemoryWebAuthnRepository() WebAuthnRepository {
+	return &memoryWebAuthnRepository{nextID: 1}
+}
+
+func (r *memoryWebAuthnRepository) Create(ctx context.Context, credential *model.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.credentials {
+		if existing.CredentialID == credential.CredentialID {
+			return ErrDuplicate
+		}
+	}
+	credential.ID = r.nextID
+	r.nextID++
+	if credential.CreatedAt.IsZero() {
+		credential.CreatedAt = time.Now()
+	}
+	r.credentials = append(r.credentials, *credential)
+	return nil
+}
+
+func (r *memoryWebAuthnRepository) FindByCredentialID(ctx context.Context, credentialID string) (model.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, credential := range r.credentials {
+		if credential.CredentialID == credentialID {
+			return credential, nil
+		}
+	}
+	return model.WebAuthnCredential{}, ErrNotFound
+}
+
+func (r *memoryWebAuthnRepository) ListByUser(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credentials := []model.WebAuthnCredential{}
+	for _, credential := range r.credentials {
+		if credential.UserID == userID {
+			credentials = append(credentials, credential)
+		}
+	}
+	return credentials, nil
+}
+
+func (r *memoryWebAuthnRepository) Rename(ctx context.Context, userID, id uint, name string) error {
+	return r.update(func(credential *model.WebAuthnCredential) bool {
+		if credential.ID != id || credential.UserID != userID {
+			return false
+		}
+		credential.Name = name
+		return true
+	})
+}
+
+func (r *memoryWebAuthnRepository) RecordUse(ctx context.Context, id uint, signCount int64, usedAt time.Time) error {
+	return r.update(func(credential *model.WebAuthnCredential) bool {
+		if credential.ID != id {
+			return false
+		}
+		credential.SignCount = signCount
+		credential.LastUsedAt = &usedAt
+		return true
+	})
+}
+
+func (r *memoryWebAuthnRepository) Delete(ctx context.Context, userID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, credential := range r.credentials {
+		if credential.ID == id && credential.UserID == userID {
+			r.credentials = append(r.credentials[:i], r.credentials[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// update menerapkan fn ke kredensial pertama yang cocok (fn mengembalikan true).
+func (r *memoryWebAuthnRepository) update(fn func(*model.WebAuthnCredential) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.credentials {
+		if fn(&r.credentials[i]) {
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-fiber-user-management/model"
+)
+
+var (
+	// ErrNotFound dikembalikan ketika data yang dicari tidak ada.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate dikembalikan ketika data melanggar constraint unik (mis. email yang sama).
+	ErrDuplicate = errors.New("record already exists")
+	// ErrVersionConflict dikembalikan ketika versi baris sudah berubah sejak dibaca.
+	ErrVersionConflict = errors.New("record has been modified by another request")
+)
+
+// UserFilter berisi kriteria opsional untuk daftar pengguna.
+type UserFilter struct {
+	Statuses []string // Kosong berarti semua status
+}
+
+// UserRepository adalah akses penyimpanan untuk model.User.
+// Implementasi mengisi EmailNormalized setiap kali Email disimpan, dan FindByEmail
+// mencocokkan bentuk ternormalisasinya sehingga pencarian tidak peka huruf besar/kecil.
+type UserRepository interface {
+	List(ctx context.Context, filter UserFilter) ([]model.User, error)
+	FindByID(ctx context.Context, id uint) (model.User, error)
+	FindByEmail(ctx context.Context, email string) (model.User, error)
+	// Create menyimpan pengguna baru. Mengembalikan ErrDuplicate jika email sudah dipakai.
+	Create(ctx context.Context, user *model.User) error
+	// UpdateVersioned menyimpan kolom yang dipilih dan menaikkan Version, hanya jika versi
+	// yang tersimpan masih sama dengan user.Version. Mengembalikan ErrVersionConflict jika tidak.
+	UpdateVersioned(ctx context.Context, user *model.User, columns ...string) error
+	// DeleteVersioned menghapus pengguna jika versinya masih sama.
+	DeleteVersioned(ctx context.Context, id uint, version uint) error
+	// Transaction menjalankan fn di dalam satu transaksi; error dari fn membatalkan semua perubahan.
+	Transaction(ctx context.Context, fn func(users UserRepository) error) error
+}
+
+// TokenRepository menyimpan token JWT yang sudah dibatalkan (logout).
+type TokenRepository interface {
+	Revoke(ctx context.Context, token string) error
+	IsRevoked(ctx context.Context, token string) (bool, error)
+	// PurgeRevokedBefore menghapus permanen token yang dibatalkan sebelum waktu tertentu
+	// dan mengembalikan jumlah yang dihapus.
+	PurgeRevokedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OAuthRepository menyimpan klien OAuth2 dan kode otorisasi.
+type OAuthRepository interface {
+	// CreateClient menyimpan klien baru. Mengembalikan ErrDuplicate jika ClientID sudah dipakai.
+	CreateClient(ctx context.Context, client *model.OAuthClient) error
+	FindClient(ctx context.Context, clientID string) (model.OAuthClient, error)
+	ListClients(ctx context.Context) ([]model.OAuthClient, error)
+	SaveAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error
+	// ConsumeAuthorizationCode mengambil dan menghapus kode dalam satu langkah sehingga kode
+	// hanya bisa ditukar sekali. Mengembalikan ErrNotFound jika kode tidak ada atau sudah dipakai.
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (model.OAuthAuthorizationCode, error)
+	// PurgeExpiredCodes menghapus kode yang kedaluwarsa sebelum waktu tertentu.
+	PurgeExpiredCodes(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IdentityRepository menyimpan tautan identitas IdP eksternal ke pengguna.
+type IdentityRepository interface {
+	// Create menyimpan identitas baru. Mengembalikan ErrDuplicate jika subject sudah tertaut
+	// atau pengguna sudah punya identitas dari provider yang sama.
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	// Find mencari identitas berdasarkan provider dan subject dari IdP.
+	Find(ctx context.Context, provider, subject string) (model.UserIdentity, error)
+	// ListByUser mengembalikan identitas milik pengguna, terurut berdasarkan provider.
+	ListByUser(ctx context.Context, userID uint) ([]model.UserIdentity, error)
+	// Delete melepas identitas provider dari pengguna. Mengembalikan ErrNotFound jika tidak ada.
+	Delete(ctx context.Context, userID uint, provider string) error
+}
+
+// LoginChallengeRepository menyimpan challenge login tanpa password.
+type LoginChallengeRepository interface {
+	Create(ctx context.Context, challenge *model.LoginChallenge) error
+	// Find mencari challenge berdasarkan ID publiknya.
+	Find(ctx context.Context, challengeID string) (model.LoginChallenge, error)
+	// RecordAttempt menaikkan Attempts secara atomik dan mengembalikan nilai barunya, sehingga
+	// percobaan yang berjalan bersamaan tetap terhitung semua.
+	RecordAttempt(ctx context.Context, id uint) (int, error)
+	// Consume menghapus challenge. Mengembalikan ErrNotFound jika sudah dipakai atau dihapus,
+	// sehingga hanya satu verifikasi yang bisa berhasil.
+	Consume(ctx context.Context, id uint) error
+	// ListByUser mengembalikan challenge milik pengguna dengan method tertentu, termasuk yang kedaluwarsa.
+	ListByUser(ctx context.Context, userID uint, method string) ([]model.LoginChallenge, error)
+	// DeleteByUser menghapus challenge milik pengguna dengan method tertentu.
+	DeleteByUser(ctx context.Context, userID uint, method string) error
+	// PurgeExpired menghapus challenge yang kedaluwarsa sebelum waktu tertentu.
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WebAuthnRepository menyimpan passkey (kredensial WebAuthn) milik pengguna.
+type WebAuthnRepository interface {
+	// Create menyimpan kredensial baru. Mengembalikan ErrDuplicate jika CredentialID sudah terdaftar.
+	Create(ctx context.Context, credential *model.WebAuthnCredential) error
+	// FindByCredentialID mencari kredensial berdasarkan credential ID base64url.
+	FindByCredentialID(ctx context.Context, credentialID string) (model.WebAuthnCredential, error)
+	// ListByUser mengembalikan kredensial milik pengguna, terurut dari yang paling lama.
+	ListByUser(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error)
+	// Rename mengganti nama kredensial milik pengguna. Mengembalikan ErrNotFound jika tidak ada.
+	Rename(ctx context.Context, userID, id uint, name string) error
+	// RecordUse menyimpan sign counter dan waktu pemakaian terakhir.
+	RecordUse(ctx context.Context, id uint, signCount int64, usedAt time.Time) error
+	// Delete menghapus kredensial milik pengguna. Mengembalikan ErrNotFound jika tidak ada.
+	Delete(ctx context.Context, userID, id uint) error
+}
+
+// APITokenRepository menyimpan token akses pribadi milik pengguna.
+type APITokenRepository interface {
+	// Create menyimpan token baru. Mengembalikan ErrDuplicate jika TokenHash sudah ada.
+	Create(ctx context.Context, token *model.APIToken) error
+	// FindByHash mencari token berdasarkan hash SHA-256 nilainya.
+	FindByHash(ctx context.Context, tokenHash string) (model.APIToken, error)
+	// ListByUser mengembalikan token milik pengguna, terurut dari yang paling lama.
+	ListByUser(ctx context.Context, userID uint) ([]model.APIToken, error)
+	// RecordUse menyimpan waktu pemakaian terakhir.
+	RecordUse(ctx context.Context, id uint, usedAt time.Time) error
+	// Delete menghapus token milik pengguna. Mengembalikan ErrNotFound jika tidak ada.
+	Delete(ctx context.Context, userID, id uint) error
+	// PurgeExpired menghapus token yang kedaluwarsa sebelum waktu yang diberikan.
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AuditRepository menyimpan audit trail. Catatan hanya bisa ditambahkan, tidak diubah atau dihapus.
+type AuditRepository interface {
+	// Record menyimpan catatan baru.
+	Record(ctx context.Context, event *model.AuditEvent) error
+	// List mengembalikan catatan yang cocok dengan filter, terbaru lebih dulu.
+	List(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error)
+}
@@ -0,0 +1,598 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/database"
+	"go-fiber-user-management/migration"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+
+	"gorm.io/gorm"
+)
+
+// stores mengembalikan setiap implementasi repository yang harus berperilaku sama.
+func stores(t *testing.T) map[string]func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+	return map[string]func(t *testing.T) (repository.UserRepository, repository.TokenRepository){
+		"memory": func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+			return repository.NewMemoryUserRepository(), repository.NewMemoryTokenRepository()
+		},
+		"sqlite": func(t *testing.T) (repository.UserRepository, repository.TokenRepository) {
+			db := openSQLite(t)
+			return repository.NewGormUserRepository(db), repository.NewGormTokenRepository(db)
+		},
+	}
+}
+
+// oauthStores mengembalikan setiap implementasi OAuthRepository.
+func oauthStores(t *testing.T) map[string]func(t *testing.T) repository.OAuthRepository {
+	return map[string]func(t *testing.T) repository.OAuthRepository{
+		"memory": func(t *testing.T) repository.OAuthRepository {
+			return repository.NewMemoryOAuthRepository()
+		},
+		"sqlite": func(t *testing.T) repository.OAuthRepository {
+			return repository.NewGormOAuthRepository(openSQLite(t))
+		},
+	}
+}
+
+// openSQLite membuka database SQLite sementara yang sudah dimigrasi.
+func openSQLite(t *testing.T) *gorm.DB {
+	db, err := database.Open(database.Config{
+		Driver:  database.DriverSQLite,
+		Name:    filepath.Join(t.TempDir(), "test.db"),
+		SSLMode: "disable",
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if _, err := migration.New(db, database.DriverSQLite).Up(context.Background()); err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+	return db
+}
+
+func newUser(email, status string) *model.User {
+	return &model.User{
+		Email:        email,
+		PasswordHash: "hash",
+		Fullname:     "Test",
+		Role:         model.RoleUser,
+		Status:       status,
+		Version:      1,
+	}
+}
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+
+	for name, open := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			users, _ := open(t)
+
+			active := newUser("active@mail.com", model.StatusActive)
+			suspended := newUser("suspended@mail.com", model.StatusSuspended)
+			for _, u := range []*model.User{active, suspended} {
+				if err := users.Create(ctx, u); err != nil {
+					t.Fatalf("create: %v", err)
+				}
+			}
+			if active.ID == 0 || suspended.ID <= active.ID {
+				t.Fatalf("unexpected ids %d, %d", active.ID, suspended.ID)
+			}
+
+			all, err := users.List(ctx, repository.UserFilter{})
+			if err != nil || len(all) != 2 || all[0].ID != active.ID {
+				t.Fatalf("list all = %v, %v", all, err)
+			}
+			filtered, err := users.List(ctx, repository.UserFilter{Statuses: []string{model.StatusSuspended}})
+			if err != nil || len(filtered) != 1 || filtered[0].Email != suspended.Email {
+				t.Fatalf("list filtered = %v, %v", filtered, err)
+			}
+
+			found, err := users.FindByEmail(ctx, "active@mail.com")
+			if err != nil || found.ID != active.ID {
+				t.Fatalf("find by email = %v, %v", found, err)
+			}
+			// Email dicocokkan tanpa membedakan huruf besar/kecil, baik saat mencari maupun menyimpan
+			if byCase, err := users.FindByEmail(ctx, "  Active@Mail.COM "); err != nil || byCase.ID != active.ID {
+				t.Fatalf("find by email ignoring case = %v, %v", byCase, err)
+			}
+			if err := users.Create(ctx, newUser("ACTIVE@mail.com", model.StatusActive)); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("create duplicate = %v, want ErrDuplicate", err)
+			}
+			taken := *suspended
+			taken.Email = "Active@Mail.com"
+			if err := users.UpdateVersioned(ctx, &taken, "email"); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("update to duplicate = %v, want ErrDuplicate", err)
+			}
+			if _, err := users.FindByID(ctx, 999); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("find missing = %v, want ErrNotFound", err)
+			}
+
+			// Hanya kolom yang dipilih yang disimpan, dan versi naik satu
+			changed := found
+			changed.Fullname = "Renamed"
+			changed.Address = "Not saved"
+			if err := users.UpdateVersioned(ctx, &changed, "fullname"); err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			reloaded, _ := users.FindByID(ctx, active.ID)
+			if reloaded.Fullname != "Renamed" || reloaded.Address != "" || reloaded.Version != 2 || changed.Version != 2 {
+				t.Fatalf("after update = %+v", reloaded)
+			}
+
+			// Versi lama ditolak
+			stale := found
+			stale.Fullname = "Stale"
+			if err := users.UpdateVersioned(ctx, &stale, "fullname"); !errors.Is(err, repository.ErrVersionConflict) {
+				t.Fatalf("stale update = %v, want ErrVersionConflict", err)
+			}
+			if err := users.DeleteVersioned(ctx, active.ID, 1); !errors.Is(err, repository.ErrVersionConflict) {
+				t.Fatalf("stale delete = %v, want ErrVersionConflict", err)
+			}
+			if err := users.DeleteVersioned(ctx, active.ID, 2); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := users.FindByID(ctx, active.ID); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("find deleted = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryTransaction(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	for name, open := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			users, _ := open(t)
+			user := newUser("user@mail.com", model.StatusActive)
+			if err := users.Create(ctx, user); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			err := users.Transaction(ctx, func(tx repository.UserRepository) error {
+				user.Status = model.StatusSuspended
+				if err := tx.UpdateVersioned(ctx, user, "status"); err != nil {
+					return err
+				}
+				if err := tx.Create(ctx, newUser("other@mail.com", model.StatusActive)); err != nil {
+					return err
+				}
+				return errAbort
+			})
+			if !errors.Is(err, errAbort) {
+				t.Fatalf("transaction = %v, want errAbort", err)
+			}
+
+			reloaded, _ := users.FindByID(ctx, user.ID)
+			if reloaded.Status != model.StatusActive || reloaded.Version != 1 {
+				t.Errorf("update not rolled back: %+v", reloaded)
+			}
+			if _, err := users.FindByEmail(ctx, "other@mail.com"); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("create not rolled back: %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenRepository(t *testing.T) {
+	ctx := context.Background()
+
+	for name, open := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			_, tokens := open(t)
+
+			if revoked, err := tokens.IsRevoked(ctx, "token"); err != nil || revoked {
+				t.Fatalf("fresh token revoked = %v, %v", revoked, err)
+			}
+			if err := tokens.Revoke(ctx, "token"); err != nil {
+				t.Fatalf("revoke: %v", err)
+			}
+			if revoked, err := tokens.IsRevoked(ctx, "token"); err != nil || !revoked {
+				t.Fatalf("revoked token = %v, %v", revoked, err)
+			}
+
+			// Hanya token yang dibatalkan sebelum batas waktu yang dihapus
+			if purged, err := tokens.PurgeRevokedBefore(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+				t.Fatalf("purge old = %d, %v, want 0", purged, err)
+			}
+			if purged, err := tokens.PurgeRevokedBefore(ctx, time.Now().Add(time.Minute)); err != nil || purged != 1 {
+				t.Fatalf("purge = %d, %v, want 1", purged, err)
+			}
+			if revoked, err := tokens.IsRevoked(ctx, "token"); err != nil || revoked {
+				t.Fatalf("purged token revoked = %v, %v", revoked, err)
+			}
+		})
+	}
+}
+
+func TestOAuthRepository(t *testing.T) {
+	ctx := context.Background()
+
+	for name, open := range oauthStores(t) {
+		t.Run(name, func(t *testing.T) {
+			oauth := open(t)
+
+			client := &model.OAuthClient{
+				ClientID:     "client-1",
+				Name:         "Partner",
+				RedirectURIs: "https://partner.example/callback",
+				GrantTypes:   model.GrantAuthorizationCode,
+				Scopes:       "profile",
+			}
+			if err := oauth.CreateClient(ctx, client); err != nil || client.ID == 0 {
+				t.Fatalf("create client = %v (id %d)", err, client.ID)
+			}
+			duplicate := *client
+			duplicate.ID = 0
+			if err := oauth.CreateClient(ctx, &duplicate); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("duplicate client err = %v, want ErrDuplicate", err)
+			}
+			if found, err := oauth.FindClient(ctx, "client-1"); err != nil || found.Name != "Partner" {
+				t.Fatalf("find client = %v, %v", found, err)
+			}
+			if _, err := oauth.FindClient(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("find missing client err = %v, want ErrNotFound", err)
+			}
+			if clients, err := oauth.ListClients(ctx); err != nil || len(clients) != 1 {
+				t.Fatalf("list clients = %v, %v", clients, err)
+			}
+
+			for _, hash := range []string{"fresh", "stale"} {
+				expires := time.Now().Add(time.Minute)
+				if hash == "stale" {
+					expires = time.Now().Add(-time.Minute)
+				}
+				if err := oauth.SaveAuthorizationCode(ctx, &model.OAuthAuthorizationCode{
+					CodeHash: hash, ClientID: "client-1", UserID: 1, RedirectURI: "https://partner.example/callback",
+					Scope: "profile", CodeChallenge: "challenge", CodeChallengeMethod: model.PKCEMethodS256, ExpiresAt: expires,
+				}); err != nil {
+					t.Fatalf("save code %s: %v", hash, err)
+				}
+			}
+
+			// Kode hanya bisa ditukar sekali
+			code, err := oauth.ConsumeAuthorizationCode(ctx, "fresh")
+			if err != nil || code.ClientID != "client-1" || code.UserID != 1 {
+				t.Fatalf("consume = %v, %v", code, err)
+			}
+			if _, err := oauth.ConsumeAuthorizationCode(ctx, "fresh"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("second consume err = %v, want ErrNotFound", err)
+			}
+
+			if purged, err := oauth.PurgeExpiredCodes(ctx, time.Now()); err != nil || purged != 1 {
+				t.Fatalf("purge codes = %d, %v, want 1", purged, err)
+			}
+			if _, err := oauth.ConsumeAuthorizationCode(ctx, "stale"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("purged code err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestIdentityRepository(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) repository.IdentityRepository{
+		"memory": func(t *testing.T) repository.IdentityRepository {
+			return repository.NewMemoryIdentityRepository()
+		},
+		"sqlite": func(t *testing.T) repository.IdentityRepository {
+			return repository.NewGormIdentityRepository(openSQLite(t))
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			identities := open(t)
+
+			for _, identity := range []*model.UserIdentity{
+				{UserID: 1, Provider: "google", Subject: "g-1", Email: "user@mail.com"},
+				{UserID: 1, Provider: "github", Subject: "42"},
+				{UserID: 2, Provider: "google", Subject: "g-2"},
+			} {
+				if err := identities.Create(ctx, identity); err != nil || identity.ID == 0 {
+					t.Fatalf("create %s/%s = %v (id %d)", identity.Provider, identity.Subject, err, identity.ID)
+				}
+			}
+
+			// Subject yang sama, atau provider kedua untuk pengguna yang sama, ditolak
+			for _, duplicate := range []*model.UserIdentity{
+				{UserID: 3, Provider: "google", Subject: "g-1"},
+				{UserID: 1, Provider: "google", Subject: "g-3"},
+			} {
+				if err := identities.Create(ctx, duplicate); !errors.Is(err, repository.ErrDuplicate) {
+					t.Fatalf("duplicate %+v err = %v, want ErrDuplicate", duplicate, err)
+				}
+			}
+
+			if found, err := identities.Find(ctx, "google", "g-1"); err != nil || found.UserID != 1 || found.Email != "user@mail.com" {
+				t.Fatalf("find = %+v, %v", found, err)
+			}
+			if _, err := identities.Find(ctx, "github", "g-1"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("find other provider err = %v, want ErrNotFound", err)
+			}
+
+			list, err := identities.ListByUser(ctx, 1)
+			if err != nil || len(list) != 2 || list[0].Provider != "github" || list[1].Provider != "google" {
+				t.Fatalf("list = %+v, %v", list, err)
+			}
+
+			if err := identities.Delete(ctx, 1, "google"); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if err := identities.Delete(ctx, 1, "google"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("second delete err = %v, want ErrNotFound", err)
+			}
+			// Subject yang dilepas boleh ditautkan lagi
+			if err := identities.Create(ctx, &model.UserIdentity{UserID: 3, Provider: "google", Subject: "g-1"}); err != nil {
+				t.Fatalf("relink: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginChallengeRepository(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) repository.LoginChallengeRepository{
+		"memory": func(t *testing.T) repository.LoginChallengeRepository {
+			return repository.NewMemoryLoginChallengeRepository()
+		},
+		"sqlite": func(t *testing.T) repository.LoginChallengeRepository {
+			return repository.NewGormLoginChallengeRepository(openSQLite(t))
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			challenges := open(t)
+			newChallenge := func(challengeID string, userID uint, expiresAt time.Time) *model.LoginChallenge {
+				return &model.LoginChallenge{
+					ChallengeID: challengeID, UserID: userID, Method: model.LoginMethodCode,
+					SecretHash: "secret", DeviceHash: "device", ExpiresAt: expiresAt,
+				}
+			}
+
+			live := newChallenge("live", 1, time.Now().Add(time.Hour))
+			if err := challenges.Create(ctx, live); err != nil || live.ID == 0 {
+				t.Fatalf("create = %v (id %d)", err, live.ID)
+			}
+			if err := challenges.Create(ctx, newChallenge("live", 2, time.Now())); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("duplicate err = %v, want ErrDuplicate", err)
+			}
+
+			for want := 1; want <= 2; want++ {
+				if attempts, err := challenges.RecordAttempt(ctx, live.ID); err != nil || attempts != want {
+					t.Fatalf("attempt = %d, %v, want %d", attempts, err, want)
+				}
+			}
+			if found, err := challenges.Find(ctx, "live"); err != nil || found.Attempts != 2 || found.UserID != 1 {
+				t.Fatalf("find = %+v, %v", found, err)
+			}
+
+			// Challenge hanya bisa dipakai sekali
+			if err := challenges.Consume(ctx, live.ID); err != nil {
+				t.Fatalf("consume: %v", err)
+			}
+			if err := challenges.Consume(ctx, live.ID); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("second consume err = %v, want ErrNotFound", err)
+			}
+			if _, err := challenges.RecordAttempt(ctx, live.ID); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("attempt on consumed err = %v, want ErrNotFound", err)
+			}
+
+			for _, challenge := range []*model.LoginChallenge{
+				newChallenge("a", 1, time.Now().Add(time.Hour)),
+				newChallenge("b", 2, time.Now().Add(time.Hour)),
+				newChallenge("stale", 2, time.Now().Add(-time.Minute)),
+			} {
+				if err := challenges.Create(ctx, challenge); err != nil {
+					t.Fatalf("create %s: %v", challenge.ChallengeID, err)
+				}
+			}
+			// Challenge dengan method lain tidak ikut terhapus
+			other := newChallenge("other", 1, time.Now().Add(time.Hour))
+			other.Method = model.LoginMethodLink
+			if err := challenges.Create(ctx, other); err != nil {
+				t.Fatalf("create other: %v", err)
+			}
+			if listed, err := challenges.ListByUser(ctx, 1, model.LoginMethodCode); err != nil || len(listed) != 1 ||
+				listed[0].ChallengeID != "a" {
+				t.Fatalf("list by user = %+v, %v, want only a", listed, err)
+			}
+			if err := challenges.DeleteByUser(ctx, 1, model.LoginMethodCode); err != nil {
+				t.Fatalf("delete by user: %v", err)
+			}
+			if _, err := challenges.Find(ctx, "a"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("deleted challenge err = %v, want ErrNotFound", err)
+			}
+			if purged, err := challenges.PurgeExpired(ctx, time.Now()); err != nil || purged != 1 {
+				t.Fatalf("purge = %d, %v, want 1", purged, err)
+			}
+			for _, challengeID := range []string{"b", "other"} {
+				if _, err := challenges.Find(ctx, challengeID); err != nil {
+					t.Fatalf("challenge %s removed: %v", challengeID, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWebAuthnRepository(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) repository.WebAuthnRepository{
+		"memory": func(t *testing.T) repository.WebAuthnRepository {
+			return repository.NewMemoryWebAuthnRepository()
+		},
+		"sqlite": func(t *testing.T) repository.WebAuthnRepository {
+			return repository.NewGormWebAuthnRepository(openSQLite(t))
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			credentials := open(t)
+			newCredential := func(userID uint, credentialID string) *model.WebAuthnCredential {
+				return &model.WebAuthnCredential{
+					UserID: userID, CredentialID: credentialID, Name: "Passkey",
+					PublicKey: []byte{0xa5, 0x01, 0x02}, Algorithm: -7,
+				}
+			}
+
+			first, second := newCredential(1, "cred-1"), newCredential(1, "cred-2")
+			for _, credential := range []*model.WebAuthnCredential{first, second, newCredential(2, "cred-3")} {
+				if err := credentials.Create(ctx, credential); err != nil || credential.ID == 0 {
+					t.Fatalf("create %s = %v (id %d)", credential.CredentialID, err, credential.ID)
+				}
+			}
+			if err := credentials.Create(ctx, newCredential(2, "cred-1")); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("duplicate err = %v, want ErrDuplicate", err)
+			}
+
+			found, err := credentials.FindByCredentialID(ctx, "cred-1")
+			if err != nil || found.UserID != 1 || string(found.PublicKey) != string(first.PublicKey) || found.LastUsedAt != nil {
+				t.Fatalf("find = %+v, %v", found, err)
+			}
+
+			usedAt := time.Now().Truncate(time.Second)
+			if err := credentials.RecordUse(ctx, first.ID, 7, usedAt); err != nil {
+				t.Fatalf("record use: %v", err)
+			}
+			// Rename ke nama yang sama tetap berhasil
+			for _, name := range []string{"Laptop", "Laptop"} {
+				if err := credentials.Rename(ctx, 1, first.ID, name); err != nil {
+					t.Fatalf("rename: %v", err)
+				}
+			}
+			// Kredensial pengguna lain tidak bisa diubah atau dihapus
+			if err := credentials.Rename(ctx, 2, first.ID, "Stolen"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("rename other user err = %v, want ErrNotFound", err)
+			}
+			if err := credentials.Delete(ctx, 2, first.ID); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("delete other user err = %v, want ErrNotFound", err)
+			}
+
+			list, err := credentials.ListByUser(ctx, 1)
+			if err != nil || len(list) != 2 || list[0].ID != first.ID || list[0].Name != "Laptop" || list[0].SignCount != 7 ||
+				list[0].LastUsedAt == nil || !list[0].LastUsedAt.Equal(usedAt) {
+				t.Fatalf("list = %+v, %v", list, err)
+			}
+
+			if err := credentials.Delete(ctx, 1, second.ID); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := credentials.FindByCredentialID(ctx, "cred-2"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("deleted credential err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestAPITokenRepository(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) repository.APITokenRepository{
+		"memory": func(t *testing.T) repository.APITokenRepository {
+			return repository.NewMemoryAPITokenRepository()
+		},
+		"sqlite": func(t *testing.T) repository.APITokenRepository {
+			return repository.NewGormAPITokenRepository(openSQLite(t))
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			tokens := open(t)
+			now := time.Now().Truncate(time.Second)
+			newToken := func(userID uint, hash string, expiresAt time.Time) *model.APIToken {
+				return &model.APIToken{
+					UserID: userID, Name: "CI", Prefix: "umt_" + hash[:4], TokenHash: hash,
+					Scopes: model.ScopeUsersRead, ExpiresAt: expiresAt,
+				}
+			}
+
+			active, expired := newToken(1, "hash-active", now.Add(time.Hour)), newToken(1, "hash-expired", now.Add(-time.Hour))
+			for _, token := range []*model.APIToken{active, expired, newToken(2, "hash-other", now.Add(time.Hour))} {
+				if err := tokens.Create(ctx, token); err != nil || token.ID == 0 {
+					t.Fatalf("create %s = %v (id %d)", token.TokenHash, err, token.ID)
+				}
+			}
+			if err := tokens.Create(ctx, newToken(2, "hash-active", now)); !errors.Is(err, repository.ErrDuplicate) {
+				t.Fatalf("duplicate err = %v, want ErrDuplicate", err)
+			}
+
+			found, err := tokens.FindByHash(ctx, "hash-active")
+			if err != nil || found.ID != active.ID || found.Scopes != model.ScopeUsersRead || found.LastUsedAt != nil {
+				t.Fatalf("find = %+v, %v", found, err)
+			}
+			if err := tokens.RecordUse(ctx, active.ID, now); err != nil {
+				t.Fatalf("record use: %v", err)
+			}
+			if err := tokens.Delete(ctx, 2, active.ID); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("delete other user err = %v, want ErrNotFound", err)
+			}
+
+			purged, err := tokens.PurgeExpired(ctx, now)
+			if err != nil || purged != 1 {
+				t.Fatalf("purge = %d, %v; want 1", purged, err)
+			}
+			list, err := tokens.ListByUser(ctx, 1)
+			if err != nil || len(list) != 1 || list[0].ID != active.ID || list[0].LastUsedAt == nil || !list[0].LastUsedAt.Equal(now) {
+				t.Fatalf("list = %+v, %v", list, err)
+			}
+
+			if err := tokens.Delete(ctx, 1, active.ID); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := tokens.FindByHash(ctx, "hash-active"); !errors.Is(err, repository.ErrNotFound) {
+				t.Fatalf("deleted token err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestAuditRepository(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) repository.AuditRepository{
+		"memory": func(t *testing.T) repository.AuditRepository {
+			return repository.NewMemoryAuditRepository()
+		},
+		"sqlite": func(t *testing.T) repository.AuditRepository {
+			return repository.NewGormAuditRepository(openSQLite(t))
+		},
+	}
+
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			audit := open(t)
+			events := []*model.AuditEvent{
+				{Action: model.AuditImpersonationStart, ActorID: 1, SubjectID: 2, SessionID: "s1", Detail: "ticket 42"},
+				{Action: model.AuditImpersonationRequest, ActorID: 1, SubjectID: 2, SessionID: "s1", Detail: "GET /api/v1/auth/profile"},
+				{Action: model.AuditImpersonationStart, ActorID: 1, SubjectID: 3, SessionID: "s2", Detail: "ticket 43"},
+			}
+			for _, event := range events {
+				if err := audit.Record(ctx, event); err != nil || event.ID == 0 {
+					t.Fatalf("record = %v (id %d)", err, event.ID)
+				}
+			}
+
+			all, err := audit.List(ctx, model.AuditFilter{ActorID: 1})
+			if err != nil || len(all) != 3 || all[0].ID != events[2].ID || all[0].CreatedAt.IsZero() {
+				t.Fatalf("list = %+v, %v; want newest first", all, err)
+			}
+			session, err := audit.List(ctx, model.AuditFilter{SessionID: "s1", Action: model.AuditImpersonationRequest})
+			if err != nil || len(session) != 1 || session[0].Detail != "GET /api/v1/auth/profile" {
+				t.Fatalf("session = %+v, %v", session, err)
+			}
+			limited, err := audit.List(ctx, model.AuditFilter{SubjectID: 2, Limit: 1})
+			if err != nil || len(limited) != 1 || limited[0].ID != events[1].ID {
+				t.Fatalf("limited = %+v, %v", limited, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package response
+
+import "github.com/gofiber/fiber/v2"
+
+// Envelope adalah bentuk tunggal respons sukses untuk semua controller.
+type Envelope struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// JSON mengirim respons sukses dengan envelope standar.
+func JSON(c *fiber.Ctx, status int, message string, data interface{}) error {
+	return c.Status(status).JSON(Envelope{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// OK mengirim respons 200 dengan envelope standar.
+func OK(c *fiber.Ctx, message string, data interface{}) error {
+	return JSON(c, fiber.StatusOK, message, data)
+}
+
+// Created mengirim respons 201 dengan envelope standar.
+func Created(c *fiber.Ctx, message string, data interface{}) error {
+	return JSON(c, fiber.StatusCreated, message, data)
+}
+
+// Accepted mengirim respons 202 dengan envelope standar.
+func Accepted(c *fiber.Ctx, message string, data interface{}) error {
+	return JSON(c, fiber.StatusAccepted, message, data)
+}
@@ -0,0 +1,131 @@
+package router_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// createAPIToken membuat token akses pribadi untuk pemilik jwt dan mengembalikan nilai serta datanya.
+func (a *testApp) createAPIToken(jwt string, body interface{}) (string, map[string]interface{}) {
+	a.t.Helper()
+
+	resp := a.request(fiber.MethodPost, "/api/v1/auth/tokens", body, jwt)
+	resp.expectStatus(a.t, fiber.StatusCreated)
+	data := resp.data(a.t)
+	return data["token"].(string), data["api_token"].(map[string]interface{})
+}
+
+func TestAPITokenLifecycle(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("ci@example.com")
+	jwt := app.tokenFor(user)
+
+	value, token := app.createAPIToken(jwt, map[string]interface{}{
+		"name": "GitHub Actions", "scopes": []string{model.ScopeProfileWrite, model.ScopeProfileRead}, "expires_in": 3600,
+	})
+	if !strings.HasPrefix(value, service.APITokenPrefix) || !strings.HasPrefix(value, token["prefix"].(string)) {
+		t.Fatalf("token %q does not start with prefix %v", value, token["prefix"])
+	}
+	if scopes := fmt.Sprint(token["scopes"]); scopes != "[profile:read profile:write]" {
+		t.Errorf("scopes = %s, want sorted known scopes", scopes)
+	}
+	if expires, _ := time.Parse(time.RFC3339, token["expires_at"].(string)); time.Until(expires) > time.Hour {
+		t.Errorf("expires_at = %v, want within an hour", token["expires_at"])
+	}
+
+	// Token dipakai seperti JWT
+	profile := app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, value)
+	profile.expectStatus(t, fiber.StatusOK)
+	if profile.data(t)["email"] != user.Email {
+		t.Errorf("profile = %v", profile.Body)
+	}
+
+	list := app.request(fiber.MethodGet, "/api/v1/auth/tokens", nil, jwt)
+	list.expectStatus(t, fiber.StatusOK)
+	tokens := list.Body["data"].([]interface{})
+	if len(tokens) != 1 || tokens[0].(map[string]interface{})["last_used_at"] == nil {
+		t.Fatalf("tokens = %v, want one used token", tokens)
+	}
+	if strings.Contains(fmt.Sprint(list.Body), value) {
+		t.Fatal("token list must not contain the token value")
+	}
+
+	// Token akses pribadi tidak bisa membuat token baru atau menambah cara login
+	app.request(fiber.MethodPost, "/api/v1/auth/tokens", map[string]interface{}{
+		"name": "escalate", "scopes": []string{model.ScopeUsersWrite},
+	}, value).expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+	app.request(fiber.MethodPost, passkeyBase+"/register/begin", nil, value).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+
+	// Pengguna lain tidak bisa mencabut token ini
+	path := fmt.Sprintf("/api/v1/auth/tokens/%v", token["id"])
+	other := app.tokenFor(app.createUser("other@example.com"))
+	app.request(fiber.MethodDelete, path, nil, other).expectProblem(t, fiber.StatusNotFound, apperror.CodeAPITokenNotFound)
+
+	app.request(fiber.MethodDelete, path, nil, jwt).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, value).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenInvalid)
+}
+
+func TestAPITokenValidation(t *testing.T) {
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.APITokens.MaxPerUser = 2
+	})
+	jwt := app.tokenFor(app.createUser("limits@example.com"))
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		code string
+	}{
+		{"missing name", map[string]interface{}{"scopes": []string{model.ScopeProfileRead}}, apperror.CodeValidationFailed},
+		{"no scopes", map[string]interface{}{"name": "CI"}, apperror.CodeValidationFailed},
+		{"unknown scope", map[string]interface{}{"name": "CI", "scopes": []string{"admin:all"}}, apperror.CodeInvalidScope},
+		{"expiry above max", map[string]interface{}{
+			"name": "CI", "scopes": []string{model.ScopeProfileRead}, "expires_in": int64(app.config.APITokens.MaxTTL/time.Second) + 1,
+		}, apperror.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.request(fiber.MethodPost, "/api/v1/auth/tokens", tt.body, jwt).
+				expectProblem(t, fiber.StatusBadRequest, tt.code)
+		})
+	}
+
+	body := map[string]interface{}{"name": "CI", "scopes": []string{model.ScopeProfileRead}}
+	app.createAPIToken(jwt, body)
+	app.createAPIToken(jwt, body)
+	app.request(fiber.MethodPost, "/api/v1/auth/tokens", body, jwt).
+		expectProblem(t, fiber.StatusConflict, apperror.CodeAPITokenLimit)
+}
+
+func TestAPITokenExpiryAndLogout(t *testing.T) {
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.APITokens.DefaultTTL = 50 * time.Millisecond
+	})
+	jwt := app.tokenFor(app.createUser("expiry@example.com"))
+	body := map[string]interface{}{"name": "nightly", "scopes": []string{model.ScopeProfileRead}}
+
+	expiring, _ := app.createAPIToken(jwt, body)
+	time.Sleep(100 * time.Millisecond)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, expiring).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenExpired)
+
+	// Logout dengan token akses pribadi mencabut token tersebut
+	value, _ := app.createAPIToken(jwt, map[string]interface{}{
+		"name": "script", "scopes": []string{model.ScopeProfileRead}, "expires_in": 3600,
+	})
+	app.request(fiber.MethodGet, "/api/v1/auth/logout", nil, value).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, value).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenInvalid)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, jwt).expectStatus(t, fiber.StatusOK)
+}
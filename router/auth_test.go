@@ -0,0 +1,190 @@
+package router_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-fiber-user-management/model"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{
+			name:   "success",
+			body:   map[string]string{"email": "new@mail.com", "password": testPassword, "fullname": "New User"},
+			status: http.StatusCreated,
+		},
+		{
+			name:   "duplicate email",
+			body:   map[string]string{"email": "taken@mail.com", "password": testPassword, "fullname": "Dup"},
+			status: http.StatusConflict,
+			code:   "email_exists",
+		},
+		{
+			name:   "duplicate email with different case",
+			body:   map[string]string{"email": " Taken@Mail.com ", "password": testPassword, "fullname": "Dup"},
+			status: http.StatusConflict,
+			code:   "email_exists",
+		},
+		{
+			name:   "short password",
+			body:   map[string]string{"email": "short@mail.com", "password": "123", "fullname": "Short"},
+			status: http.StatusBadRequest,
+			code:   "validation_failed",
+		},
+		{
+			name:   "malformed json",
+			body:   "{not json",
+			status: http.StatusBadRequest,
+			code:   "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			app.createUser("taken@mail.com")
+
+			resp := app.request(http.MethodPost, "/api/auth/register", tt.body, "")
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			data := resp.data(t)
+			if data["email"] != "new@mail.com" || data["status"] != model.StatusActive {
+				t.Errorf("unexpected user: %v", data)
+			}
+			if _, leaked := data["password_hash"]; leaked {
+				t.Error("response leaks password_hash")
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		status   int
+		code     string
+	}{
+		{name: "success", email: "user@mail.com", password: testPassword, status: http.StatusOK},
+		{name: "email ignores case", email: "User@Mail.COM", password: testPassword, status: http.StatusOK},
+		{name: "wrong password", email: "user@mail.com", password: "wrong-password", status: http.StatusUnauthorized, code: "invalid_credentials"},
+		{name: "unknown email", email: "nobody@mail.com", password: testPassword, status: http.StatusNotFound, code: "user_not_found"},
+		{name: "suspended account", email: "suspended@mail.com", password: testPassword, status: http.StatusForbidden, code: "account_inactive"},
+		{name: "pending account", email: "pending@mail.com", password: testPassword, status: http.StatusForbidden, code: "account_inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			app.createUser("user@mail.com")
+			app.createUser("suspended@mail.com", func(u *model.User) { u.Status = model.StatusSuspended })
+			app.createUser("pending@mail.com", func(u *model.User) { u.Status = model.StatusPending })
+
+			resp := app.request(http.MethodPost, "/api/auth/login",
+				map[string]string{"email": tt.email, "password": tt.password}, "")
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			token, _ := resp.data(t)["token"].(string)
+			if token == "" {
+				t.Fatal("login returned no token")
+			}
+
+			// Token hasil login harus bisa dipakai untuk rute yang dilindungi
+			app.request(http.MethodGet, "/api/auth/profile", nil, token).expectStatus(t, http.StatusOK)
+		})
+	}
+}
+
+func TestProfile(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("me@mail.com")
+	token := app.tokenFor(user)
+
+	tests := []struct {
+		name    string
+		headers []header
+		status  int
+		code    string
+	}{
+		{name: "valid token", headers: []header{{"Authorization", "Bearer " + token}}, status: http.StatusOK},
+		{name: "missing header", status: http.StatusUnauthorized, code: "token_missing"},
+		{name: "wrong scheme", headers: []header{{"Authorization", "Token " + token}}, status: http.StatusUnauthorized, code: "token_invalid"},
+		{name: "garbage token", headers: []header{{"Authorization", "Bearer not-a-jwt"}}, status: http.StatusUnauthorized, code: "token_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.request(http.MethodGet, "/api/auth/profile", nil, "", tt.headers...)
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			if resp.data(t)["email"] != user.Email {
+				t.Errorf("profile email = %v, want %s", resp.data(t)["email"], user.Email)
+			}
+			if resp.Header.Get("ETag") != `"1"` {
+				t.Errorf("ETag = %q, want \"1\"", resp.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	app := newTestApp(t)
+	token := app.tokenFor(app.createUser("me@mail.com"))
+
+	app.request(http.MethodGet, "/api/auth/logout", nil, token).expectStatus(t, http.StatusOK)
+	app.request(http.MethodGet, "/api/auth/profile", nil, token).
+		expectProblem(t, http.StatusUnauthorized, "token_revoked")
+}
+
+func TestTokenRejectedAfterSuspension(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.adminToken()
+	user := app.createUser("me@mail.com")
+	token := app.tokenFor(user)
+
+	app.request(http.MethodPost, fmt.Sprintf("/api/users/%d/suspend", user.ID), map[string]string{"reason": "abuse"}, admin).
+		expectStatus(t, http.StatusOK)
+	app.request(http.MethodGet, "/api/auth/profile", nil, token).
+		expectProblem(t, http.StatusForbidden, "account_inactive")
+}
+
+func TestRequestID(t *testing.T) {
+	app := newTestApp(t)
+
+	resp := app.request(http.MethodGet, "/healthz", nil, "", header{"X-Request-ID", "trace-42"})
+	if got := resp.Header.Get("X-Request-ID"); got != "trace-42" {
+		t.Errorf("X-Request-ID = %q, want trace-42", got)
+	}
+
+	// ID yang tidak valid diganti dengan ID baru
+	resp = app.request(http.MethodGet, "/healthz", nil, "", header{"X-Request-ID", "bad id with spaces"})
+	if got := resp.Header.Get("X-Request-ID"); got == "" || got == "bad id with spaces" {
+		t.Errorf("X-Request-ID = %q, want generated id", got)
+	}
+}
+
+func TestUnknownRouteIsProblem(t *testing.T) {
+	app := newTestApp(t)
+	app.request(http.MethodGet, "/api/does-not-exist", nil, "").
+		expectProblem(t, http.StatusNotFound, "not_found")
+}
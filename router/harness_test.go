@@ -0,0 +1,195 @@
+package router_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const testPassword = "secret123"
+
+// testApp membungkus aplikasi Fiber lengkap yang memakai repository in-memory.
+type testApp struct {
+	t      *testing.T
+	config config.Config
+	app    *fiber.App
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+}
+
+// testResponse adalah respons HTTP yang body JSON-nya sudah di-decode.
+type testResponse struct {
+	Status int
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// header membuat opsi request untuk menambahkan satu header.
+type header struct {
+	key, value string
+}
+
+// newTestApp membuat aplikasi dengan konfigurasi default dan repository in-memory;
+// mutate dapat mengubah dependensi (mis. konfigurasi) per test.
+func newTestApp(t *testing.T, mutate ...func(*router.Dependencies)) *testApp {
+	t.Helper()
+
+	deps := router.Dependencies{
+		Config: config.Default(),
+		Users:  repository.NewMemoryUserRepository(),
+		Tokens: repository.NewMemoryTokenRepository(),
+		OAuth:  repository.NewMemoryOAuthRepository(),
+
+		Identities:      repository.NewMemoryIdentityRepository(),
+		LoginChallenges: repository.NewMemoryLoginChallengeRepository(),
+		WebAuthn:        repository.NewMemoryWebAuthnRepository(),
+		APITokens:       repository.NewMemoryAPITokenRepository(),
+		Audit:           repository.NewMemoryAuditRepository(),
+	}
+	deps.Config.Auth.JWTSecret = "test-secret-that-is-at-least-32-chars"
+	for _, fn := range mutate {
+		fn(&deps)
+	}
+
+	return &testApp{
+		t:      t,
+		config: deps.Config,
+		app:    router.New(deps),
+		users:  deps.Users,
+		tokens: deps.Tokens,
+	}
+}
+
+// request mengirim request ke aplikasi. body dapat berupa string (dikirim apa adanya),
+// nil, atau nilai lain yang di-encode sebagai JSON.
+func (a *testApp) request(method, path string, body interface{}, token string, headers ...header) testResponse {
+	a.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	for _, h := range headers {
+		req.Header.Set(h.key, h.value)
+	}
+
+	resp, err := a.app.Test(req, -1)
+	if err != nil {
+		a.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.t.Fatalf("read body: %v", err)
+	}
+
+	result := testResponse{Status: resp.StatusCode, Header: resp.Header}
+	if len(raw) > 0 && raw[0] == '{' {
+		if err := json.Unmarshal(raw, &result.Body); err != nil {
+			a.t.Fatalf("decode body %q: %v", raw, err)
+		}
+	}
+	return result
+}
+
+// createUser adalah fixture yang menyimpan pengguna aktif langsung ke repository.
+func (a *testApp) createUser(email string, mutate ...func(*model.User)) model.User {
+	a.t.Helper()
+
+	user := model.User{
+		Email:        email,
+		PasswordHash: utils.GeneratePassword(testPassword),
+		Fullname:     "Test " + email,
+		Role:         model.RoleUser,
+		Status:       model.StatusActive,
+		Version:      1,
+	}
+	for _, fn := range mutate {
+		fn(&user)
+	}
+	if err := a.users.Create(context.Background(), &user); err != nil {
+		a.t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// tokenFor menghasilkan token JWT untuk pengguna tanpa melewati endpoint login.
+func (a *testApp) tokenFor(user model.User) string {
+	a.t.Helper()
+
+	token, err := utils.GenerateToken(user, a.config.Auth.JWTSecret, a.config.Auth.TokenTTL)
+	if err != nil {
+		a.t.Fatalf("generate token: %v", err)
+	}
+	return token
+}
+
+// adminToken membuat pengguna admin dan mengembalikan tokennya.
+func (a *testApp) adminToken() string {
+	a.t.Helper()
+	return a.tokenFor(a.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin }))
+}
+
+// data mengambil member "data" dari envelope sukses sebagai map.
+func (r testResponse) data(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	data, ok := r.Body["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response has no data object: %v", r.Body)
+	}
+	return data
+}
+
+// expectStatus memastikan kode status respons sesuai.
+func (r testResponse) expectStatus(t *testing.T, want int) {
+	t.Helper()
+	if r.Status != want {
+		t.Fatalf("status = %d, want %d (body: %v)", r.Status, want, r.Body)
+	}
+}
+
+// expectProblem memastikan respons adalah problem+json dengan status dan kode yang sesuai.
+func (r testResponse) expectProblem(t *testing.T, status int, code string) {
+	t.Helper()
+	r.expectStatus(t, status)
+
+	if ct := r.Header.Get(fiber.HeaderContentType); ct != "application/problem+json" {
+		t.Errorf("content type = %q, want application/problem+json", ct)
+	}
+	if got := r.Body["code"]; got != code {
+		t.Errorf("code = %v, want %s", got, code)
+	}
+	if got := r.Body["status"]; got != float64(status) {
+		t.Errorf("problem status = %v, want %d", got, status)
+	}
+}
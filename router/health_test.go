@@ -0,0 +1,85 @@
+package router_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go-fiber-user-management/health"
+	"go-fiber-user-management/router"
+)
+
+// failingDatabase mensimulasikan koneksi database yang putus.
+func failingDatabase(deps *router.Dependencies) {
+	deps.HealthChecks = append(deps.HealthChecks, health.Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		},
+	})
+}
+
+func TestLiveness(t *testing.T) {
+	app := newTestApp(t, failingDatabase)
+
+	// Liveness tidak bergantung pada dependensi
+	resp := app.request(http.MethodGet, "/healthz", nil, "")
+	resp.expectStatus(t, http.StatusOK)
+	if resp.data(t)["status"] != health.StatusUp {
+		t.Errorf("status = %v, want up", resp.data(t)["status"])
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	resp := newTestApp(t).request(http.MethodGet, "/readyz", nil, "")
+	resp.expectStatus(t, http.StatusOK)
+	components, _ := resp.data(t)["components"].(map[string]interface{})
+	if components["signing_key"] != health.StatusUp {
+		t.Errorf("components = %v", components)
+	}
+
+	resp = newTestApp(t, failingDatabase).request(http.MethodGet, "/readyz", nil, "")
+	resp.expectProblem(t, http.StatusServiceUnavailable, "not_ready")
+	components, _ = resp.Body["components"].(map[string]interface{})
+	if components["database"] != health.StatusDown || components["signing_key"] != health.StatusUp {
+		t.Errorf("components = %v", components)
+	}
+	if _, leaked := resp.Body["error"]; leaked {
+		t.Error("public readiness leaks component errors")
+	}
+
+	resp = newTestApp(t, func(deps *router.Dependencies) { deps.Config.Auth.JWTSecret = "" }).
+		request(http.MethodGet, "/readyz", nil, "")
+	resp.expectProblem(t, http.StatusServiceUnavailable, "not_ready")
+}
+
+func TestHealthReport(t *testing.T) {
+	app := newTestApp(t, failingDatabase)
+	user := app.createUser("user@mail.com")
+
+	app.request(http.MethodGet, "/api/health", nil, "").
+		expectProblem(t, http.StatusUnauthorized, "token_missing")
+	app.request(http.MethodGet, "/api/health", nil, app.tokenFor(user)).
+		expectProblem(t, http.StatusForbidden, "forbidden")
+
+	resp := app.request(http.MethodGet, "/api/health", nil, app.adminToken())
+	resp.expectStatus(t, http.StatusOK)
+	data := resp.data(t)
+	if data["status"] != health.StatusDown {
+		t.Errorf("status = %v, want down", data["status"])
+	}
+
+	components, _ := data["components"].([]interface{})
+	byName := map[string]map[string]interface{}{}
+	for _, c := range components {
+		component := c.(map[string]interface{})
+		byName[component["name"].(string)] = component
+	}
+	if db := byName["database"]; db["status"] != health.StatusDown || db["error"] != "connection refused" {
+		t.Errorf("database = %v", db)
+	}
+	if _, ok := byName["signing_key"]["latency_ms"].(float64); !ok {
+		t.Errorf("signing_key has no latency: %v", byName["signing_key"])
+	}
+}
@@ -0,0 +1,356 @@
+package router_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/router"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	stubClientID     = "test-client"
+	stubClientSecret = "test-secret"
+	stubReturnURL    = "https://app.example.com/login/done"
+)
+
+// stubUser adalah akun yang sedang login di stub IdP.
+type stubUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// stubGrant adalah authorization code yang diterbitkan stub IdP.
+type stubGrant struct {
+	user        stubUser
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// stubIdP adalah IdP OpenID Connect lokal: discovery, authorize (langsung menyetujui sebagai
+// user), token dengan PKCE, dan userinfo.
+type stubIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu     sync.Mutex
+	user   stubUser
+	codes  map[string]stubGrant
+	tokens map[string]stubUser
+}
+
+func newStubIdP(t *testing.T, user stubUser) *stubIdP {
+	t.Helper()
+
+	idp := &stubIdP{t: t, user: user, codes: map[string]stubGrant{}, tokens: map[string]stubUser{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/userinfo", idp.userInfo)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// login mengganti akun yang akan disetujui oleh authorize berikutnya.
+func (idp *stubIdP) login(user stubUser) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.user = user
+}
+
+func (idp *stubIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"userinfo_endpoint":      idp.server.URL + "/userinfo",
+	})
+}
+
+func (idp *stubIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != stubClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("state") == "" || query.Get("nonce") == "" ||
+		!strings.Contains(query.Get("scope"), "openid") {
+		idp.t.Errorf("stub authorize: unexpected request %v", query)
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	code := "code-" + query.Get("state")[:8]
+	idp.codes[code] = stubGrant{
+		user:        idp.user,
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+	}
+	idp.mu.Unlock()
+
+	http.Redirect(w, r, query.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+}
+
+func (idp *stubIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	form := r.PostForm
+	if form.Get("client_id") != stubClientID || form.Get("client_secret") != stubClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	idp.mu.Lock()
+	grant, ok := idp.codes[form.Get("code")]
+	delete(idp.codes, form.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+	if !ok || grant.redirectURI != form.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	// Aplikasi tidak memverifikasi tanda tangan ID token, sehingga kunci HMAC apa pun cukup
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            stubClientID,
+		"sub":            grant.user.Subject,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          grant.nonce,
+		"email":          grant.user.Email,
+		"email_verified": grant.user.EmailVerified,
+	}).SignedString([]byte("stub-idp-key"))
+	if err != nil {
+		idp.t.Fatalf("sign stub id token: %v", err)
+	}
+
+	accessToken := "access-" + form.Get("code")
+	idp.mu.Lock()
+	idp.tokens[accessToken] = grant.user
+	idp.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": accessToken, "token_type": "Bearer", "id_token": idToken})
+}
+
+func (idp *stubIdP) userInfo(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	user, ok := idp.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	idp.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sub": user.Subject, "name": user.Name, "email": user.Email})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// withStubIdP mendaftarkan stub IdP sebagai provider "stub".
+func withStubIdP(idp *stubIdP, mutate ...func(*config.IdentityProvider)) func(*router.Dependencies) {
+	return func(d *router.Dependencies) {
+		provider := config.IdentityProvider{
+			DisplayName:  "Stub",
+			Issuer:       idp.server.URL,
+			ClientID:     stubClientID,
+			ClientSecret: stubClientSecret,
+		}
+		for _, fn := range mutate {
+			fn(&provider)
+		}
+		d.Config.Identity = config.IdentityConfig{
+			Providers:  map[string]config.IdentityProvider{"stub": provider},
+			ReturnURLs: []string{stubReturnURL},
+		}
+	}
+}
+
+// stateCookie mengambil cookie identity_state dari response start atau link.
+func stateCookie(t *testing.T, resp testResponse) string {
+	t.Helper()
+
+	for _, raw := range resp.Header.Values(fiber.HeaderSetCookie) {
+		if pair, _, _ := strings.Cut(raw, ";"); strings.HasPrefix(pair, "identity_state=") {
+			return pair
+		}
+	}
+	t.Fatalf("no identity_state cookie in %v", resp.Header)
+	return ""
+}
+
+// followIdP membuka authURL di stub IdP dan mengembalikan URL callback tujuan redirect.
+func followIdP(t *testing.T, authURL string) *url.URL {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("stub authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("stub authorize status = %d", resp.StatusCode)
+	}
+	callback, err := url.Parse(resp.Header.Get(fiber.HeaderLocation))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	return callback
+}
+
+// identityLogin menjalankan alur start -> IdP -> callback seperti browser.
+func (a *testApp) identityLogin(path string) testResponse {
+	a.t.Helper()
+
+	start := a.request(http.MethodGet, path, nil, "")
+	start.expectStatus(a.t, fiber.StatusFound)
+	callback := followIdP(a.t, start.Header.Get(fiber.HeaderLocation))
+	return a.request(http.MethodGet, callback.RequestURI(), nil, "", header{fiber.HeaderCookie, stateCookie(a.t, start)})
+}
+
+func TestIdentityLoginProvisionsAccount(t *testing.T) {
+	idp := newStubIdP(t, stubUser{Subject: "u-1", Email: "new@corp.example", EmailVerified: true, Name: "New User"})
+	app := newTestApp(t, withStubIdP(idp, func(p *config.IdentityProvider) {
+		p.AutoProvision = true
+		p.AllowedDomains = []string{"corp.example"}
+	}))
+
+	providers := app.request(http.MethodGet, "/api/v1/auth/oauth/providers", nil, "")
+	providers.expectStatus(t, fiber.StatusOK)
+	if list, _ := providers.Body["data"].([]interface{}); len(list) != 1 || list[0].(map[string]interface{})["display_name"] != "Stub" {
+		t.Fatalf("providers = %v", providers.Body)
+	}
+
+	first := app.identityLogin("/api/v1/auth/oauth/stub/start")
+	first.expectStatus(t, fiber.StatusOK)
+	if data := first.data(t); data["created"] != true || data["token"] == "" {
+		t.Fatalf("first login = %v", data)
+	}
+
+	profile := app.request(http.MethodGet, "/api/v1/auth/profile", nil, first.data(t)["token"].(string))
+	profile.expectStatus(t, fiber.StatusOK)
+	if data := profile.data(t); data["email"] != "new@corp.example" || data["fullname"] != "New User" {
+		t.Fatalf("provisioned profile = %v", data)
+	}
+
+	// Login kedua memakai akun yang sama
+	second := app.identityLogin("/api/v1/auth/oauth/stub/start")
+	second.expectStatus(t, fiber.StatusOK)
+	if data := second.data(t); data["created"] != false || data["identity"].(map[string]interface{})["subject"] != "u-1" {
+		t.Fatalf("second login = %v", data)
+	}
+
+	// Akun tanpa password tidak boleh kehilangan satu-satunya cara login
+	token := second.data(t)["token"].(string)
+	app.request(http.MethodDelete, "/api/v1/auth/identities/stub", nil, token).
+		expectProblem(t, fiber.StatusConflict, "last_login_method")
+
+	// Domain di luar allowed_domains dan email yang belum terverifikasi tidak diprovisioning
+	idp.login(stubUser{Subject: "u-2", Email: "someone@gmail.com", EmailVerified: true})
+	app.identityLogin("/api/v1/auth/oauth/stub/start").expectProblem(t, fiber.StatusForbidden, "provisioning_disabled")
+	idp.login(stubUser{Subject: "u-3", Email: "other@corp.example"})
+	app.identityLogin("/api/v1/auth/oauth/stub/start").expectProblem(t, fiber.StatusForbidden, "provisioning_disabled")
+}
+
+func TestIdentityLinkAndUnlink(t *testing.T) {
+	idp := newStubIdP(t, stubUser{Subject: "u-1", Email: "user@mail.com", EmailVerified: true})
+	app := newTestApp(t, withStubIdP(idp))
+	user := app.createUser("user@mail.com")
+	token := app.tokenFor(user)
+
+	// Email sama tanpa link_by_email tidak boleh mengambil alih akun
+	app.identityLogin("/api/v1/auth/oauth/stub/start").expectProblem(t, fiber.StatusConflict, "email_exists")
+
+	link := app.request(http.MethodPost, "/api/v1/auth/oauth/stub/link", nil, token)
+	link.expectStatus(t, fiber.StatusOK)
+	callback := followIdP(t, link.data(t)["authorization_url"].(string))
+	linked := app.request(http.MethodGet, callback.RequestURI(), nil, "", header{fiber.HeaderCookie, stateCookie(t, link)})
+	linked.expectStatus(t, fiber.StatusOK)
+	if data := linked.data(t); data["linked"] != true || data["token"] != nil {
+		t.Fatalf("link callback = %v", data)
+	}
+
+	identities := app.request(http.MethodGet, "/api/v1/auth/identities", nil, token)
+	identities.expectStatus(t, fiber.StatusOK)
+	if list, _ := identities.Body["data"].([]interface{}); len(list) != 1 || list[0].(map[string]interface{})["provider"] != "stub" {
+		t.Fatalf("identities = %v", identities.Body)
+	}
+
+	// Identitas yang tertaut dipakai untuk login ke akun yang sama
+	login := app.identityLogin("/api/v1/auth/oauth/stub/start")
+	login.expectStatus(t, fiber.StatusOK)
+	profile := app.request(http.MethodGet, "/api/v1/auth/profile", nil, login.data(t)["token"].(string))
+	if profile.data(t)["id"] != float64(user.ID) {
+		t.Fatalf("login via identity = %v", profile.Body)
+	}
+
+	// Identitas milik akun lain tidak bisa ditautkan
+	other := app.createUser("other@mail.com")
+	link = app.request(http.MethodPost, "/api/v1/auth/oauth/stub/link", nil, app.tokenFor(other))
+	callback = followIdP(t, link.data(t)["authorization_url"].(string))
+	app.request(http.MethodGet, callback.RequestURI(), nil, "", header{fiber.HeaderCookie, stateCookie(t, link)}).
+		expectProblem(t, fiber.StatusConflict, "identity_already_linked")
+
+	app.request(http.MethodDelete, "/api/v1/auth/identities/stub", nil, token).expectStatus(t, fiber.StatusOK)
+	app.request(http.MethodDelete, "/api/v1/auth/identities/stub", nil, token).
+		expectProblem(t, fiber.StatusNotFound, "identity_not_found")
+}
+
+func TestIdentityCallbackState(t *testing.T) {
+	idp := newStubIdP(t, stubUser{Subject: "u-1", Email: "new@corp.example", EmailVerified: true})
+	app := newTestApp(t, withStubIdP(idp, func(p *config.IdentityProvider) { p.AutoProvision = true }))
+
+	start := app.request(http.MethodGet, "/api/v1/auth/oauth/stub/start", nil, "")
+	start.expectStatus(t, fiber.StatusFound)
+	cookie := stateCookie(t, start)
+	callback := followIdP(t, start.Header.Get(fiber.HeaderLocation))
+
+	// State dari IdP harus sama dengan cookie dari browser yang memulai login
+	tampered := callback.Query()
+	tampered.Set("state", "forged")
+	app.request(http.MethodGet, callback.Path+"?"+tampered.Encode(), nil, "", header{fiber.HeaderCookie, cookie}).
+		expectProblem(t, fiber.StatusBadRequest, "invalid_state")
+	app.request(http.MethodGet, callback.RequestURI(), nil, "").expectProblem(t, fiber.StatusBadRequest, "invalid_state")
+	app.request(http.MethodGet, callback.RequestURI(), nil, "", header{fiber.HeaderCookie, cookie + "x"}).
+		expectProblem(t, fiber.StatusBadRequest, "invalid_state")
+
+	app.request(http.MethodGet, "/api/v1/auth/oauth/unknown/start", nil, "").
+		expectProblem(t, fiber.StatusNotFound, "provider_not_found")
+	app.request(http.MethodGet, "/api/v1/auth/oauth/stub/start?return_to=https://evil.example", nil, "").
+		expectProblem(t, fiber.StatusBadRequest, "validation_failed")
+
+	// Dengan return_to, hasil dikirim ke frontend di fragment
+	start = app.request(http.MethodGet, "/api/v1/auth/oauth/stub/start?return_to="+url.QueryEscape(stubReturnURL), nil, "")
+	callback = followIdP(t, start.Header.Get(fiber.HeaderLocation))
+	done := app.request(http.MethodGet, callback.RequestURI(), nil, "", header{fiber.HeaderCookie, stateCookie(t, start)})
+	done.expectStatus(t, fiber.StatusFound)
+	location, err := url.Parse(done.Header.Get(fiber.HeaderLocation))
+	if err != nil || !strings.HasPrefix(location.String(), stubReturnURL+"#") {
+		t.Fatalf("return location = %q", done.Header.Get(fiber.HeaderLocation))
+	}
+	fragment, _ := url.ParseQuery(location.Fragment)
+	if fragment.Get("token") == "" || fragment.Get("provider") != "stub" || fragment.Get("created") != "true" {
+		t.Fatalf("return fragment = %v", fragment)
+	}
+}
@@ -0,0 +1,129 @@
+package router_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const supportEmail = "support@mail.com"
+
+// newImpersonationApp membuat aplikasi dengan support@mail.com sebagai admin yang boleh impersonation.
+func newImpersonationApp(t *testing.T) (*testApp, model.User, string) {
+	t.Helper()
+
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.Impersonation.Admins = []string{supportEmail}
+	})
+	support := app.createUser(supportEmail, func(u *model.User) { u.Role = model.RoleAdmin })
+	return app, support, app.tokenFor(support)
+}
+
+// impersonate memulai impersonation userID dan mengembalikan data sesinya.
+func (a *testApp) impersonate(admin string, userID uint) map[string]interface{} {
+	a.t.Helper()
+
+	resp := a.request(fiber.MethodPost, fmt.Sprintf("/api/v1/users/%d/impersonate", userID),
+		map[string]string{"reason": "Ticket #4521: cannot see invoices"}, admin)
+	resp.expectStatus(a.t, fiber.StatusCreated)
+	return resp.data(a.t)
+}
+
+func TestImpersonation(t *testing.T) {
+	app, support, admin := newImpersonationApp(t)
+	user := app.createUser("customer@mail.com")
+
+	session := app.impersonate(admin, user.ID)
+	token := session["token"].(string)
+	if session["user"].(map[string]interface{})["email"] != user.Email || session["session_id"] == "" {
+		t.Fatalf("session = %v", session)
+	}
+
+	profile := app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token)
+	profile.expectStatus(t, fiber.StatusOK)
+	data := profile.data(t)
+	act, _ := data["act"].(map[string]interface{})
+	if data["email"] != user.Email || act["email"] != support.Email || act["user_id"] != float64(support.ID) ||
+		act["session_id"] != session["session_id"] {
+		t.Fatalf("profile = %v, want customer with support as actor", data)
+	}
+	if _, ok := app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, admin).data(t)["act"]; ok {
+		t.Error("regular profile must not have an actor")
+	}
+
+	// Operasi sensitif ditolak dan tetap tercatat
+	app.request(fiber.MethodPost, "/api/v1/auth/tokens", map[string]interface{}{
+		"name": "backdoor", "scopes": []string{model.ScopeProfileRead},
+	}, token).expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+	app.request(fiber.MethodPost, passkeyBase+"/register/begin", nil, token).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+	app.request(fiber.MethodDelete, "/api/v1/auth/identities/google", nil, token).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+
+	app.request(fiber.MethodPost, "/api/v1/auth/impersonation/end", nil, admin).
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeNotImpersonating)
+	app.request(fiber.MethodPost, "/api/v1/auth/impersonation/end", nil, token).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenRevoked)
+
+	audit := app.request(fiber.MethodGet, "/api/v1/audit-events?session_id="+session["session_id"].(string), nil, admin)
+	audit.expectStatus(t, fiber.StatusOK)
+	var trail []string
+	for _, item := range audit.Body["data"].([]interface{}) {
+		event := item.(map[string]interface{})
+		if event["actor_id"] != float64(support.ID) || event["subject_id"] != float64(user.ID) {
+			t.Errorf("event = %v, want support acting on customer", event)
+		}
+		trail = append(trail, fmt.Sprintf("%s %s", event["action"], event["detail"]))
+	}
+	want := []string{
+		"impersonation.end ",
+		"impersonation.request POST /api/v1/auth/impersonation/end",
+		"impersonation.request DELETE /api/v1/auth/identities/google",
+		"impersonation.request POST /api/v1/auth/webauthn/register/begin",
+		"impersonation.request POST /api/v1/auth/tokens",
+		"impersonation.request GET /api/v1/auth/profile",
+		"impersonation.start Ticket #4521: cannot see invoices",
+	}
+	if fmt.Sprint(trail) != fmt.Sprint(want) {
+		t.Errorf("audit trail = %q\nwant %q", trail, want)
+	}
+
+	app.request(fiber.MethodGet, "/api/v1/audit-events", nil, app.tokenFor(user)).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+}
+
+func TestImpersonationIsRestricted(t *testing.T) {
+	app, support, admin := newImpersonationApp(t)
+	user := app.createUser("customer@mail.com")
+	other := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	otherAdmin := app.tokenFor(other)
+	path := fmt.Sprintf("/api/v1/users/%d/impersonate", user.ID)
+	reason := map[string]string{"reason": "support"}
+
+	// Hanya admin di impersonation.admins, dengan alasan, dan bukan terhadap admin lain
+	app.request(fiber.MethodPost, path, reason, otherAdmin).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+	app.request(fiber.MethodPost, path, map[string]string{"reason": " "}, admin).
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeValidationFailed)
+	app.request(fiber.MethodPost, fmt.Sprintf("/api/v1/users/%d/impersonate", other.ID), reason, admin).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+	app.request(fiber.MethodPost, "/api/v1/users/999/impersonate", reason, admin).
+		expectProblem(t, fiber.StatusNotFound, apperror.CodeUserNotFound)
+
+	// Token impersonation tidak bisa memulai impersonation lain
+	token := app.impersonate(admin, user.ID)["token"].(string)
+	app.request(fiber.MethodPost, path, reason, token).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeImpersonationNotAllowed)
+
+	// Menangguhkan admin langsung menghentikan sesi impersonation-nya
+	app.request(fiber.MethodPost, fmt.Sprintf("/api/v1/users/%d/suspend", support.ID), map[string]string{"reason": "offboarding"},
+		otherAdmin, header{"If-Match", `"1"`}).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenInvalid)
+}
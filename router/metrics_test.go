@@ -0,0 +1,65 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// scrapeMetrics mengambil /metrics dalam format teks Prometheus.
+func (a *testApp) scrapeMetrics() string {
+	a.t.Helper()
+
+	resp, err := a.app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), -1)
+	if err != nil {
+		a.t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		a.t.Fatalf("GET /metrics status = %d: %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	app := newTestApp(t)
+	app.createUser("user@mail.com")
+
+	app.request(http.MethodPost, "/api/auth/login",
+		map[string]string{"email": "user@mail.com", "password": testPassword}, "").expectStatus(t, http.StatusOK)
+	app.request(http.MethodPost, "/api/auth/login",
+		map[string]string{"email": "user@mail.com", "password": "wrong-password"}, "")
+	app.request(http.MethodPost, "/api/auth/register",
+		map[string]string{"email": "new@mail.com", "password": testPassword}, "").expectStatus(t, http.StatusCreated)
+
+	token := app.adminToken()
+	app.request(http.MethodGet, "/api/users/1", nil, token).expectStatus(t, http.StatusOK)
+	app.request(http.MethodGet, "/api/auth/logout", nil, token).expectStatus(t, http.StatusOK)
+	app.request(http.MethodGet, "/api/auth/profile", nil, token).expectStatus(t, http.StatusUnauthorized)
+	app.request(http.MethodGet, "/no/such/route/12345", nil, "")
+
+	body := app.scrapeMetrics()
+	for _, want := range []string{
+		`auth_login_attempts_total{outcome="success"}`,
+		`auth_login_attempts_total{outcome="invalid_credentials"}`,
+		`auth_registrations_total{outcome="success"}`,
+		`auth_token_revocations_total`,
+		`auth_revoked_token_hits_total`,
+		// Label rute memakai pola, bukan path asli
+		`http_requests_total{method="GET",route="/api/v1/users/:id",status="200"}`,
+		`http_requests_total{method="POST",route="/api/v1/auth/login",status="401"}`,
+		`http_requests_total{method="GET",route="unmatched",status="404"}`,
+		`http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/:id"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if strings.Contains(body, "12345") {
+		t.Error("raw path leaked into route label")
+	}
+}
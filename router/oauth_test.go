@@ -0,0 +1,316 @@
+package router_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	testRedirectURI = "http://127.0.0.1:8080/callback"
+	testVerifier    = "dBjftJeZ4CVP-mJ92K9Tvr3mvZ7JJ2ozBhfY8oPs0LQbU"
+)
+
+var formHeader = header{fiber.HeaderContentType, fiber.MIMEApplicationForm}
+
+// registerClient mendaftarkan klien lewat endpoint admin dan mengembalikan client_id dan secret.
+func (a *testApp) registerClient(admin string, req model.OAuthClientRequestDTO) (string, string) {
+	a.t.Helper()
+
+	resp := a.request(http.MethodPost, "/api/v1/oauth/clients", req, admin)
+	resp.expectStatus(a.t, fiber.StatusCreated)
+	data := resp.data(a.t)
+	secret, _ := data["client_secret"].(string)
+	return data["client_id"].(string), secret
+}
+
+// authorize menyetujui permintaan otorisasi lewat form consent dan mengembalikan URL redirect.
+func (a *testApp) authorize(clientID, email, password string) *url.URL {
+	a.t.Helper()
+	return a.approve(authorizeParams(clientID), email, password)
+}
+
+// approve mengirim form consent dengan parameter otorisasi form dan keputusan approve.
+func (a *testApp) approve(form url.Values, email, password string) *url.URL {
+	a.t.Helper()
+
+	form.Set("decision", "approve")
+	form.Set("email", email)
+	form.Set("password", password)
+	resp := a.request(http.MethodPost, "/oauth/authorize", form.Encode(), "", formHeader)
+	resp.expectStatus(a.t, fiber.StatusFound)
+
+	location, err := url.Parse(resp.Header.Get(fiber.HeaderLocation))
+	if err != nil {
+		a.t.Fatalf("parse location: %v", err)
+	}
+	return location
+}
+
+func authorizeParams(clientID string) url.Values {
+	sum := sha256.Sum256([]byte(testVerifier))
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {testRedirectURI},
+		"scope":                 {"profile"},
+		"state":                 {"xyz"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {model.PKCEMethodS256},
+	}
+}
+
+func basicAuth(id, secret string) header {
+	return header{fiber.HeaderAuthorization, "Basic " + base64.StdEncoding.EncodeToString([]byte(id+":"+secret))}
+}
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.adminToken()
+	user := app.createUser("user@mail.com")
+	clientID, secret := app.registerClient(admin, model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"profile"},
+	})
+	if secret != "" {
+		t.Fatalf("public client got a secret")
+	}
+
+	// Halaman consent
+	page := app.request(http.MethodGet, "/oauth/authorize?"+authorizeParams(clientID).Encode(), nil, "")
+	page.expectStatus(t, fiber.StatusOK)
+	if ct := page.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("content type = %q, want text/html", ct)
+	}
+	if page.Header.Get(fiber.HeaderXFrameOptions) != "DENY" ||
+		page.Header.Get(fiber.HeaderContentSecurityPolicy) != "frame-ancestors 'none'" {
+		t.Error("consent page can be framed")
+	}
+
+	// Password salah menampilkan ulang halaman consent tanpa redirect
+	form := authorizeParams(clientID)
+	form.Set("decision", "approve")
+	form.Set("email", user.Email)
+	form.Set("password", "wrong-password")
+	app.request(http.MethodPost, "/oauth/authorize", form.Encode(), "", formHeader).expectStatus(t, fiber.StatusUnauthorized)
+
+	location := app.authorize(clientID, user.Email, testPassword)
+	if location.Query().Get("state") != "xyz" || location.Query().Get("code") == "" {
+		t.Fatalf("redirect = %s, want code and state", location)
+	}
+
+	exchange := func(code, verifier string) testResponse {
+		return app.request(http.MethodPost, "/oauth/token", url.Values{
+			"grant_type":    {model.GrantAuthorizationCode},
+			"code":          {code},
+			"redirect_uri":  {testRedirectURI},
+			"code_verifier": {verifier},
+			"client_id":     {clientID},
+		}.Encode(), "", formHeader)
+	}
+
+	code := location.Query().Get("code")
+	resp := exchange(code, testVerifier)
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+		t.Error("token response may be cached")
+	}
+	if resp.Body["token_type"] != "Bearer" || resp.Body["scope"] != "profile" {
+		t.Fatalf("token response = %v", resp.Body)
+	}
+	accessToken := resp.Body["access_token"].(string)
+	// Scope profile milik OpenID Connect tidak memberi akses ke API; scope API harus diminta
+	app.request(http.MethodGet, "/api/v1/auth/profile", nil, accessToken).
+		expectProblem(t, fiber.StatusForbidden, "insufficient_scope")
+
+	// Kode hanya berlaku sekali
+	reused := exchange(code, testVerifier)
+	reused.expectStatus(t, fiber.StatusBadRequest)
+	if reused.Body["error"] != "invalid_grant" {
+		t.Errorf("reused code error = %v, want invalid_grant", reused.Body)
+	}
+
+	// code_verifier yang salah ditolak
+	wrong := exchange(app.authorize(clientID, user.Email, testPassword).Query().Get("code"), strings.Repeat("a", 43))
+	wrong.expectStatus(t, fiber.StatusBadRequest)
+	if wrong.Body["error"] != "invalid_grant" {
+		t.Errorf("wrong verifier error = %v, want invalid_grant", wrong.Body)
+	}
+}
+
+func TestOAuthAuthorizeErrors(t *testing.T) {
+	app := newTestApp(t)
+	clientID, _ := app.registerClient(app.adminToken(), model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"profile"},
+	})
+
+	// redirect_uri yang tidak terdaftar tidak pernah di-redirect
+	params := authorizeParams(clientID)
+	params.Set("redirect_uri", "https://attacker.example/callback")
+	resp := app.request(http.MethodGet, "/oauth/authorize?"+params.Encode(), nil, "")
+	resp.expectStatus(t, fiber.StatusBadRequest)
+	if resp.Header.Get(fiber.HeaderLocation) != "" {
+		t.Fatal("redirected to an unregistered redirect_uri")
+	}
+
+	// Error lain dikirim ke klien lewat redirect
+	params = authorizeParams(clientID)
+	params.Set("scope", "admin")
+	resp = app.request(http.MethodGet, "/oauth/authorize?"+params.Encode(), nil, "")
+	resp.expectStatus(t, fiber.StatusFound)
+	if location := resp.Header.Get(fiber.HeaderLocation); !strings.Contains(location, "error=invalid_scope") {
+		t.Errorf("location = %s, want invalid_scope", location)
+	}
+
+	params = authorizeParams(clientID)
+	params.Set("decision", "deny")
+	resp = app.request(http.MethodPost, "/oauth/authorize", params.Encode(), "", formHeader)
+	resp.expectStatus(t, fiber.StatusFound)
+	if location := resp.Header.Get(fiber.HeaderLocation); !strings.Contains(location, "error=access_denied") ||
+		!strings.Contains(location, "state=xyz") {
+		t.Errorf("location = %s, want access_denied with state", location)
+	}
+}
+
+// Halaman consent belum mendukung passkey, jadi akun yang wajib passkey ditolak dengan pesan
+// yang jelas alih-alih diminta faktor kedua yang tidak bisa diselesaikan.
+func TestOAuthConsentRejectsPasskeyAccounts(t *testing.T) {
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.WebAuthn.RequireForPasswordLogin = true
+	})
+	clientID, _ := app.registerClient(app.adminToken(), model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"profile"},
+	})
+	user := app.createUser("owner@example.com")
+	app.registerPasskey(app.tokenFor(user), newSoftAuthenticator(t, 0), "Phone")
+
+	form := authorizeParams(clientID)
+	form.Set("decision", "approve")
+	form.Set("email", user.Email)
+	form.Set("password", testPassword)
+	req := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(form.Encode()))
+	req.Header.Set(formHeader.key, formHeader.value)
+	resp, err := app.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("POST /oauth/authorize: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusForbidden || !strings.Contains(string(body), "protected by a passkey") {
+		t.Fatalf("POST /oauth/authorize = %d %s", resp.StatusCode, body)
+	}
+	if resp.Header.Get(fiber.HeaderXFrameOptions) != "DENY" {
+		t.Error("consent response can be framed")
+	}
+}
+
+func TestOAuthClientCredentialsIntrospectionAndRevocation(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.adminToken()
+	user := app.createUser("user@mail.com")
+
+	serviceID, serviceSecret := app.registerClient(admin, model.OAuthClientRequestDTO{
+		Name:         "Billing service",
+		GrantTypes:   []string{model.GrantClientCredentials},
+		Scopes:       []string{"users:read"},
+		Confidential: true,
+	})
+	spaID, _ := app.registerClient(admin, model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"profile"},
+	})
+
+	grant := url.Values{"grant_type": {model.GrantClientCredentials}}.Encode()
+	resp := app.request(http.MethodPost, "/oauth/token", grant, "", formHeader, basicAuth(serviceID, "wrong"))
+	resp.expectStatus(t, fiber.StatusUnauthorized)
+	if resp.Body["error"] != "invalid_client" || resp.Header.Get(fiber.HeaderWWWAuthenticate) == "" {
+		t.Errorf("bad secret response = %v", resp.Body)
+	}
+
+	resp = app.request(http.MethodPost, "/oauth/token", grant, "", formHeader, basicAuth(serviceID, serviceSecret))
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.Body["scope"] != "users:read" {
+		t.Fatalf("client credentials scope = %v", resp.Body)
+	}
+	serviceToken := resp.Body["access_token"].(string)
+
+	// Public client tidak boleh memakai client_credentials
+	app.request(http.MethodPost, "/oauth/token", grant+"&client_id="+spaID, "", formHeader).expectStatus(t, fiber.StatusBadRequest)
+
+	code := app.authorize(spaID, user.Email, testPassword).Query().Get("code")
+	resp = app.request(http.MethodPost, "/oauth/token", url.Values{
+		"grant_type": {model.GrantAuthorizationCode}, "code": {code}, "redirect_uri": {testRedirectURI},
+		"code_verifier": {testVerifier}, "client_id": {spaID},
+	}.Encode(), "", formHeader)
+	resp.expectStatus(t, fiber.StatusOK)
+	userToken := resp.Body["access_token"].(string)
+
+	introspect := func(token string) testResponse {
+		resp := app.request(http.MethodPost, "/oauth/introspect", url.Values{"token": {token}}.Encode(), "",
+			formHeader, basicAuth(serviceID, serviceSecret))
+		resp.expectStatus(t, fiber.StatusOK)
+		return resp
+	}
+	if got := introspect(userToken).Body; got["active"] != true || got["username"] != user.Email || got["client_id"] != spaID {
+		t.Fatalf("introspect user token = %v", got)
+	}
+	if got := introspect(serviceToken).Body; got["active"] != true || got["sub"] != "client:"+serviceID {
+		t.Fatalf("introspect service token = %v", got)
+	}
+	if got := introspect("not-a-token").Body; got["active"] != false || len(got) != 1 {
+		t.Fatalf("introspect garbage = %v, want only active=false", got)
+	}
+
+	// Token hanya bisa dibatalkan oleh klien penerimanya
+	revoke := url.Values{"token": {userToken}}.Encode()
+	app.request(http.MethodPost, "/oauth/revoke", revoke, "", formHeader, basicAuth(serviceID, serviceSecret)).
+		expectStatus(t, fiber.StatusBadRequest)
+	app.request(http.MethodPost, "/oauth/revoke", revoke+"&client_id="+spaID, "", formHeader).expectStatus(t, fiber.StatusOK)
+
+	if got := introspect(userToken).Body; got["active"] != false {
+		t.Fatalf("revoked token still active: %v", got)
+	}
+	app.request(http.MethodGet, "/api/v1/auth/profile", nil, userToken).expectProblem(t, fiber.StatusUnauthorized, "token_revoked")
+}
+
+func TestOAuthClientRegistrationValidation(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.adminToken()
+
+	tests := map[string]model.OAuthClientRequestDTO{
+		"public client credentials": {Name: "svc", GrantTypes: []string{model.GrantClientCredentials}},
+		"missing redirect":          {Name: "spa", GrantTypes: []string{model.GrantAuthorizationCode}},
+		"plain http redirect":       {Name: "spa", GrantTypes: []string{model.GrantAuthorizationCode}, RedirectURIs: []string{"http://partner.example/cb"}},
+		"unknown grant":             {Name: "spa", GrantTypes: []string{"password"}},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			app.request(http.MethodPost, "/api/v1/oauth/clients", req, admin).
+				expectProblem(t, fiber.StatusBadRequest, "validation_failed")
+		})
+	}
+
+	user := app.tokenFor(app.createUser("user@mail.com"))
+	app.request(http.MethodGet, "/api/v1/oauth/clients", nil, user).expectProblem(t, fiber.StatusForbidden, "forbidden")
+}
@@ -0,0 +1,137 @@
+package router_test
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestOIDCDiscovery(t *testing.T) {
+	app := newTestApp(t, func(d *router.Dependencies) { d.Config.OAuth.Issuer = "https://auth.example.com/" })
+
+	resp := app.request(http.MethodGet, "/.well-known/openid-configuration", nil, "")
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.Body["issuer"] != "https://auth.example.com" ||
+		resp.Body["token_endpoint"] != "https://auth.example.com/oauth/token" ||
+		resp.Body["jwks_uri"] != "https://auth.example.com/.well-known/jwks.json" ||
+		resp.Body["userinfo_endpoint"] != "https://auth.example.com/userinfo" {
+		t.Fatalf("discovery = %v", resp.Body)
+	}
+}
+
+// publicKey membaca kunci RSA pertama dari JWKS server.
+func (a *testApp) publicKey() (string, *rsa.PublicKey) {
+	a.t.Helper()
+
+	resp := a.request(http.MethodGet, "/.well-known/jwks.json", nil, "")
+	resp.expectStatus(a.t, fiber.StatusOK)
+	keys, _ := resp.Body["keys"].([]interface{})
+	if len(keys) != 1 {
+		a.t.Fatalf("jwks = %v", resp.Body)
+	}
+	key := keys[0].(map[string]interface{})
+
+	decode := func(member string) *big.Int {
+		raw, err := base64.RawURLEncoding.DecodeString(key[member].(string))
+		if err != nil {
+			a.t.Fatalf("decode %s: %v", member, err)
+		}
+		return new(big.Int).SetBytes(raw)
+	}
+	return key["kid"].(string), &rsa.PublicKey{N: decode("n"), E: int(decode("e").Int64())}
+}
+
+func TestOIDCIDTokenAndUserInfo(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com", func(u *model.User) { u.PhoneNumber = "08123456789" })
+	clientID, _ := app.registerClient(app.adminToken(), model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"openid", "profile", "email", "phone"},
+	})
+
+	params := authorizeParams(clientID)
+	params.Set("scope", "openid profile email")
+	params.Set("nonce", "n-0S6_WzA2Mj")
+	code := app.approve(params, user.Email, testPassword).Query().Get("code")
+
+	resp := app.request(http.MethodPost, "/oauth/token", url.Values{
+		"grant_type": {model.GrantAuthorizationCode}, "code": {code}, "redirect_uri": {testRedirectURI},
+		"code_verifier": {testVerifier}, "client_id": {clientID},
+	}.Encode(), "", formHeader)
+	resp.expectStatus(t, fiber.StatusOK)
+	idToken, _ := resp.Body["id_token"].(string)
+	accessToken := resp.Body["access_token"].(string)
+
+	// ID token bisa diverifikasi hanya dengan kunci publik dari JWKS
+	kid, key := app.publicKey()
+	parsed, err := jwt.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 || token.Header["kid"] != kid {
+			t.Fatalf("id token header = %v", token.Header)
+		}
+		return key, nil
+	})
+	if err != nil {
+		t.Fatalf("verify id token: %v", err)
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	if claims["iss"] != app.config.OAuth.Issuer || claims["aud"] != clientID || claims["nonce"] != "n-0S6_WzA2Mj" ||
+		claims["sub"] != "1" || claims["email"] != user.Email || claims["name"] != user.Fullname {
+		t.Fatalf("id token claims = %v", claims)
+	}
+	if _, ok := claims["phone_number"]; ok {
+		t.Error("id token contains phone_number without the phone scope")
+	}
+
+	// userinfo menyaring claim dengan scope yang sama
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		info := app.request(method, "/userinfo", nil, accessToken)
+		info.expectStatus(t, fiber.StatusOK)
+		if info.Body["sub"] != "1" || info.Body["email"] != user.Email || info.Body["name"] != user.Fullname {
+			t.Fatalf("%s userinfo = %v", method, info.Body)
+		}
+		if _, ok := info.Body["phone_number"]; ok {
+			t.Errorf("%s userinfo contains phone_number without the phone scope", method)
+		}
+	}
+}
+
+func TestOIDCUserInfoRequiresOpenIDScope(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	clientID, _ := app.registerClient(app.adminToken(), model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{"profile"},
+	})
+
+	code := app.authorize(clientID, user.Email, testPassword).Query().Get("code")
+	resp := app.request(http.MethodPost, "/oauth/token", url.Values{
+		"grant_type": {model.GrantAuthorizationCode}, "code": {code}, "redirect_uri": {testRedirectURI},
+		"code_verifier": {testVerifier}, "client_id": {clientID},
+	}.Encode(), "", formHeader)
+	resp.expectStatus(t, fiber.StatusOK)
+	if _, ok := resp.Body["id_token"]; ok {
+		t.Fatal("id_token issued without the openid scope")
+	}
+
+	for _, token := range []string{resp.Body["access_token"].(string), app.tokenFor(user)} {
+		info := app.request(http.MethodGet, "/userinfo", nil, token)
+		info.expectProblem(t, fiber.StatusForbidden, "insufficient_scope")
+		if info.Header.Get(fiber.HeaderWWWAuthenticate) == "" {
+			t.Error("missing WWW-Authenticate on insufficient_scope")
+		}
+	}
+	app.request(http.MethodGet, "/userinfo", nil, "").expectProblem(t, fiber.StatusUnauthorized, "token_missing")
+}
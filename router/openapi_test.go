@@ -0,0 +1,204 @@
+package router_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/openapi"
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/utils"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// TestOpenAPIMatchesRoutes gagal jika rute ditambah, diubah, atau dihapus tanpa memperbarui
+// deskripsi operasi di package openapi, atau sebaliknya.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	routes := newTestApp(t).app.GetRoutes(true)
+
+	if missing := openapi.Undocumented(routes); len(missing) > 0 {
+		t.Errorf("routes without an OpenAPI operation: %v", missing)
+	}
+	if stale := openapi.Unrouted(routes); len(stale) > 0 {
+		t.Errorf("OpenAPI operations without a route: %v", stale)
+	}
+}
+
+// TestOpenAPIAccessMatchesMiddleware memanggil setiap operasi yang terdokumentasi untuk
+// memastikan auth, admin, dan scopes di dokumen sesuai dengan middleware yang terpasang.
+func TestOpenAPIAccessMatchesMiddleware(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+
+	// Setiap request memakai token baru karena sebagian rute (mis. logout) membatalkan tokennya
+	probes := 0
+	tokenFor := func(u model.User, scopes ...string) string {
+		probes++
+		token, err := utils.GenerateToken(u, app.config.Auth.JWTSecret, time.Hour, jwt.MapClaims{
+			"scope": strings.Join(scopes, " "), "jti": strconv.Itoa(probes),
+		})
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		return token
+	}
+
+	// Parameter path diisi nilai yang tidak ada agar handler tidak mengubah data
+	params := strings.NewReplacer("{id}", "999", "{provider}", "unknown")
+	var keys []string
+	for _, route := range app.app.GetRoutes(true) {
+		if key := openapi.Key(route.Method, route.Path); route.Method != http.MethodHead && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		access, ok := openapi.AccessOf(key)
+		if !ok {
+			continue
+		}
+		t.Run(key, func(t *testing.T) {
+			method, path, _ := strings.Cut(key, " ")
+			code := func(token string) string {
+				return fmt.Sprint(app.request(method, params.Replace(path), nil, token).Body["code"])
+			}
+
+			if got := code(""); access.Auth != (got == apperror.CodeTokenMissing) {
+				t.Fatalf("documented auth = %v, request without token got %s", access.Auth, got)
+			}
+			if !access.Auth {
+				return
+			}
+
+			if got := code(tokenFor(user, model.APIScopes...)); access.Admin != (got == apperror.CodeForbidden) {
+				t.Errorf("documented admin = %v, non-admin token got %s", access.Admin, got)
+			}
+
+			// Scope yang terdokumentasi harus cukup, dan setiap scope itu memang diperiksa
+			required := access.Scopes
+			if access.RoleScopes {
+				required = model.RoleScopes(model.RoleAdmin)
+				if got := code(tokenFor(user, model.RoleScopes(model.RoleUser)...)); got == apperror.CodeInsufficientScope {
+					t.Errorf("scopes for role %s are not enough", model.RoleUser)
+				}
+			}
+			if got := code(tokenFor(admin, append([]string{"openid"}, required...)...)); got == apperror.CodeInsufficientScope {
+				t.Errorf("documented scopes %v are not enough", required)
+			}
+			for _, scope := range required {
+				others := slices.DeleteFunc(slices.Clone(model.APIScopes), func(s string) bool { return s == scope })
+				if got := code(tokenFor(admin, others...)); got != apperror.CodeInsufficientScope {
+					t.Errorf("token without documented scope %s got %s", scope, got)
+				}
+			}
+			if len(required) == 0 {
+				if got := code(tokenFor(admin, "openid")); got == apperror.CodeInsufficientScope {
+					t.Error("route requires a scope that is not documented")
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	resp := newTestApp(t).request(http.MethodGet, "/openapi.json", nil, "")
+	resp.expectStatus(t, http.StatusOK)
+
+	if resp.Body["openapi"] != "3.1.0" {
+		t.Errorf("openapi = %v", resp.Body["openapi"])
+	}
+
+	paths, _ := resp.Body["paths"].(map[string]interface{})
+	user, _ := paths["/api/v1/users/{id}"].(map[string]interface{})
+	for _, method := range []string{"get", "put", "patch", "delete"} {
+		if _, ok := user[method]; !ok {
+			t.Errorf("/api/v1/users/{id} has no %s operation", method)
+		}
+	}
+	if _, ok := paths["/metrics"]; ok {
+		t.Error("hidden route /metrics is documented")
+	}
+
+	login, _ := paths["/api/v1/auth/login"].(map[string]interface{})["post"].(map[string]interface{})
+	if _, ok := login["security"]; ok {
+		t.Error("login must not require a bearer token")
+	}
+	profile, _ := paths["/api/v1/auth/profile"].(map[string]interface{})["get"].(map[string]interface{})
+	if _, ok := profile["security"]; !ok {
+		t.Error("profile must require a bearer token")
+	}
+
+	components, _ := resp.Body["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	for _, name := range []string{"UserRequestDTO", "UserResponseDTO", "AuthenticationRequest", "Problem", "Envelope"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+
+	request, _ := schemas["AuthenticationRequest"].(map[string]interface{})
+	properties, _ := request["properties"].(map[string]interface{})
+	email, _ := properties["email"].(map[string]interface{})
+	if email["format"] != "email" {
+		t.Errorf("AuthenticationRequest.email = %v, want format email", email)
+	}
+	response, _ := schemas["UserResponseDTO"].(map[string]interface{})
+	if _, leaked := response["properties"].(map[string]interface{})["password_hash"]; leaked {
+		t.Error("UserResponseDTO exposes password_hash")
+	}
+}
+
+func TestDocsPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		docs      openapi.DocsConfig
+		want, not []string
+	}{
+		{"built-in", openapi.DocsConfig{}, []string{"/openapi.json"}, []string{"<script src="}},
+		{"swagger ui", openapi.DocsConfig{
+			SwaggerUIURL:    "https://cdn.example.com/swagger-ui/",
+			ScriptIntegrity: "sha384-c2NyaXB0",
+			StyleIntegrity:  "sha384-c3R5bGU=",
+		}, []string{
+			`src="https://cdn.example.com/swagger-ui/swagger-ui-bundle.js" integrity="sha384-c2NyaXB0"`,
+			`href="https://cdn.example.com/swagger-ui/swagger-ui.css" integrity="sha384-c3R5bGU="`,
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(deps *router.Dependencies) { deps.Config.Docs = tt.docs })
+			resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil), -1)
+			if err != nil {
+				t.Fatalf("GET /docs: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("GET /docs = %d %q", resp.StatusCode, body)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("page does not contain %s", want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(string(body), not) {
+					t.Errorf("page contains %s", not)
+				}
+			}
+		})
+	}
+}
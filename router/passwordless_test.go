@@ -0,0 +1,253 @@
+package router_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/mailer"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordingMailer menyimpan email yang dikirim alih-alih mengirimnya.
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *recordingMailer) sent() []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mailer.Message(nil), m.messages...)
+}
+
+// last mengembalikan email terakhir yang dikirim ke alamat to.
+func (m *recordingMailer) last(t *testing.T, to string) mailer.Message {
+	t.Helper()
+
+	sent := m.sent()
+	for i := len(sent) - 1; i >= 0; i-- {
+		if sent[i].To == to {
+			return sent[i]
+		}
+	}
+	t.Fatalf("no email sent to %s", to)
+	return mailer.Message{}
+}
+
+func withMailer(mail mailer.Mailer) func(*router.Dependencies) {
+	return func(deps *router.Dependencies) { deps.Mailer = mail }
+}
+
+var loginCode = regexp.MustCompile(`\b\d{6}\b`)
+
+// requestCode meminta kode login dan mengembalikan challenge_id, device_secret, dan kode dari email.
+func (a *testApp) requestCode(mail *recordingMailer, email string) (challengeID, device, code string) {
+	a.t.Helper()
+
+	resp := a.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{"email": email, "method": "code"}, "")
+	resp.expectStatus(a.t, fiber.StatusAccepted)
+	data := resp.data(a.t)
+	code = loginCode.FindString(mail.last(a.t, email).Text)
+	if code == "" {
+		a.t.Fatalf("no code in email %q", mail.last(a.t, email).Text)
+	}
+	return data["challenge_id"].(string), data["device_secret"].(string), code
+}
+
+func TestPasswordlessCodeLogin(t *testing.T) {
+	mail := &recordingMailer{}
+	app := newTestApp(t, withMailer(mail))
+	user := app.createUser("user@mail.com")
+
+	challengeID, device, code := app.requestCode(mail, user.Email)
+	verify := func(secret, device string) testResponse {
+		return app.request(fiber.MethodPost, "/api/v1/auth/passwordless/verify", map[string]string{
+			"challenge_id": challengeID, "secret": secret, "device_secret": device,
+		}, "")
+	}
+
+	// Perangkat lain tidak bisa memakai kode meskipun kodenya benar
+	verify(code, "other-device").expectProblem(t, fiber.StatusForbidden, apperror.CodeDeviceMismatch)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	resp := verify(wrong, device)
+	resp.expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidCode)
+	if resp.Body["attempts_remaining"] != float64(app.config.Passwordless.MaxAttempts-1) {
+		t.Errorf("attempts_remaining = %v", resp.Body["attempts_remaining"])
+	}
+
+	resp = verify(code, device)
+	resp.expectStatus(t, fiber.StatusOK)
+	token, _ := resp.data(t)["token"].(string)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token).expectStatus(t, fiber.StatusOK)
+
+	// Kode hanya berlaku sekali
+	verify(code, device).expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidChallenge)
+}
+
+func TestPasswordlessMagicLink(t *testing.T) {
+	mail := &recordingMailer{}
+	app := newTestApp(t, withMailer(mail))
+	user := app.createUser("user@mail.com")
+
+	resp := app.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{"email": "USER@mail.com"}, "")
+	resp.expectStatus(t, fiber.StatusAccepted)
+	if method := resp.data(t)["method"]; method != model.LoginMethodLink {
+		t.Errorf("method = %v, want link", method)
+	}
+	var cookie string
+	for _, raw := range resp.Header.Values(fiber.HeaderSetCookie) {
+		if pair, attrs, _ := strings.Cut(raw, ";"); strings.HasPrefix(pair, "login_device=") {
+			cookie = pair
+			if !strings.Contains(strings.ToLower(attrs), "httponly") {
+				t.Errorf("device cookie is not HttpOnly: %s", raw)
+			}
+		}
+	}
+	if cookie == "" {
+		t.Fatalf("no login_device cookie in %v", resp.Header)
+	}
+
+	text := mail.last(t, user.Email).Text
+	start := strings.Index(text, "http://")
+	if start < 0 {
+		t.Fatalf("no link in email %q", text)
+	}
+	link, err := url.Parse(strings.Fields(text[start:])[0])
+	if err != nil || link.Path != "/api/v1/auth/passwordless/verify" {
+		t.Fatalf("link = %v, %v", link, err)
+	}
+
+	// Link yang dibuka di browser lain (tanpa cookie perangkat) ditolak
+	path := link.RequestURI()
+	app.request(fiber.MethodGet, path, nil, "").expectProblem(t, fiber.StatusForbidden, apperror.CodeDeviceMismatch)
+
+	resp = app.request(fiber.MethodGet, path, nil, "", header{fiber.HeaderCookie, cookie})
+	resp.expectStatus(t, fiber.StatusOK)
+	if token, _ := resp.data(t)["token"].(string); token == "" {
+		t.Fatalf("no token in %v", resp.Body)
+	}
+	app.request(fiber.MethodGet, path, nil, "", header{fiber.HeaderCookie, cookie}).
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidChallenge)
+}
+
+func TestPasswordlessAttemptLimit(t *testing.T) {
+	mail := &recordingMailer{}
+	app := newTestApp(t, withMailer(mail), func(deps *router.Dependencies) {
+		deps.Config.Passwordless.MaxAttempts = 2
+		// Jeda per email diuji di TestPasswordlessRequestThrottle
+		deps.Config.Passwordless.Cooldown = 0
+	})
+	user := app.createUser("user@mail.com")
+
+	verify := func(challengeID, secret, device string) testResponse {
+		return app.request(fiber.MethodPost, "/api/v1/auth/passwordless/verify", map[string]string{
+			"challenge_id": challengeID, "secret": secret, "device_secret": device,
+		}, "")
+	}
+	wrongFor := func(code string) string {
+		if code == "000000" {
+			return "111111"
+		}
+		return "000000"
+	}
+
+	victim, victimDevice, victimCode := app.requestCode(mail, user.Email)
+	// Permintaan dari perangkat lain tidak membatalkan challenge korban
+	attacker, attackerDevice, attackerCode := app.requestCode(mail, user.Email)
+
+	// Tebakan dari perangkat lain ditolak tanpa memakai jatah percobaan korban
+	for i := 0; i < 3; i++ {
+		verify(victim, wrongFor(victimCode), attackerDevice).expectProblem(t, fiber.StatusForbidden, apperror.CodeDeviceMismatch)
+	}
+
+	// Jatah percobaan habis per challenge; setelah itu kode yang benar pun ditolak
+	verify(attacker, wrongFor(attackerCode), attackerDevice).expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidCode)
+	verify(attacker, wrongFor(attackerCode), attackerDevice).expectProblem(t, fiber.StatusTooManyRequests, apperror.CodeTooManyAttempts)
+	verify(attacker, attackerCode, attackerDevice).expectProblem(t, fiber.StatusTooManyRequests, apperror.CodeTooManyAttempts)
+
+	// Challenge yang kehabisan jatah tidak memblokir challenge korban maupun permintaan baru
+	resp := verify(victim, wrongFor(victimCode), victimDevice)
+	resp.expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidCode)
+	if resp.Body["attempts_remaining"] != float64(1) {
+		t.Errorf("attempts_remaining = %v, want 1", resp.Body["attempts_remaining"])
+	}
+	verify(victim, victimCode, victimDevice).expectStatus(t, fiber.StatusOK)
+	app.requestCode(mail, user.Email)
+
+	// Meminta ulang dari perangkat yang sama hanya menggantikan challenge perangkat itu
+	resp = app.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{
+		"email": user.Email, "method": "code", "device_secret": attackerDevice,
+	}, "")
+	resp.expectStatus(t, fiber.StatusAccepted)
+	if device := resp.data(t)["device_secret"]; device != attackerDevice {
+		t.Errorf("device_secret = %v, want the requesting device's secret", device)
+	}
+	verify(attacker, attackerCode, attackerDevice).expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidChallenge)
+}
+
+func TestPasswordlessRequestThrottle(t *testing.T) {
+	mail := &recordingMailer{}
+	app := newTestApp(t, withMailer(mail), func(deps *router.Dependencies) {
+		deps.Config.Passwordless.IPLimit = 4
+	})
+	user := app.createUser("user@mail.com")
+	request := func(email string) testResponse {
+		return app.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{"email": email}, "")
+	}
+
+	request(user.Email).expectStatus(t, fiber.StatusAccepted)
+	// Jeda berlaku per IP dan email, termasuk variasi huruf besar dan email yang tidak terdaftar
+	resp := request("USER@mail.com")
+	resp.expectProblem(t, fiber.StatusTooManyRequests, apperror.CodeRateLimited)
+	if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Error("rate limited response has no Retry-After header")
+	}
+	request("nobody@mail.com").expectStatus(t, fiber.StatusAccepted)
+	request("nobody@mail.com").expectProblem(t, fiber.StatusTooManyRequests, apperror.CodeRateLimited)
+
+	// Batas per IP berlaku untuk semua email
+	request("other@mail.com").expectProblem(t, fiber.StatusTooManyRequests, apperror.CodeRateLimited)
+	if sent := len(mail.sent()); sent != 1 {
+		t.Errorf("sent %d emails, want 1", sent)
+	}
+}
+
+func TestPasswordlessUnknownEmail(t *testing.T) {
+	mail := &recordingMailer{}
+	app := newTestApp(t, withMailer(mail))
+	app.createUser("suspended@mail.com", func(u *model.User) { u.Status = model.StatusSuspended })
+
+	// Respons sama seperti email terdaftar, tetapi tidak ada email yang dikirim
+	for _, email := range []string{"nobody@mail.com", "suspended@mail.com"} {
+		resp := app.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{"email": email}, "")
+		resp.expectStatus(t, fiber.StatusAccepted)
+		if resp.data(t)["challenge_id"] == "" {
+			t.Errorf("%s: no challenge_id", email)
+		}
+	}
+	if sent := mail.sent(); len(sent) != 0 {
+		t.Fatalf("sent %d emails, want none", len(sent))
+	}
+
+	app.request(fiber.MethodPost, "/api/v1/auth/passwordless", map[string]string{"email": "user@mail.com", "method": "sms"}, "").
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeValidationFailed)
+}
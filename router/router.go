@@ -1,30 +1,248 @@
 package router
 
 import (
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
 	"go-fiber-user-management/controller"
+	"go-fiber-user-management/health"
+	"go-fiber-user-management/mailer"
+	"go-fiber-user-management/metrics"
 	"go-fiber-user-management/middleware"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/oidc"
+	"go-fiber-user-management/openapi"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/service"
+	"go-fiber-user-management/tracing"
+	"go-fiber-user-management/versioning"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// SetupRoutes menginisialisasi semua rute API.
-func SetupRoutes(app *fiber.App) {
-	api := app.Group("/api") // Grup API utama
+// Dependencies berisi konfigurasi dan repository yang dipakai oleh semua rute.
+type Dependencies struct {
+	Config config.Config
+	Users  repository.UserRepository
+	Tokens repository.TokenRepository
+	OAuth  repository.OAuthRepository
+	// Identities menyimpan tautan akun ke IdP eksternal (login Google, GitHub, dsb.)
+	Identities repository.IdentityRepository
+	// LoginChallenges menyimpan magic link dan kode login tanpa password yang belum dipakai
+	LoginChallenges repository.LoginChallengeRepository
+	// WebAuthn menyimpan passkey pengguna
+	WebAuthn repository.WebAuthnRepository
+	// APITokens menyimpan token akses pribadi untuk otomasi
+	APITokens repository.APITokenRepository
+	// Audit menyimpan audit trail impersonation oleh admin
+	Audit repository.AuditRepository
+
+	// Mailer mengirim email login tanpa password; nil berarti email hanya ditulis ke log.
+	Mailer mailer.Mailer
+
+	// Signer menandatangani ID token OpenID Connect; nil berarti kunci sementara per proses.
+	Signer *oidc.Signer
+
+	// HealthChecks adalah pemeriksaan tambahan untuk /readyz (mis. database, migrasi);
+	// pemeriksaan kunci JWT selalu ditambahkan oleh router.
+	HealthChecks []health.Check
+}
+
+// New membuat aplikasi Fiber lengkap dengan error handler dan semua rute.
+func New(deps Dependencies) *fiber.App {
+	// Semua error dari handler diubah menjadi application/problem+json
+	app := fiber.New(fiber.Config{
+		ErrorHandler: apperror.Handler,
+		// Banner startup diganti log terstruktur di main
+		DisableStartupMessage: true,
+	})
+
+	// Path /api tanpa versi diteruskan ke versi tujuan sebelum middleware lain berjalan.
+	// LegacyDeprecated dan LegacySunset sudah divalidasi oleh Config.Validate.
+	deprecated, _ := deps.Config.API.Deprecated()
+	sunset, _ := deps.Config.API.Sunset()
+	versions := versioning.New("/api", deps.Config.API.DefaultVersion, versioning.Policy{
+		Deprecated: deprecated,
+		Sunset:     sunset,
+	})
+	app.Use("/api", versions.Negotiate())
+
+	// Request ID dan span request lebih dulu agar access log dan log lain membawa
+	// request_id dan trace_id; metrik request dicatat untuk semua rute, termasuk yang tidak ditemukan
+	app.Use(middleware.RequestID(), tracing.Middleware(), middleware.AccessLog(), metrics.Middleware())
+	app.Get("/metrics", metrics.Handler())
+
+	// Dokumentasi API dibentuk dari rute yang terdaftar (lihat package openapi)
+	app.Get("/openapi.json", openapi.Handler())
+	app.Get("/docs", openapi.Docs(deps.Config.Docs))
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hello this is JWT Task App")
+	})
+
+	// route authentication & task
+	SetupRoutes(app, versions, deps)
+
+	return app
+}
+
+// SetupRoutes menginisialisasi semua rute API. Setiap versi API didaftarkan lewat
+// versions.Version dengan controller dan DTO sendiri.
+func SetupRoutes(app *fiber.App, versions *versioning.API, deps Dependencies) {
+	authService := service.NewAuthService(deps.Users, deps.Tokens, deps.Config.Auth)
+	apiTokenService := service.NewAPITokenService(deps.APITokens, deps.Users, deps.Config.APITokens)
+	authService.UseAPITokens(apiTokenService)
+	impersonationService := service.NewImpersonationService(deps.Audit, deps.Users, deps.Tokens, deps.Config.Auth,
+		deps.Config.Impersonation)
+	authService.UseImpersonation(impersonationService)
+	userService := service.NewUserService(deps.Users)
+	signer := deps.Signer
+	if signer == nil {
+		signer = oidc.Ephemeral()
+	}
+	oauthService := service.NewOAuthService(deps.OAuth, deps.Users, deps.Tokens, deps.Config.Auth, deps.Config.OAuth, signer)
+	identityService := service.NewIdentityService(deps.Identities, deps.Users, deps.Config.Auth, deps.Config.Identity,
+		deps.Config.OAuth.Issuer, nil)
+
+	mail := deps.Mailer
+	if mail == nil {
+		mail = mailer.Log{}
+	}
+	passwordlessService := service.NewPasswordlessService(deps.LoginChallenges, deps.Users, mail, deps.Config.Auth,
+		deps.Config.Passwordless, deps.Config.OAuth.Issuer)
+	webauthnService := service.NewWebAuthnService(deps.WebAuthn, deps.LoginChallenges, deps.Users, deps.Config.Auth,
+		deps.Config.WebAuthn, deps.Config.OAuth.Issuer)
+	if deps.Config.WebAuthn.RequireForPasswordLogin {
+		authService.UseSecondFactor(webauthnService.SecondFactor)
+	}
+
+	authController := controller.NewAuthController(authService)
+	userController := controller.NewUserController(userService, deps.Config.Server.RequireIfMatch)
+	oauthController := controller.NewOAuthController(oauthService, authService)
+	oidcController := controller.NewOIDCController(authService, deps.Config.OAuth.Issuer, signer)
+	identityController := controller.NewIdentityController(identityService, authService, deps.Config.OAuth.Issuer)
+	passwordlessController := controller.NewPasswordlessController(passwordlessService, deps.Config.Passwordless.TTL,
+		deps.Config.OAuth.Issuer)
+	webauthnController := controller.NewWebAuthnController(webauthnService, authService)
+	apiTokenController := controller.NewAPITokenController(apiTokenService, authService)
+	impersonationController := controller.NewImpersonationController(impersonationService)
+	jwtAuth := middleware.JWTAuthorization(authService)
+	// Operasi yang bisa memperpanjang akses tidak boleh dilakukan dengan token akses pribadi
+	sessionOnly := middleware.RejectAPITokens()
+	// Admin yang bertindak sebagai pengguna tidak boleh mengubah kredensial pengguna tersebut
+	notImpersonated := middleware.RejectImpersonation()
+	adminOnly := middleware.RequireRole(model.RoleAdmin)
+
+	// Scope token membatasi akses di atas role; token dengan scope sempit (mis. hanya users:read)
+	// tidak bisa mengubah data atau menerbitkan kredensial baru
+	readProfile := middleware.RequireScope(model.ScopeProfileRead)
+	writeProfile := middleware.RequireScope(model.ScopeProfileWrite)
+	readUsers := middleware.RequireScope(model.ScopeUsersRead)
+	writeUsers := middleware.RequireScope(model.ScopeUsersWrite)
+	// Scope penuh bergantung pada role: admin juga memerlukan users:read dan users:write
+	fullScope := middleware.RequireRoleScopes()
+
+	checks := append([]health.Check{health.SigningKey(deps.Config.Auth.JWTSecret)}, deps.HealthChecks...)
+	healthController := controller.NewHealthController(health.NewChecker(health.DefaultTimeout, checks...))
+
+	// Setiap handler controller mendapat span sendiri di bawah span request
+	traced := tracing.Handler
+
+	// Probe untuk orchestrator, di luar /api dan tanpa autentikasi
+	app.Get("/healthz", healthController.Liveness)
+	app.Get("/readyz", healthController.Readiness)
+
+	// Server otorisasi OAuth2, di luar /api karena mengikuti RFC 6749/7662/7009, bukan versi API
+	oauth := app.Group("/oauth")
+	oauth.Get("/authorize", traced(oauthController.Authorize)) // Halaman consent
+	oauth.Post("/authorize", traced(oauthController.Decide))
+	oauth.Post("/token", traced(oauthController.Token))
+	oauth.Post("/introspect", traced(oauthController.Introspect))
+	oauth.Post("/revoke", traced(oauthController.Revoke))
+
+	// OpenID Connect di atas server otorisasi
+	app.Get(oidc.DiscoveryPath, traced(oidcController.Discovery))
+	app.Get(oidc.JWKSPath, traced(oidcController.JWKS))
+	app.Get(oidc.UserInfoPath, jwtAuth, traced(oidcController.UserInfo))
+	app.Post(oidc.UserInfoPath, jwtAuth, traced(oidcController.UserInfo))
+
+	// Grup API v1. Versi berikutnya (mis. v2 dengan DTO berbeda) didaftarkan di sini dengan
+	// versions.Version(app, "v2", ...); tandai v1 usang lewat Policy.Deprecated dan Successor.
+	v1 := versions.Version(app, "v1", versioning.Policy{})
+
+	// Laporan kesehatan lengkap hanya untuk admin
+	v1.Get("/health", jwtAuth, adminOnly, traced(healthController.Report))
 
 	// Rute Autentikasi
-	auth := api.Group("/auth")            // Grup untuk rute terkait autentikasi
-	auth.Post("/login", controller.Login) // Rute untuk login pengguna
-	auth.Post("/register", controller.Register)
+	auth := v1.Group("/auth")                         // Grup untuk rute terkait autentikasi
+	auth.Post("/login", traced(authController.Login)) // Rute untuk login pengguna
+	auth.Post("/register", traced(authController.Register))
 	//auth.Post("/forgot-password", controller.ForgotPassword)
 	//auth.Post("/reset-password", controller.ResetPassword)                    // Rute untuk pendaftaran pengguna
-	auth.Get("/profile", middleware.JWTAuthorization, controller.GetUserInfo) // Rute info pengguna yang dilindungi
-	auth.Get("/logout", middleware.JWTAuthorization, controller.Logout)       // Rute info pengguna yang dilindungi
+	auth.Get("/profile", jwtAuth, readProfile, traced(authController.GetUserInfo)) // Rute info pengguna yang dilindungi
+	auth.Get("/logout", jwtAuth, traced(authController.Logout))                    // Rute info pengguna yang dilindungi
+	auth.Post("/impersonation/end", jwtAuth, traced(impersonationController.End))
+	// Token dengan scope lebih sempit, mis. untuk diteruskan ke layanan lain
+	auth.Post("/exchange", jwtAuth, sessionOnly, notImpersonated, traced(authController.Exchange))
+
+	// Login tanpa password lewat magic link atau kode email. Permintaan dibatasi per IP dan per
+	// email agar endpoint ini tidak bisa dipakai membanjiri kotak masuk atau mereset jatah percobaan
+	passwordless := deps.Config.Passwordless
+	auth.Post("/passwordless",
+		middleware.RateLimit(passwordless.IPLimit, passwordless.IPWindow, middleware.ClientIP),
+		middleware.RateLimit(1, passwordless.Cooldown, middleware.ClientEmail),
+		traced(passwordlessController.Request))
+	auth.Post("/passwordless/verify", traced(passwordlessController.Verify))
+	auth.Get("/passwordless/verify", traced(passwordlessController.VerifyLink)) // Tujuan magic link
+
+	// Token akses pribadi untuk otomasi; dikirim sebagai Bearer seperti JWT
+	tokens := auth.Group("/tokens", jwtAuth, sessionOnly, notImpersonated, fullScope)
+	tokens.Post("/", traced(apiTokenController.Create))
+	tokens.Get("/", traced(apiTokenController.List))
+	tokens.Delete("/:id", traced(apiTokenController.Revoke))
+
+	// Passkey (WebAuthn) untuk login tanpa password, faktor kedua, dan pengelolaannya
+	passkeys := auth.Group("/webauthn")
+	passkeys.Post("/register/begin", jwtAuth, sessionOnly, notImpersonated, fullScope,
+		traced(webauthnController.BeginRegistration))
+	passkeys.Post("/register/finish", jwtAuth, sessionOnly, notImpersonated, fullScope,
+		traced(webauthnController.FinishRegistration))
+	passkeys.Post("/login/begin", traced(webauthnController.BeginLogin))
+	passkeys.Post("/login/finish", traced(webauthnController.FinishLogin))
+	passkeys.Get("/credentials", jwtAuth, readProfile, traced(webauthnController.Credentials))
+	passkeys.Patch("/credentials/:id", jwtAuth, notImpersonated, writeProfile, traced(webauthnController.RenameCredential))
+	passkeys.Delete("/credentials/:id", jwtAuth, notImpersonated, writeProfile, traced(webauthnController.DeleteCredential))
+
+	// Login lewat IdP eksternal dan pengelolaan identitas tertaut
+	auth.Get("/oauth/providers", traced(identityController.Providers))
+	auth.Get("/oauth/:provider/start", traced(identityController.Start))
+	auth.Get("/oauth/:provider/callback", traced(identityController.Callback))
+	auth.Post("/oauth/:provider/link", jwtAuth, sessionOnly, notImpersonated, fullScope, traced(identityController.Link))
+	auth.Get("/identities", jwtAuth, readProfile, traced(identityController.Identities))
+	auth.Delete("/identities/:provider", jwtAuth, notImpersonated, writeProfile, traced(identityController.Unlink))
 
 	// Route user CRUD management
-	user := api.Group("/users")
-	user.Get("/", middleware.JWTAuthorization, controller.GetUsers)                // Rute list pengguna oleh admin
-	user.Get("/:id", middleware.JWTAuthorization, controller.GetDetailUser) // Rute untuk info pengguna oleh admin
-	user.Post("/", middleware.JWTAuthorization, controller.CreateUser)
-	user.Put("/:id", middleware.JWTAuthorization, controller.UpdateUser)    // Rute untuk edit pengguna oleh admin
-	user.Delete("/:id", middleware.JWTAuthorization, controller.DeleteUser) // Rute untuk hapus pengguna oleh admin)
+	user := v1.Group("/users")
+	user.Get("/", jwtAuth, adminOnly, readUsers, traced(userController.GetUsers))         // Rute list pengguna oleh admin
+	user.Get("/:id", jwtAuth, adminOnly, readUsers, traced(userController.GetDetailUser)) // Rute untuk info pengguna oleh admin
+	user.Post("/", jwtAuth, adminOnly, writeUsers, traced(userController.CreateUser))
+	user.Post("/batch", jwtAuth, adminOnly, writeUsers, traced(userController.BatchUsers)) // Rute untuk operasi massal oleh admin
+	user.Put("/:id", jwtAuth, adminOnly, writeUsers, traced(userController.UpdateUser))    // Rute untuk edit pengguna oleh admin
+	user.Patch("/:id", jwtAuth, adminOnly, writeUsers, traced(userController.PatchUser))   // Rute untuk edit sebagian data pengguna oleh admin
+	user.Delete("/:id", jwtAuth, adminOnly, writeUsers, traced(userController.DeleteUser)) // Rute untuk hapus pengguna oleh admin)
+
+	// Rute perubahan status akun oleh admin
+	user.Post("/:id/suspend", jwtAuth, adminOnly, writeUsers, traced(userController.SuspendUser))
+	user.Post("/:id/lock", jwtAuth, adminOnly, writeUsers, traced(userController.LockUser))
+	user.Post("/:id/reactivate", jwtAuth, adminOnly, writeUsers, traced(userController.ReactivateUser))
+	user.Post("/:id/disable", jwtAuth, adminOnly, writeUsers, traced(userController.DisableUser))
+
+	// Impersonation oleh admin dukungan; setiap sesi tercatat di audit trail
+	user.Post("/:id/impersonate", jwtAuth, sessionOnly, notImpersonated, fullScope, adminOnly,
+		traced(impersonationController.Start))
+	v1.Get("/audit-events", jwtAuth, adminOnly, readUsers, traced(impersonationController.Events))
+
+	// Pendaftaran klien OAuth2 oleh admin
+	clients := v1.Group("/oauth/clients", jwtAuth, adminOnly)
+	clients.Get("/", traced(oauthController.ListClients))
+	clients.Post("/", traced(oauthController.RegisterClient))
 }
@@ -0,0 +1,263 @@
+package router_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+// login masuk dengan password dan scope yang diminta, lalu mengembalikan token dan scope-nya.
+func (a *testApp) login(email, scope string) (string, string) {
+	a.t.Helper()
+
+	resp := a.request(fiber.MethodPost, "/api/v1/auth/login", map[string]string{
+		"email": email, "password": testPassword, "scope": scope,
+	}, "")
+	resp.expectStatus(a.t, fiber.StatusOK)
+	data := resp.data(a.t)
+	return data["token"].(string), data["scope"].(string)
+}
+
+func TestLoginWithReducedScope(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	target := app.createUser("target@mail.com")
+	userPath := fmt.Sprintf("/api/v1/users/%d", target.ID)
+
+	if _, scope := app.login(admin.Email, ""); scope != model.DefaultScope(model.RoleAdmin) {
+		t.Errorf("default scope = %q, want %q", scope, model.DefaultScope(model.RoleAdmin))
+	}
+
+	token, scope := app.login(admin.Email, model.ScopeUsersRead)
+	if scope != model.ScopeUsersRead {
+		t.Fatalf("scope = %q, want %q", scope, model.ScopeUsersRead)
+	}
+	app.request(fiber.MethodGet, "/api/v1/users", nil, token).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, userPath, nil, token).expectStatus(t, fiber.StatusOK)
+
+	resp := app.request(fiber.MethodDelete, userPath, nil, token)
+	resp.expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+	if challenge := resp.Header.Get(fiber.HeaderWWWAuthenticate); !strings.Contains(challenge, `scope="users:write"`) {
+		t.Errorf("WWW-Authenticate = %q, want required scope", challenge)
+	}
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+
+	// Token dengan scope sempit tidak bisa menerbitkan kredensial dengan akses lebih luas
+	app.request(fiber.MethodPost, "/api/v1/auth/tokens", map[string]interface{}{
+		"name": "escalate", "scopes": []string{model.ScopeUsersWrite},
+	}, token).expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+
+	app.request(fiber.MethodPost, "/api/v1/auth/login", map[string]string{
+		"email": admin.Email, "password": testPassword, "scope": "users:read admin:all",
+	}, "").expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+}
+
+func TestAPITokenScopesAreEnforced(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.adminToken()
+	target := app.createUser("target@mail.com")
+
+	readOnly, _ := app.createAPIToken(admin, map[string]interface{}{
+		"name": "report", "scopes": []string{model.ScopeUsersRead},
+	})
+	app.request(fiber.MethodGet, "/api/v1/users", nil, readOnly).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodDelete, fmt.Sprintf("/api/v1/users/%d", target.ID), nil, readOnly).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+}
+
+func TestDefaultScopeDependsOnRole(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+
+	token, scope := app.login(user.Email, "")
+	if scope != model.DefaultScope(model.RoleUser) {
+		t.Errorf("default scope = %q, want %q", scope, model.DefaultScope(model.RoleUser))
+	}
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, token).expectStatus(t, fiber.StatusOK)
+
+	// Pengguna biasa tidak bisa meminta scope admin, lewat login maupun API token
+	app.request(fiber.MethodPost, "/api/v1/auth/login", map[string]string{
+		"email": user.Email, "password": testPassword, "scope": model.ScopeUsersRead,
+	}, "").expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+	app.request(fiber.MethodPost, "/api/v1/auth/tokens", map[string]interface{}{
+		"name": "escalate", "scopes": []string{model.ScopeUsersRead},
+	}, token).expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+}
+
+func TestTokenWithoutScopeClaimIsLimitedToProfile(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	target := app.createUser("target@mail.com")
+
+	// Token yang diterbitkan sebelum klaim scope diperkenalkan
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": admin.ID, "email": admin.Email, "issued_at": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(app.config.Auth.JWTSecret))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, legacy).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodDelete, fmt.Sprintf("/api/v1/users/%d", target.ID), nil, legacy, header{"If-Match", `"1"`}).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+}
+
+func TestExchangeForReducedScope(t *testing.T) {
+	app := newTestApp(t)
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	full, _ := app.login(admin.Email, "")
+
+	exchange := func(token, scope string) testResponse {
+		return app.request(fiber.MethodPost, "/api/v1/auth/exchange", map[string]string{"scope": scope}, token)
+	}
+
+	resp := exchange(full, model.ScopeUsersRead)
+	resp.expectStatus(t, fiber.StatusOK)
+	data := resp.data(t)
+	if data["scope"] != model.ScopeUsersRead {
+		t.Fatalf("scope = %v, want %s", data["scope"], model.ScopeUsersRead)
+	}
+	reduced := data["token"].(string)
+	app.request(fiber.MethodGet, "/api/v1/users", nil, reduced).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, reduced).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+
+	// Token hasil penukaran tidak bisa ditukar lagi dengan scope yang lebih luas
+	exchange(reduced, model.ScopeUsersWrite).expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+	exchange(full, "admin:all").expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+}
+
+func TestOAuthTokenExchangeWithReducedScope(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	clientID, _ := app.registerClient(app.adminToken(), model.OAuthClientRequestDTO{
+		Name:         "Partner SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{model.ScopeProfileRead, model.ScopeProfileWrite},
+	})
+
+	exchange := func(scope string) testResponse {
+		form := authorizeParams(clientID)
+		form.Set("scope", model.ScopeProfileRead+" "+model.ScopeProfileWrite)
+		code := app.approve(form, user.Email, testPassword).Query().Get("code")
+		return app.request(http.MethodPost, "/oauth/token", url.Values{
+			"grant_type":    {model.GrantAuthorizationCode},
+			"code":          {code},
+			"redirect_uri":  {testRedirectURI},
+			"code_verifier": {testVerifier},
+			"client_id":     {clientID},
+			"scope":         {scope},
+		}.Encode(), "", formHeader)
+	}
+
+	resp := exchange(model.ScopeProfileRead)
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.Body["scope"] != model.ScopeProfileRead {
+		t.Fatalf("scope = %v, want %s", resp.Body["scope"], model.ScopeProfileRead)
+	}
+	token := resp.Body["access_token"].(string)
+	app.request(http.MethodGet, "/api/v1/auth/profile", nil, token).expectStatus(t, fiber.StatusOK)
+	app.request(http.MethodDelete, passkeyBase+"/credentials/1", nil, token).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeInsufficientScope)
+
+	// Scope yang tidak disetujui pengguna tidak bisa ditambahkan saat penukaran
+	wider := exchange(model.ScopeUsersRead)
+	wider.expectStatus(t, fiber.StatusBadRequest)
+	if wider.Body["error"] != apperror.CodeInvalidScope {
+		t.Errorf("error = %v, want invalid_scope", wider.Body)
+	}
+}
+
+func TestOAuthScopeLimitedByRole(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	clientID, _ := app.registerClient(app.tokenFor(admin), model.OAuthClientRequestDTO{
+		Name:         "Admin console",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode},
+		Scopes:       []string{model.ScopeProfileRead, model.ScopeUsersRead, model.ScopeUsersWrite},
+	})
+
+	// Tanpa scope yang diminta, token memakai scope klien yang tersedia untuk role pengguna
+	grant := func(email, scope string) *url.URL {
+		form := authorizeParams(clientID)
+		form.Set("scope", scope)
+		return app.approve(form, email, testPassword)
+	}
+	redeem := func(location *url.URL) testResponse {
+		return app.request(http.MethodPost, "/oauth/token", url.Values{
+			"grant_type":    {model.GrantAuthorizationCode},
+			"code":          {location.Query().Get("code")},
+			"redirect_uri":  {testRedirectURI},
+			"code_verifier": {testVerifier},
+			"client_id":     {clientID},
+		}.Encode(), "", formHeader)
+	}
+
+	resp := redeem(grant(user.Email, ""))
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.Body["scope"] != model.ScopeProfileRead {
+		t.Fatalf("scope = %v, want %s", resp.Body["scope"], model.ScopeProfileRead)
+	}
+	target := app.createUser("target@mail.com")
+	app.request(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d", target.ID), nil, resp.Body["access_token"].(string)).
+		expectStatus(t, fiber.StatusForbidden)
+
+	if location := grant(user.Email, model.ScopeUsersWrite); location.Query().Get("error") != apperror.CodeInvalidScope {
+		t.Errorf("redirect = %s, want error=invalid_scope", location)
+	}
+
+	resp = redeem(grant(admin.Email, ""))
+	resp.expectStatus(t, fiber.StatusOK)
+	if want := model.ScopeProfileRead + " " + model.ScopeUsersRead + " " + model.ScopeUsersWrite; resp.Body["scope"] != want {
+		t.Errorf("admin scope = %v, want %s", resp.Body["scope"], want)
+	}
+}
+
+func TestUserRoutesRequireAdminRole(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	target := app.createUser("target@mail.com")
+	path := fmt.Sprintf("/api/v1/users/%d", target.ID)
+
+	// Token dengan scope admin milik pengguna biasa tetap ditolak
+	token, err := utils.GenerateToken(user, app.config.Auth.JWTSecret, app.config.Auth.TokenTTL,
+		jwt.MapClaims{"scope": model.ScopeUsersRead + " " + model.ScopeUsersWrite})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	app.request(fiber.MethodDelete, path, nil, token, header{"If-Match", `"1"`}).
+		expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+	app.request(fiber.MethodGet, "/api/v1/users", nil, token).expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+
+	// Admin yang diturunkan kehilangan akses meskipun JWT dan API token-nya masih berlaku
+	admin := app.createUser("admin@mail.com", func(u *model.User) { u.Role = model.RoleAdmin })
+	session := app.tokenFor(admin)
+	apiToken, _ := app.createAPIToken(session, map[string]interface{}{
+		"name": "sync", "scopes": []string{model.ScopeUsersWrite},
+	})
+	admin.Role = model.RoleUser
+	if err := app.users.UpdateVersioned(context.Background(), &admin, "role"); err != nil {
+		t.Fatalf("demote admin: %v", err)
+	}
+	for _, token := range []string{session, apiToken} {
+		app.request(fiber.MethodDelete, path, nil, token, header{"If-Match", `"1"`}).
+			expectProblem(t, fiber.StatusForbidden, apperror.CodeForbidden)
+	}
+	if _, err := app.users.FindByID(context.Background(), target.ID); err != nil {
+		t.Errorf("target was deleted: %v", err)
+	}
+}
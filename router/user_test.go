@@ -0,0 +1,379 @@
+package router_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+)
+
+func TestUserRoutesRequireToken(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	path := fmt.Sprintf("/api/users/%d", user.ID)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/users"},
+		{http.MethodPost, "/api/users"},
+		{http.MethodPost, "/api/users/batch"},
+		{http.MethodGet, path},
+		{http.MethodPut, path},
+		{http.MethodPatch, path},
+		{http.MethodDelete, path},
+		{http.MethodPost, path + "/suspend"},
+		{http.MethodPost, path + "/lock"},
+		{http.MethodPost, path + "/reactivate"},
+		{http.MethodPost, path + "/disable"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			app.request(route.method, route.path, nil, "").
+				expectProblem(t, http.StatusUnauthorized, "token_missing")
+		})
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	app := newTestApp(t)
+	token := app.adminToken()
+	app.createUser("active@mail.com")
+	app.createUser("suspended@mail.com", func(u *model.User) { u.Status = model.StatusSuspended })
+
+	tests := []struct {
+		name  string
+		query string
+		count int
+		code  string
+	}{
+		{name: "all users", query: "", count: 3},
+		{name: "filter active", query: "?status=active", count: 2},
+		{name: "filter several", query: "?status=suspended,disabled", count: 1},
+		{name: "invalid status", query: "?status=unknown", code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.request(http.MethodGet, "/api/users"+tt.query, nil, token)
+			if tt.code != "" {
+				resp.expectProblem(t, http.StatusBadRequest, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, http.StatusOK)
+			users, _ := resp.Body["data"].([]interface{})
+			if len(users) != tt.count {
+				t.Errorf("got %d users, want %d", len(users), tt.count)
+			}
+		})
+	}
+}
+
+func TestGetDetailUser(t *testing.T) {
+	app := newTestApp(t)
+	token := app.adminToken()
+	user := app.createUser("user@mail.com")
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		code   string
+	}{
+		{name: "found", path: fmt.Sprintf("/api/users/%d", user.ID), status: http.StatusOK},
+		{name: "not found", path: "/api/users/999", status: http.StatusNotFound, code: "user_not_found"},
+		{name: "invalid id", path: "/api/users/abc", status: http.StatusBadRequest, code: "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := app.request(http.MethodGet, tt.path, nil, token)
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			if resp.data(t)["email"] != user.Email {
+				t.Errorf("email = %v, want %s", resp.data(t)["email"], user.Email)
+			}
+			if resp.Header.Get("ETag") == "" {
+				t.Error("missing ETag header")
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{
+			name:   "success",
+			body:   map[string]string{"email": "new@mail.com", "password": testPassword, "fullname": "New"},
+			status: http.StatusCreated,
+		},
+		{
+			name:   "duplicate email",
+			body:   map[string]string{"email": "admin@mail.com", "password": testPassword, "fullname": "Dup"},
+			status: http.StatusConflict,
+			code:   "email_exists",
+		},
+		{
+			name:   "missing password",
+			body:   map[string]string{"email": "nopass@mail.com", "fullname": "No Pass"},
+			status: http.StatusBadRequest,
+			code:   "validation_failed",
+		},
+		{
+			name:   "malformed json",
+			body:   "[",
+			status: http.StatusBadRequest,
+			code:   "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			token := app.adminToken()
+
+			resp := app.request(http.MethodPost, "/api/users", tt.body, token)
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+			resp.expectStatus(t, tt.status)
+			if resp.data(t)["email"] != "new@mail.com" {
+				t.Errorf("unexpected user: %v", resp.data(t))
+			}
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	body := map[string]string{"email": "user@mail.com", "fullname": "Renamed"}
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []header
+		status  int
+		code    string
+	}{
+		{name: "matching If-Match", path: "/api/users/2", headers: []header{{"If-Match", `"1"`}}, status: http.StatusOK},
+		{name: "wildcard If-Match", path: "/api/users/2", headers: []header{{"If-Match", "*"}}, status: http.StatusOK},
+		{name: "missing If-Match", path: "/api/users/2", status: http.StatusPreconditionRequired, code: "precondition_required"},
+		{name: "stale If-Match", path: "/api/users/2", headers: []header{{"If-Match", `"7"`}}, status: http.StatusPreconditionFailed, code: "version_conflict"},
+		{name: "not found", path: "/api/users/999", headers: []header{{"If-Match", "*"}}, status: http.StatusNotFound, code: "user_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, requireIfMatch)
+			token := app.adminToken()
+			app.createUser("user@mail.com")
+
+			resp := app.request(http.MethodPut, tt.path, body, token, tt.headers...)
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			if resp.data(t)["fullname"] != "Renamed" {
+				t.Errorf("fullname = %v, want Renamed", resp.data(t)["fullname"])
+			}
+			if resp.Header.Get("ETag") != `"2"` {
+				t.Errorf("ETag = %q, want \"2\"", resp.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+// requireIfMatch mengaktifkan server.require_if_match, yang nonaktif secara default.
+func requireIfMatch(deps *router.Dependencies) {
+	deps.Config.Server.RequireIfMatch = true
+}
+
+func TestUpdateUserIfMatchOptionalByDefault(t *testing.T) {
+	app := newTestApp(t)
+	token := app.adminToken()
+	user := app.createUser("user@mail.com")
+
+	app.request(http.MethodPut, fmt.Sprintf("/api/users/%d", user.ID),
+		map[string]string{"email": user.Email, "fullname": "No Precondition"}, token).
+		expectStatus(t, http.StatusOK)
+}
+
+func TestPatchUser(t *testing.T) {
+	app := newTestApp(t)
+	token := app.adminToken()
+	user := app.createUser("user@mail.com", func(u *model.User) { u.Address = "Jayapura" })
+	path := fmt.Sprintf("/api/users/%d", user.ID)
+
+	resp := app.request(http.MethodPatch, path, map[string]string{"fullname": "Patched"}, token, header{"If-Match", `"1"`})
+	resp.expectStatus(t, http.StatusOK)
+	data := resp.data(t)
+	if data["fullname"] != "Patched" || data["address"] != "Jayapura" {
+		t.Errorf("patch changed unexpected fields: %v", data)
+	}
+
+	// Versi lama tidak boleh dipakai lagi setelah perubahan pertama
+	app.request(http.MethodPatch, path, map[string]string{"fullname": "Again"}, token, header{"If-Match", `"1"`}).
+		expectProblem(t, http.StatusPreconditionFailed, "version_conflict")
+
+	app.request(http.MethodPatch, path, map[string]string{"password": "123"}, token, header{"If-Match", `"2"`}).
+		expectProblem(t, http.StatusBadRequest, "validation_failed")
+}
+
+func TestDeleteUser(t *testing.T) {
+	app := newTestApp(t, requireIfMatch)
+	token := app.adminToken()
+	user := app.createUser("user@mail.com")
+	path := fmt.Sprintf("/api/users/%d", user.ID)
+
+	app.request(http.MethodDelete, path, nil, token).
+		expectProblem(t, http.StatusPreconditionRequired, "precondition_required")
+	app.request(http.MethodDelete, path, nil, token, header{"If-Match", `"1"`}).
+		expectStatus(t, http.StatusOK)
+	app.request(http.MethodGet, path, nil, token).
+		expectProblem(t, http.StatusNotFound, "user_not_found")
+	app.request(http.MethodDelete, path, nil, token, header{"If-Match", "*"}).
+		expectProblem(t, http.StatusNotFound, "user_not_found")
+}
+
+func TestUserStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		action string
+		status int
+		want   string
+		code   string
+	}{
+		{name: "suspend active", from: model.StatusActive, action: "suspend", status: http.StatusOK, want: model.StatusSuspended},
+		{name: "disable active", from: model.StatusActive, action: "disable", status: http.StatusOK, want: model.StatusDisabled},
+		{name: "reactivate suspended", from: model.StatusSuspended, action: "reactivate", status: http.StatusOK, want: model.StatusActive},
+		{name: "lock active", from: model.StatusActive, action: "lock", status: http.StatusOK, want: model.StatusLocked},
+		{name: "activate pending", from: model.StatusPending, action: "reactivate", status: http.StatusOK, want: model.StatusActive},
+		{name: "disable pending", from: model.StatusPending, action: "disable", status: http.StatusOK, want: model.StatusDisabled},
+		{name: "suspend pending", from: model.StatusPending, action: "suspend", status: http.StatusConflict, code: "invalid_status_transition"},
+		{name: "reactivate locked", from: model.StatusLocked, action: "reactivate", status: http.StatusOK, want: model.StatusActive},
+		{name: "lock suspended", from: model.StatusSuspended, action: "lock", status: http.StatusConflict, code: "invalid_status_transition"},
+		{name: "suspend disabled", from: model.StatusDisabled, action: "suspend", status: http.StatusConflict, code: "invalid_status_transition"},
+		{name: "reactivate active", from: model.StatusActive, action: "reactivate", status: http.StatusConflict, code: "invalid_status_transition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			token := app.adminToken()
+			user := app.createUser("user@mail.com", func(u *model.User) { u.Status = tt.from })
+
+			resp := app.request(http.MethodPost, fmt.Sprintf("/api/users/%d/%s", user.ID, tt.action),
+				map[string]string{"reason": "test"}, token)
+			if tt.code != "" {
+				resp.expectProblem(t, tt.status, tt.code)
+				return
+			}
+
+			resp.expectStatus(t, tt.status)
+			data := resp.data(t)
+			if data["status"] != tt.want || data["status_reason"] != "test" {
+				t.Errorf("unexpected status: %v", data)
+			}
+		})
+	}
+}
+
+func TestUserStatusRequiresAdmin(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+	target := app.createUser("target@mail.com")
+	token := app.tokenFor(user)
+
+	for _, action := range []string{"suspend", "lock", "reactivate", "disable"} {
+		t.Run(action, func(t *testing.T) {
+			app.request(http.MethodPost, fmt.Sprintf("/api/users/%d/%s", target.ID, action), nil, token).
+				expectProblem(t, http.StatusForbidden, "forbidden")
+		})
+	}
+
+	stored, err := app.users.FindByID(context.Background(), target.ID)
+	if err != nil || stored.Status != model.StatusActive {
+		t.Errorf("status = %q (err %v), want %q", stored.Status, err, model.StatusActive)
+	}
+}
+
+func TestBatchUsers(t *testing.T) {
+	app := newTestApp(t)
+	token := app.adminToken()
+	user := app.createUser("user@mail.com")
+
+	// Mode atomic: operasi kedua gagal sehingga operasi pertama ikut dibatalkan
+	resp := app.request(http.MethodPost, "/api/users/batch", map[string]interface{}{
+		"operations": []map[string]interface{}{
+			{"op": "suspend", "id": user.ID},
+			{"op": "delete", "id": 999},
+		},
+	}, token)
+	resp.expectProblem(t, http.StatusUnprocessableEntity, "batch_rolled_back")
+	results, _ := resp.Body["results"].([]interface{})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if first := results[0].(map[string]interface{}); first["status"] != float64(http.StatusFailedDependency) {
+		t.Errorf("first result = %v, want status 424", first)
+	}
+
+	detail := app.request(http.MethodGet, fmt.Sprintf("/api/users/%d", user.ID), nil, token)
+	if detail.data(t)["status"] != model.StatusActive {
+		t.Errorf("atomic batch was not rolled back: %v", detail.data(t))
+	}
+
+	// Mode best_effort: operasi yang berhasil tetap disimpan
+	resp = app.request(http.MethodPost, "/api/users/batch", map[string]interface{}{
+		"mode": "best_effort",
+		"operations": []map[string]interface{}{
+			{"op": "set_role", "id": user.ID, "role": model.RoleAdmin},
+			{"op": "set_role", "id": user.ID, "role": "superuser"},
+		},
+	}, token)
+	resp.expectStatus(t, http.StatusMultiStatus)
+	results, _ = resp.Body["data"].([]interface{})
+	if len(results) != 2 ||
+		results[0].(map[string]interface{})["status"] != float64(http.StatusOK) ||
+		results[1].(map[string]interface{})["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("unexpected best effort results: %v", results)
+	}
+
+	detail = app.request(http.MethodGet, fmt.Sprintf("/api/users/%d", user.ID), nil, token)
+	if detail.data(t)["role"] != model.RoleAdmin {
+		t.Errorf("best effort batch did not apply role: %v", detail.data(t))
+	}
+
+	app.request(http.MethodPost, "/api/users/batch", map[string]interface{}{"mode": "sometimes"}, token).
+		expectProblem(t, http.StatusBadRequest, "validation_failed")
+}
+
+func TestBatchUsersRequiresAdmin(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("user@mail.com")
+
+	// Pengguna biasa tidak boleh menjadikan dirinya admin lewat batch
+	app.request(http.MethodPost, "/api/users/batch", map[string]interface{}{
+		"operations": []map[string]interface{}{{"op": "set_role", "id": user.ID, "role": model.RoleAdmin}},
+	}, app.tokenFor(user)).expectProblem(t, http.StatusForbidden, "forbidden")
+
+	stored, err := app.users.FindByID(context.Background(), user.ID)
+	if err != nil || stored.Role != model.RoleUser {
+		t.Errorf("role = %q (err %v), want %q", stored.Role, err, model.RoleUser)
+	}
+}
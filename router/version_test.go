@@ -0,0 +1,49 @@
+package router_test
+
+import (
+	"net/http"
+	"testing"
+
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/versioning"
+)
+
+func TestAPIVersioning(t *testing.T) {
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.API.LegacyDeprecated = "2026-10-19"
+		deps.Config.API.LegacySunset = "2027-06-30"
+	})
+	user := app.createUser("user@mail.com")
+	token := app.tokenFor(user)
+
+	resp := app.request(http.MethodGet, "/api/v1/auth/profile", nil, token)
+	resp.expectStatus(t, http.StatusOK)
+	if resp.Header.Get(versioning.Header) != "v1" || resp.Header.Get(versioning.HeaderDeprecation) != "" {
+		t.Errorf("v1 headers = %v", resp.Header)
+	}
+
+	// /api tanpa versi tetap dilayani v1 tetapi ditandai usang
+	resp = app.request(http.MethodGet, "/api/auth/profile", nil, token)
+	resp.expectStatus(t, http.StatusOK)
+	if got := resp.Header.Get(versioning.HeaderDeprecation); got != "@1792368000" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := resp.Header.Get(versioning.HeaderSunset); got != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+
+	app.request(http.MethodGet, "/api/auth/profile", nil, token, header{versioning.Header, "v7"}).
+		expectProblem(t, http.StatusBadRequest, "unsupported_version")
+}
+
+func TestAPIVersioningWithoutDeprecationDate(t *testing.T) {
+	app := newTestApp(t)
+	token := app.tokenFor(app.createUser("user@mail.com"))
+
+	// Tanpa API_LEGACY_DEPRECATED, /api tanpa versi tidak ditandai usang
+	resp := app.request(http.MethodGet, "/api/auth/profile", nil, token)
+	resp.expectStatus(t, http.StatusOK)
+	if resp.Header.Get(versioning.HeaderDeprecation) != "" || resp.Header.Get(versioning.HeaderSunset) != "" {
+		t.Errorf("legacy headers = %v", resp.Header)
+	}
+}
@@ -0,0 +1,430 @@
+package router_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const passkeyBase = "/api/v1/auth/webauthn"
+
+// Flag authenticator data yang dipakai authenticator tiruan.
+const (
+	flagUP = 0x01
+	flagUV = 0x04
+	flagAT = 0x40
+)
+
+// softAuthenticator adalah authenticator ES256 tiruan yang menghasilkan respons seperti
+// PublicKeyCredential.toJSON() di browser.
+type softAuthenticator struct {
+	t          *testing.T
+	origin     string
+	rpID       string
+	key        *ecdsa.PrivateKey
+	id         []byte
+	userHandle []byte
+	signCount  uint32 // 0 meniru passkey tersinkron yang tidak memakai counter
+	flags      byte
+}
+
+func newSoftAuthenticator(t *testing.T, signCount uint32) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return &softAuthenticator{
+		t: t, origin: "http://localhost:3000", rpID: "localhost",
+		key: key, id: id, signCount: signCount, flags: flagUP | flagUV,
+	}
+}
+
+// create menjawab opsi registrasi dari register/begin.
+func (a *softAuthenticator) create(options map[string]interface{}) map[string]interface{} {
+	a.t.Helper()
+
+	user := options["user"].(map[string]interface{})
+	a.userHandle = decodeURL(a.t, user["id"].(string))
+
+	cose := cborEncode(cborMap{
+		{int64(1), int64(2)},  // kty: EC2
+		{int64(3), int64(-7)}, // alg: ES256
+		{int64(-1), int64(1)}, // crv: P-256
+		{int64(-2), a.key.X.FillBytes(make([]byte, 32))},
+		{int64(-3), a.key.Y.FillBytes(make([]byte, 32))},
+	})
+	attested := append(make([]byte, 16), byte(len(a.id)>>8), byte(len(a.id)))
+	attested = append(append(attested, a.id...), cose...)
+	attestation := cborEncode(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", a.authData(a.flags|flagAT, attested)},
+	})
+
+	return map[string]interface{}{
+		"id":    encodeURL(a.id),
+		"rawId": encodeURL(a.id),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    encodeURL(a.clientData("webauthn.create", options["challenge"].(string))),
+			"attestationObject": encodeURL(attestation),
+			"transports":        []string{"internal", "hybrid"},
+		},
+	}
+}
+
+// get menjawab opsi login dari login/begin.
+func (a *softAuthenticator) get(options map[string]interface{}) map[string]interface{} {
+	a.t.Helper()
+
+	if a.signCount > 0 {
+		a.signCount++
+	}
+	authData := a.authData(a.flags, nil)
+	clientData := a.clientData("webauthn.get", options["challenge"].(string))
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatalf("sign: %v", err)
+	}
+
+	return map[string]interface{}{
+		"id":    encodeURL(a.id),
+		"rawId": encodeURL(a.id),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    encodeURL(clientData),
+			"authenticatorData": encodeURL(authData),
+			"signature":         encodeURL(signature),
+			"userHandle":        encodeURL(a.userHandle),
+		},
+	}
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(ceremony, challenge string) []byte {
+	raw, err := json.Marshal(map[string]interface{}{
+		"type": ceremony, "challenge": challenge, "origin": a.origin, "crossOrigin": false,
+	})
+	if err != nil {
+		a.t.Fatalf("marshal client data: %v", err)
+	}
+	return raw
+}
+
+// cborMap adalah map CBOR dengan urutan kunci tetap.
+type cborMap [][2]interface{}
+
+// cborEncode meng-encode subset CBOR yang dipakai authenticator tiruan.
+func cborEncode(value interface{}) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		}
+	}
+
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case cborMap:
+		out := head(5, uint64(len(v)))
+		for _, entry := range v {
+			out = append(out, cborEncode(entry[0])...)
+			out = append(out, cborEncode(entry[1])...)
+		}
+		return out
+	default:
+		panic(fmt.Sprintf("cbor: unsupported type %T", value))
+	}
+}
+
+func encodeURL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeURL(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+// registerPasskey mendaftarkan authenticator ke akun pemilik token dan mengembalikan passkey yang tersimpan.
+func (a *testApp) registerPasskey(token string, authenticator *softAuthenticator, name string) map[string]interface{} {
+	a.t.Helper()
+
+	begin := a.request(fiber.MethodPost, passkeyBase+"/register/begin", nil, token)
+	begin.expectStatus(a.t, fiber.StatusOK)
+	resp := a.request(fiber.MethodPost, passkeyBase+"/register/finish", map[string]interface{}{
+		"name": name, "credential": authenticator.create(begin.data(a.t)),
+	}, token)
+	resp.expectStatus(a.t, fiber.StatusCreated)
+	return resp.data(a.t)
+}
+
+// passkeyAssertion menjalankan login/begin dan mengembalikan body login/finish dari authenticator.
+func (a *testApp) passkeyAssertion(authenticator *softAuthenticator, begin interface{}) map[string]interface{} {
+	a.t.Helper()
+
+	options := a.request(fiber.MethodPost, passkeyBase+"/login/begin", begin, "")
+	options.expectStatus(a.t, fiber.StatusOK)
+	return map[string]interface{}{"credential": authenticator.get(options.data(a.t))}
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("passkey@example.com")
+	token := app.tokenFor(user)
+	authenticator := newSoftAuthenticator(t, 0)
+
+	begin := app.request(fiber.MethodPost, passkeyBase+"/register/begin", nil, token)
+	begin.expectStatus(t, fiber.StatusOK)
+	options := begin.data(t)
+	if rp := options["rp"].(map[string]interface{}); rp["id"] != "localhost" {
+		t.Errorf("rp = %v, want id localhost", rp)
+	}
+	if handle := options["user"].(map[string]interface{})["id"].(string); strings.Contains(handle, "passkey") {
+		t.Errorf("user handle %q must not contain personal data", handle)
+	}
+
+	passkey := app.request(fiber.MethodPost, passkeyBase+"/register/finish", map[string]interface{}{
+		"credential": authenticator.create(options),
+	}, token)
+	passkey.expectStatus(t, fiber.StatusCreated)
+	if data := passkey.data(t); data["name"] != service.DefaultPasskeyName || data["credential_id"] != encodeURL(authenticator.id) {
+		t.Fatalf("passkey = %v", data)
+	}
+
+	// Challenge registrasi hanya bisa dipakai sekali
+	app.request(fiber.MethodPost, passkeyBase+"/register/finish", map[string]interface{}{
+		"credential": authenticator.create(options),
+	}, token).expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidCeremony)
+
+	// Authenticator yang sama dikecualikan pada registrasi berikutnya dan ditolak jika tetap dikirim
+	again := app.request(fiber.MethodPost, passkeyBase+"/register/begin", nil, token).data(t)
+	if excluded := again["excludeCredentials"].([]interface{}); len(excluded) != 1 {
+		t.Fatalf("excludeCredentials = %v, want the registered passkey", excluded)
+	}
+	app.request(fiber.MethodPost, passkeyBase+"/register/finish", map[string]interface{}{
+		"credential": authenticator.create(again),
+	}, token).expectProblem(t, fiber.StatusConflict, apperror.CodePasskeyExists)
+
+	// Login tanpa password dengan passkey mana pun milik RP
+	login := app.request(fiber.MethodPost, passkeyBase+"/login/begin", nil, "")
+	login.expectStatus(t, fiber.StatusOK)
+	if uv := login.data(t)["userVerification"]; uv != "required" {
+		t.Errorf("userVerification = %v, want required", uv)
+	}
+
+	// Passkey yang tidak terdaftar ditolak tanpa memakai challenge-nya
+	unknown := newSoftAuthenticator(t, 0)
+	unknown.id = []byte("not-registered")
+	app.request(fiber.MethodPost, passkeyBase+"/login/finish", map[string]interface{}{
+		"credential": unknown.get(login.data(t)),
+	}, "").expectProblem(t, fiber.StatusUnauthorized, apperror.CodePasskeyVerificationFailed)
+
+	assertion := map[string]interface{}{"credential": authenticator.get(login.data(t))}
+	resp := app.request(fiber.MethodPost, passkeyBase+"/login/finish", assertion, "")
+	resp.expectStatus(t, fiber.StatusOK)
+	passkeyToken := resp.data(t)["token"].(string)
+	app.request(fiber.MethodGet, "/api/v1/auth/profile", nil, passkeyToken).expectStatus(t, fiber.StatusOK)
+
+	// Token passkey membawa scope bawaan role, tanpa scope admin
+	app.request(fiber.MethodPost, "/api/v1/auth/exchange", map[string]string{"scope": model.ScopeUsersRead}, passkeyToken).
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeInvalidScope)
+
+	// Assertion yang sama tidak bisa diputar ulang
+	app.request(fiber.MethodPost, passkeyBase+"/login/finish", assertion, "").
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidCeremony)
+
+	list := app.request(fiber.MethodGet, passkeyBase+"/credentials", nil, token)
+	list.expectStatus(t, fiber.StatusOK)
+	passkeys := list.Body["data"].([]interface{})
+	if len(passkeys) != 1 || passkeys[0].(map[string]interface{})["last_used_at"] == nil {
+		t.Fatalf("passkeys = %v, want one passkey with last_used_at", passkeys)
+	}
+}
+
+func TestPasskeyLoginRejectsInvalidAssertions(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser("counter@example.com")
+	authenticator := newSoftAuthenticator(t, 10)
+	app.registerPasskey(app.tokenFor(user), authenticator, "Security key")
+
+	tests := []struct {
+		name   string
+		mutate func(*softAuthenticator) func()
+		code   string
+	}{
+		{
+			name: "without user verification",
+			mutate: func(a *softAuthenticator) func() {
+				a.flags = flagUP
+				return func() { a.flags = flagUP | flagUV }
+			},
+			code: apperror.CodePasskeyVerificationFailed,
+		},
+		{
+			name: "wrong origin",
+			mutate: func(a *softAuthenticator) func() {
+				a.origin = "https://evil.example"
+				return func() { a.origin = "http://localhost:3000" }
+			},
+			code: apperror.CodePasskeyVerificationFailed,
+		},
+		{
+			name: "counter went backwards",
+			mutate: func(a *softAuthenticator) func() {
+				a.signCount = 2
+				return func() {}
+			},
+			code: apperror.CodePasskeyVerificationFailed,
+		},
+		{
+			name: "unknown credential",
+			mutate: func(a *softAuthenticator) func() {
+				id := a.id
+				a.id = []byte("not-registered")
+				return func() { a.id = id }
+			},
+			code: apperror.CodePasskeyVerificationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := tt.mutate(authenticator)
+			defer restore()
+			app.request(fiber.MethodPost, passkeyBase+"/login/finish", app.passkeyAssertion(authenticator, nil), "").
+				expectProblem(t, fiber.StatusUnauthorized, tt.code)
+		})
+	}
+
+	// Counter yang naik kembali diterima
+	authenticator.signCount = 20
+	app.request(fiber.MethodPost, passkeyBase+"/login/finish", app.passkeyAssertion(authenticator, nil), "").
+		expectStatus(t, fiber.StatusOK)
+}
+
+func TestPasskeySecondFactor(t *testing.T) {
+	app := newTestApp(t, func(deps *router.Dependencies) {
+		deps.Config.WebAuthn.RequireForPasswordLogin = true
+	})
+	owner := app.createUser("owner@example.com")
+	other := app.createUser("other@example.com")
+	ownerKey, otherKey := newSoftAuthenticator(t, 0), newSoftAuthenticator(t, 0)
+	app.registerPasskey(app.tokenFor(owner), ownerKey, "Phone")
+	app.registerPasskey(app.tokenFor(other), otherKey, "Phone")
+
+	login := func(email string) testResponse {
+		return app.request(fiber.MethodPost, "/api/v1/auth/login", map[string]string{"email": email, "password": testPassword}, "")
+	}
+
+	// Pengguna tanpa passkey tetap login dengan password saja
+	app.createUser("plain@example.com")
+	login("plain@example.com").expectStatus(t, fiber.StatusOK)
+
+	resp := login(owner.Email)
+	resp.expectProblem(t, fiber.StatusUnauthorized, apperror.CodeSecondFactorRequired)
+	mfaToken, _ := resp.Body["mfa_token"].(string)
+	if mfaToken == "" {
+		t.Fatalf("second_factor_required without mfa_token: %v", resp.Body)
+	}
+
+	// Passkey pengguna lain tidak bisa menyelesaikan login pemilik token MFA
+	begin := app.request(fiber.MethodPost, passkeyBase+"/login/begin", map[string]string{"mfa_token": mfaToken}, "")
+	begin.expectStatus(t, fiber.StatusOK)
+	if allowed := begin.data(t)["allowCredentials"].([]interface{}); len(allowed) != 1 {
+		t.Fatalf("allowCredentials = %v, want the owner's passkey", allowed)
+	}
+	app.request(fiber.MethodPost, passkeyBase+"/login/finish", map[string]interface{}{
+		"credential": otherKey.get(begin.data(t)),
+	}, "").expectProblem(t, fiber.StatusUnauthorized, apperror.CodePasskeyVerificationFailed)
+
+	// Token MFA hanya bisa ditukar sekali
+	app.request(fiber.MethodPost, passkeyBase+"/login/begin", map[string]string{"mfa_token": mfaToken}, "").
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeInvalidChallenge)
+
+	// Faktor kedua tidak mewajibkan verifikasi pengguna karena password sudah diperiksa
+	ownerKey.flags = flagUP
+	mfaToken = login(owner.Email).Body["mfa_token"].(string)
+	resp = app.request(fiber.MethodPost, passkeyBase+"/login/finish",
+		app.passkeyAssertion(ownerKey, map[string]string{"mfa_token": mfaToken}), "")
+	resp.expectStatus(t, fiber.StatusOK)
+	if resp.data(t)["token"] == "" {
+		t.Fatalf("login response has no token: %v", resp.Body)
+	}
+}
+
+func TestPasskeyManagement(t *testing.T) {
+	app := newTestApp(t)
+	owner := app.createUser("manage@example.com")
+	other := app.createUser("intruder@example.com")
+	token := app.tokenFor(owner)
+	laptop := app.registerPasskey(token, newSoftAuthenticator(t, 0), "Laptop")
+	app.registerPasskey(token, newSoftAuthenticator(t, 0), "Phone")
+	path := fmt.Sprintf("%s/credentials/%v", passkeyBase, laptop["id"])
+
+	app.request(fiber.MethodPatch, path, map[string]string{"name": "Work laptop"}, token).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodPatch, path, map[string]string{"name": " "}, token).
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeValidationFailed)
+	app.request(fiber.MethodPatch, path, map[string]string{"name": "Mine now"}, app.tokenFor(other)).
+		expectProblem(t, fiber.StatusNotFound, apperror.CodePasskeyNotFound)
+	app.request(fiber.MethodDelete, path, nil, app.tokenFor(other)).
+		expectProblem(t, fiber.StatusNotFound, apperror.CodePasskeyNotFound)
+	app.request(fiber.MethodDelete, passkeyBase+"/credentials/abc", nil, token).
+		expectProblem(t, fiber.StatusBadRequest, apperror.CodeValidationFailed)
+
+	list := app.request(fiber.MethodGet, passkeyBase+"/credentials", nil, token)
+	list.expectStatus(t, fiber.StatusOK)
+	passkeys := list.Body["data"].([]interface{})
+	if len(passkeys) != 2 || passkeys[0].(map[string]interface{})["name"] != "Work laptop" {
+		t.Fatalf("passkeys = %v", passkeys)
+	}
+
+	app.request(fiber.MethodDelete, path, nil, token).expectStatus(t, fiber.StatusOK)
+	app.request(fiber.MethodDelete, path, nil, token).expectProblem(t, fiber.StatusNotFound, apperror.CodePasskeyNotFound)
+	if passkeys := app.request(fiber.MethodGet, passkeyBase+"/credentials", nil, token).Body["data"].([]interface{}); len(passkeys) != 1 {
+		t.Fatalf("passkeys after delete = %v, want 1", passkeys)
+	}
+	app.request(fiber.MethodGet, passkeyBase+"/credentials", nil, "").
+		expectProblem(t, fiber.StatusUnauthorized, apperror.CodeTokenMissing)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/database"
+	"go-fiber-user-management/health"
+	"go-fiber-user-management/mailer"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/migration"
+	"go-fiber-user-management/oidc"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/router"
+	"go-fiber-user-management/service"
+	"go-fiber-user-management/tracing"
+
+	"gorm.io/gorm"
+)
+
+// serve menjalankan server HTTP dan background job sampai ctx dibatalkan (SIGINT/SIGTERM),
+// lalu berhenti dengan tertib: berhenti menerima koneksi baru, menunggu request yang sedang
+// berjalan hingga ShutdownTimeout, menghentikan background job, dan menutup pool database.
+func serve(ctx context.Context, cfg config.Config, db *gorm.DB) error {
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("setup tracing: %w", err)
+	}
+	if err := metrics.InstrumentDB(db, cfg.Database.Name); err != nil {
+		return fmt.Errorf("instrument database: %w", err)
+	}
+	if err := tracing.InstrumentDB(db, cfg.Database.Driver); err != nil {
+		return fmt.Errorf("instrument database: %w", err)
+	}
+
+	// Tanpa kunci dari file, ID token ditandatangani kunci sementara yang hilang saat restart
+	var signer *oidc.Signer
+	if path := cfg.OAuth.SigningKeyFile; path != "" {
+		if signer, err = oidc.LoadSigner(path); err != nil {
+			return err
+		}
+	} else {
+		slog.Warn("OAUTH_SIGNING_KEY_FILE is not set, ID tokens are signed with an ephemeral key")
+		signer = oidc.Ephemeral()
+	}
+
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return err
+	}
+	if cfg.Mail.Driver == mailer.DriverLog {
+		slog.Warn("MAIL_DRIVER is log, login emails are written to the log instead of being sent")
+	}
+
+	users := repository.NewGormUserRepository(db)
+	tokens := repository.NewGormTokenRepository(db)
+	oauth := repository.NewGormOAuthRepository(db)
+	identities := repository.NewGormIdentityRepository(db)
+	challenges := repository.NewGormLoginChallengeRepository(db)
+	passkeys := repository.NewGormWebAuthnRepository(db)
+	apiTokens := repository.NewGormAPITokenRepository(db)
+
+	// Aplikasi beserta rute authentication & user management
+	app := router.New(router.Dependencies{
+		Config:          cfg,
+		Users:           users,
+		Tokens:          tokens,
+		OAuth:           oauth,
+		Identities:      identities,
+		LoginChallenges: challenges,
+		WebAuthn:        passkeys,
+		APITokens:       apiTokens,
+		Audit:           repository.NewGormAuditRepository(db),
+		Mailer:          mail,
+		Signer:          signer,
+		HealthChecks: []health.Check{
+			health.Database(db),
+			health.Migrations(migration.New(db, cfg.Database.Driver)),
+		},
+	})
+
+	// Background job memakai context sendiri agar dihentikan setelah request selesai dikuras
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	var jobs sync.WaitGroup
+	if interval := cfg.Auth.RevocationCleanupInterval; interval > 0 {
+		auth := service.NewAuthService(users, tokens, cfg.Auth)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			auth.RunRevocationCleanup(jobCtx, interval)
+		}()
+
+		// Kode otorisasi yang tidak pernah ditukar dibersihkan dengan interval yang sama
+		codes := service.NewOAuthService(oauth, users, tokens, cfg.Auth, cfg.OAuth, signer)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			codes.RunCodeCleanup(jobCtx, interval)
+		}()
+
+		// Token akses pribadi yang kedaluwarsa juga dibersihkan dengan interval yang sama
+		expired := service.NewAPITokenService(apiTokens, users, cfg.APITokens)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			expired.RunAPITokenCleanup(jobCtx, interval)
+		}()
+	}
+
+	// Ceremony passkey yang ditinggalkan disimpan di tabel yang sama dan ikut dibersihkan
+	if interval := cfg.Passwordless.CleanupInterval; interval > 0 {
+		passwordless := service.NewPasswordlessService(challenges, users, mail, cfg.Auth, cfg.Passwordless, cfg.OAuth.Issuer)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			passwordless.RunChallengeCleanup(jobCtx, interval)
+		}()
+	}
+
+	listenErr := make(chan error, 1)
+	slog.Info("server listening", "port", cfg.Server.Port)
+	go func() {
+		listenErr <- app.Listen(fmt.Sprintf(":%d", cfg.Server.Port))
+	}()
+
+	var errs []error
+	select {
+	case err := <-listenErr:
+		// Server berhenti sendiri, misalnya port sudah dipakai
+		errs = append(errs, err)
+	case <-ctx.Done():
+		slog.Info("shutting down, draining in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+		if err := app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown server: %w", err))
+		}
+	}
+
+	cancelJobs()
+	jobs.Wait()
+
+	if err := database.Close(db); err != nil {
+		errs = append(errs, fmt.Errorf("close database: %w", err))
+	}
+
+	// Kirim span yang tersisa; ctx sudah dibatalkan sehingga dipakai context baru dengan batas waktu
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		errs = append(errs, fmt.Errorf("flush traces: %w", err))
+	}
+	slog.Info("shutdown complete")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// APITokenPrefix mengawali setiap token akses pribadi sehingga bisa dibedakan dari JWT dan
+// dikenali oleh pemindai rahasia di repository kode.
+const APITokenPrefix = "umt_"
+
+// ClaimAPITokenID adalah klaim berisi ID token akses pribadi yang dipakai request. Klaim ini
+// hanya ada untuk request dengan token akses pribadi, tidak pernah di JWT.
+const ClaimAPITokenID = "api_token_id"
+
+const (
+	// apiTokenPrefixLength adalah panjang awal token yang disimpan dan ditampilkan.
+	apiTokenPrefixLength = 12
+	// apiTokenUseInterval membatasi penulisan last_used_at agar tidak terjadi di setiap request.
+	apiTokenUseInterval   = time.Minute
+	maxAPITokenNameLength = 100
+)
+
+// APITokenService mengelola token akses pribadi: token berumur panjang milik pengguna untuk
+// otomasi, dengan nama, scope, dan masa berlaku. Nilai token hanya ditampilkan sekali saat
+// dibuat dan disimpan sebagai hash SHA-256.
+type APITokenService struct {
+	tokens repository.APITokenRepository
+	users  repository.UserRepository
+	config config.APITokenConfig
+}
+
+// NewAPITokenService membuat APITokenService.
+func NewAPITokenService(tokens repository.APITokenRepository, users repository.UserRepository, cfg config.APITokenConfig) *APITokenService {
+	return &APITokenService{tokens: tokens, users: users, config: cfg}
+}
+
+// IsAPIToken memeriksa apakah bearer token adalah token akses pribadi, bukan JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// Create membuat token akses pribadi untuk user dan mengembalikan nilainya.
+func (s *APITokenService) Create(ctx context.Context, user model.User, req model.APITokenRequestDTO) (model.APIToken, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPITokenNameLength {
+		return model.APIToken{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+			"Name is required and must be at most 100 characters long")
+	}
+	scopes, err := apiScopes(req.Scopes)
+	if err != nil {
+		return model.APIToken{}, "", err
+	}
+	if err := allowedForRole(user.Role, scopes); err != nil {
+		return model.APIToken{}, "", err
+	}
+	ttl := s.config.DefaultTTL
+	if req.ExpiresIn != 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+		if req.ExpiresIn < 0 || ttl > s.config.MaxTTL {
+			return model.APIToken{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+				fmt.Sprintf("expires_in must be between 1 and %d seconds", int64(s.config.MaxTTL/time.Second)))
+		}
+	}
+
+	existing, err := s.tokens.ListByUser(ctx, user.ID)
+	if err != nil {
+		return model.APIToken{}, "", apperror.Internal(err, "Failed to fetch API tokens")
+	}
+	active := 0
+	for _, token := range existing {
+		if time.Now().Before(token.ExpiresAt) {
+			active++
+		}
+	}
+	if active >= s.config.MaxPerUser {
+		return model.APIToken{}, "", apperror.Conflict(apperror.CodeAPITokenLimit,
+			fmt.Sprintf("A user may have at most %d active API tokens, revoke one first", s.config.MaxPerUser))
+	}
+
+	value := APITokenPrefix + randomToken(32)
+	token := model.APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    value[:apiTokenPrefixLength],
+		TokenHash: hashToken(value),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokens.Create(ctx, &token); err != nil {
+		return model.APIToken{}, "", apperror.Internal(err, "Failed to create API token")
+	}
+	return token, value, nil
+}
+
+// List mengembalikan token akses pribadi milik pengguna, termasuk yang sudah kedaluwarsa.
+func (s *APITokenService) List(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	tokens, err := s.tokens.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch API tokens")
+	}
+	return tokens, nil
+}
+
+// Revoke menghapus token akses pribadi milik pengguna sehingga langsung tidak berlaku.
+func (s *APITokenService) Revoke(ctx context.Context, userID, id uint) error {
+	err := s.tokens.Delete(ctx, userID, id)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrNotFound):
+		return apperror.NotFound(apperror.CodeAPITokenNotFound, "API token not found")
+	default:
+		return apperror.Internal(err, "Failed to revoke API token")
+	}
+}
+
+// Authorize memvalidasi token akses pribadi dan mengembalikan klaim yang setara dengan JWT
+// (user_id, email, scope, exp) ditambah api_token_id, beserta pemilik token.
+func (s *APITokenService) Authorize(ctx context.Context, value string) (jwt.MapClaims, model.User, error) {
+	token, err := s.tokens.FindByHash(ctx, hashToken(value))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid or expired token")
+	}
+	if err != nil {
+		return nil, model.User{}, apperror.Internal(err, "Failed to check token")
+	}
+	now := time.Now()
+	if now.After(token.ExpiresAt) {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenExpired, "Token has expired")
+	}
+
+	user, err := s.users.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "User no longer exists")
+	}
+	if user.Status != model.StatusActive {
+		return nil, model.User{}, apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status))
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenUseInterval {
+		if err := s.tokens.RecordUse(ctx, token.ID, now); err != nil {
+			return nil, model.User{}, apperror.Internal(err, "Failed to update API token")
+		}
+	}
+
+	// Angka disimpan sebagai float64 seperti klaim hasil parsing JWT
+	claims := jwt.MapClaims{
+		"user_id":       float64(user.ID),
+		"email":         user.Email,
+		"scope":         token.Scopes,
+		"exp":           float64(token.ExpiresAt.Unix()),
+		"issued_at":     float64(token.CreatedAt.Unix()),
+		ClaimAPITokenID: float64(token.ID),
+	}
+	return claims, user, nil
+}
+
+// RevokeToken menghapus token akses pribadi berdasarkan nilainya; dipakai saat logout.
+func (s *APITokenService) RevokeToken(ctx context.Context, value string) error {
+	token, err := s.tokens.FindByHash(ctx, hashToken(value))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return apperror.Internal(err, "Failed to revoke token")
+	}
+	if err := s.tokens.Delete(ctx, token.UserID, token.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperror.Internal(err, "Failed to revoke token")
+	}
+	return nil
+}
+
+// PurgeExpiredTokens menghapus token akses pribadi yang sudah kedaluwarsa.
+func (s *APITokenService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	return s.tokens.PurgeExpired(ctx, time.Now())
+}
+
+// allowedForRole memastikan setiap scope tersedia untuk role pengguna (model.RoleScopes).
+func allowedForRole(role string, scopes []string) error {
+	allowed := model.RoleScopes(role)
+	for _, scope := range scopes {
+		if !slices.Contains(allowed, scope) {
+			return apperror.BadRequest(apperror.CodeInvalidScope, fmt.Sprintf("Scope %s is not available to your account", scope)).
+				With("allowed_scopes", allowed)
+		}
+	}
+	return nil
+}
+
+// apiScopes memvalidasi scope yang diminta dan mengurutkannya seperti model.APIScopes.
+func apiScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, apperror.BadRequest(apperror.CodeValidationFailed, "At least one scope is required").
+			With("allowed_scopes", model.APIScopes)
+	}
+	for _, scope := range requested {
+		if !model.IsAPIScope(scope) {
+			return nil, apperror.BadRequest(apperror.CodeInvalidScope, fmt.Sprintf("Unknown scope %q", scope)).
+				With("allowed_scopes", model.APIScopes)
+		}
+	}
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range model.APIScopes {
+		if slices.Contains(requested, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
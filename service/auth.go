@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// SecondFactor memeriksa apakah login password pengguna perlu dikonfirmasi faktor kedua.
+// Token yang tidak kosong dikirim ke klien untuk melanjutkan konfirmasi.
+type SecondFactor func(ctx context.Context, user model.User) (string, error)
+
+// APITokenAuthorizer memverifikasi dan mencabut token akses pribadi (lihat APITokenService).
+type APITokenAuthorizer interface {
+	Authorize(ctx context.Context, token string) (jwt.MapClaims, model.User, error)
+	RevokeToken(ctx context.Context, token string) error
+}
+
+// ImpersonationAuditor memverifikasi admin di balik token impersonation dan mencatat setiap
+// request-nya (lihat ImpersonationService).
+type ImpersonationAuditor interface {
+	VerifyActor(ctx context.Context, claims jwt.MapClaims) error
+	RecordRequest(ctx context.Context, claims jwt.MapClaims, request, ip string) error
+}
+
+// AuthService berisi aturan bisnis untuk pendaftaran, login, dan validasi token.
+type AuthService struct {
+	users        repository.UserRepository
+	tokens       repository.TokenRepository
+	config       config.AuthConfig
+	secondFactor SecondFactor
+	apiTokens    APITokenAuthorizer
+	// impersonation nil berarti token impersonation selalu ditolak
+	impersonation ImpersonationAuditor
+}
+
+// NewAuthService membuat AuthService dengan repository dan pengaturan token yang diberikan.
+func NewAuthService(users repository.UserRepository, tokens repository.TokenRepository, cfg config.AuthConfig) *AuthService {
+	return &AuthService{users: users, tokens: tokens, config: cfg}
+}
+
+// UseSecondFactor mewajibkan konfirmasi faktor kedua setelah password benar, mis. passkey.
+func (s *AuthService) UseSecondFactor(secondFactor SecondFactor) {
+	s.secondFactor = secondFactor
+}
+
+// UseAPITokens membuat Authorize dan Logout menerima token akses pribadi (berawalan
+// APITokenPrefix) di samping JWT.
+func (s *AuthService) UseAPITokens(apiTokens APITokenAuthorizer) {
+	s.apiTokens = apiTokens
+}
+
+// UseImpersonation membuat Authorize menerima token impersonation selama admin di baliknya
+// masih diizinkan, dan AuditImpersonation mencatat request-nya.
+func (s *AuthService) UseImpersonation(impersonation ImpersonationAuditor) {
+	s.impersonation = impersonation
+}
+
+// AuditImpersonation mencatat request (method dan path) yang dibuat dengan token impersonation;
+// token biasa tidak dicatat. Request harus ditolak jika pencatatan gagal.
+func (s *AuthService) AuditImpersonation(ctx context.Context, claims jwt.MapClaims, request, ip string) error {
+	if s.impersonation == nil || !IsImpersonation(claims) {
+		return nil
+	}
+	return s.impersonation.RecordRequest(ctx, claims, request, ip)
+}
+
+// Register membuat pengguna baru dengan status aktif.
+func (s *AuthService) Register(ctx context.Context, req model.UserRequestDTO) (_ model.User, err error) {
+	defer func() { metrics.Registrations.WithLabelValues(metrics.Outcome(err)).Inc() }()
+
+	// Periksa apakah email sudah ada di database
+	if _, err := s.users.FindByEmail(ctx, req.Email); err == nil {
+		return model.User{}, emailExistsError()
+	}
+
+	// Validasi manual untuk password
+	if req.Password == "" || len(req.Password) < 6 {
+		return model.User{}, apperror.BadRequest(apperror.CodeValidationFailed, "Password is required and must be at least 6 characters long")
+	}
+
+	user := newUser(req)
+	// Create tetap bisa gagal dengan ErrDuplicate bila ada pendaftaran bersamaan
+	if err := s.users.Create(ctx, &user); err != nil {
+		return model.User{}, userWriteError(err, "Failed to create user")
+	}
+	return user, nil
+}
+
+// Login memvalidasi kredensial dan menghasilkan token JWT beserta scope-nya: scope yang diminta
+// atau semua scope untuk role pengguna jika tidak diminta.
+func (s *AuthService) Login(ctx context.Context, req model.AuthenticationRequest) (token, scope string, err error) {
+	defer func() { metrics.LoginAttempts.WithLabelValues(metrics.Outcome(err)).Inc() }()
+
+	// Scope yang diminta diperiksa sebelum password agar kesalahan klien langsung terlihat.
+	var scopes []string
+	if strings.TrimSpace(req.Scope) != "" {
+		if scopes, err = apiScopes(strings.Fields(req.Scope)); err != nil {
+			return "", "", err
+		}
+	}
+
+	user, err := s.Authenticate(ctx, req)
+	if err != nil {
+		return "", "", err
+	}
+	if scopes == nil {
+		scopes = model.RoleScopes(user.Role)
+	} else if err := allowedForRole(user.Role, scopes); err != nil {
+		return "", "", err
+	}
+	scope = strings.Join(scopes, " ")
+
+	token, err = utils.GenerateToken(user, s.config.JWTSecret, s.config.TokenTTL, jwt.MapClaims{"scope": scope})
+	if err != nil {
+		return "", "", apperror.Internal(err, "Gagal menghasilkan token")
+	}
+	return token, scope, nil
+}
+
+// Authenticate memeriksa email dan password, lalu mengembalikan pengguna jika akunnya aktif.
+// Dipakai oleh Login dan halaman persetujuan OAuth2. Jika faktor kedua diperlukan, error
+// second_factor_required membawa mfa_token untuk melanjutkan login dengan passkey.
+func (s *AuthService) Authenticate(ctx context.Context, req model.AuthenticationRequest) (model.User, error) {
+	// Mengambil pengguna berdasarkan email.
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return model.User{}, apperror.NotFound(apperror.CodeUserNotFound, "Pengguna tidak ditemukan")
+	}
+
+	// Memvalidasi password yang diberikan oleh pengguna.
+	if !utils.ComparePassword(ctx, user.PasswordHash, req.Password) {
+		return model.User{}, apperror.Unauthorized(apperror.CodeInvalidCredentials, "Password salah")
+	}
+
+	// Hanya akun dengan status aktif yang boleh login.
+	if user.Status != model.StatusActive {
+		return model.User{}, apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status)).
+			With("account_status", user.Status)
+	}
+
+	if s.secondFactor != nil {
+		token, err := s.secondFactor(ctx, user)
+		if err != nil {
+			return model.User{}, err
+		}
+		if token != "" {
+			return model.User{}, apperror.Unauthorized(apperror.CodeSecondFactorRequired,
+				"Confirm the sign-in with one of your passkeys").With("mfa_token", token)
+		}
+	}
+	return user, nil
+}
+
+// Exchange menerbitkan token baru dengan sebagian scope token yang sedang dipakai, mis. token
+// hanya-baca untuk diteruskan ke layanan lain. Token baru berakhir bersamaan dengan token asal
+// dan membawa client_id-nya jika ada.
+func (s *AuthService) Exchange(ctx context.Context, claims jwt.MapClaims, user model.User, requested string) (token, scope string, err error) {
+	scopes, err := apiScopes(strings.Fields(requested))
+	if err != nil {
+		return "", "", err
+	}
+	granted := model.GrantedScope(claims["scope"])
+	for _, want := range scopes {
+		if !model.HasScope(granted, want) {
+			return "", "", apperror.BadRequest(apperror.CodeInvalidScope,
+				fmt.Sprintf("Scope %s exceeds the scope of the current token", want)).
+				With("allowed_scopes", model.SplitScope(granted))
+		}
+	}
+
+	exp, _ := claims["exp"].(float64)
+	ttl := time.Until(time.Unix(int64(exp), 0))
+	if ttl <= 0 {
+		return "", "", apperror.Unauthorized(apperror.CodeTokenExpired, "Token has expired")
+	}
+	scope = strings.Join(scopes, " ")
+	extra := jwt.MapClaims{"scope": scope}
+	if clientID, ok := claims["client_id"]; ok {
+		extra["client_id"] = clientID
+	}
+	token, err = utils.GenerateToken(user, s.config.JWTSecret, ttl, extra)
+	if err != nil {
+		return "", "", apperror.Internal(err, "Gagal menghasilkan token")
+	}
+	return token, scope, nil
+}
+
+// Profile mengambil pengguna pemilik token berdasarkan klaim ID dan email.
+func (s *AuthService) Profile(ctx context.Context, userID uint, email string) (model.User, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil || user.Email != email {
+		return model.User{}, apperror.NotFound(apperror.CodeUserNotFound, "Pengguna tidak ditemukan")
+	}
+	return user, nil
+}
+
+// Logout membatalkan token sehingga tidak bisa dipakai lagi. Token akses pribadi langsung dihapus.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	if s.apiTokens != nil && IsAPIToken(token) {
+		return s.apiTokens.RevokeToken(ctx, token)
+	}
+	if err := s.tokens.Revoke(ctx, token); err != nil {
+		return apperror.Internal(err, "Failed to revoke token")
+	}
+	metrics.TokenRevocations.Inc()
+	return nil
+}
+
+// Authorize memvalidasi token (belum dibatalkan, tanda tangan valid, belum kedaluwarsa, akun aktif)
+// dan mengembalikan klaimnya beserta pengguna pemilik token.
+func (s *AuthService) Authorize(ctx context.Context, token string) (jwt.MapClaims, model.User, error) {
+	if s.apiTokens != nil && IsAPIToken(token) {
+		return s.apiTokens.Authorize(ctx, token)
+	}
+
+	// Cek jika token telah dibatalkan di dalam basis data
+	revoked, err := s.tokens.IsRevoked(ctx, token)
+	if err != nil {
+		return nil, model.User{}, apperror.Internal(err, "Failed to check token")
+	}
+	if revoked {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenRevoked, "Token has been revoked")
+	}
+
+	claims, err := utils.VerifyToken(token, s.config.JWTSecret)
+	if err != nil {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid or expired token")
+	}
+
+	// Check if token is expired
+	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(time.Now()) {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenExpired, "Token has expired")
+	}
+
+	// Token hanya berlaku selama akun pemiliknya masih aktif
+	userID, _ := claims["user_id"].(float64)
+	user, err := s.users.FindByID(ctx, uint(userID))
+	if err != nil {
+		return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "User no longer exists")
+	}
+	if user.Status != model.StatusActive {
+		return nil, model.User{}, apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status))
+	}
+
+	// Token impersonation juga bergantung pada admin yang menerbitkannya
+	if IsImpersonation(claims) {
+		if s.impersonation == nil {
+			return nil, model.User{}, apperror.Unauthorized(apperror.CodeTokenInvalid, "Impersonation tokens are not accepted")
+		}
+		if err := s.impersonation.VerifyActor(ctx, claims); err != nil {
+			return nil, model.User{}, err
+		}
+	}
+
+	return claims, user, nil
+}
+
+// newUser membentuk model.User baru dari DTO dengan password yang sudah di-hash.
+func newUser(req model.UserRequestDTO) model.User {
+	return model.User{
+		Email:        strings.TrimSpace(req.Email),
+		PasswordHash: utils.GeneratePassword(req.Password),
+		Fullname:     req.Fullname,
+		Address:      req.Address,
+		Gender:       req.Gender,
+		PhoneNumber:  req.PhoneNumber,
+		Role:         model.RoleUser,
+		Status:       model.StatusActive,
+		Version:      1,
+	}
+}
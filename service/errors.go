@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VersionConflictError adalah error 412 ketika versi pengguna tidak cocok.
+func VersionConflictError() error {
+	return apperror.Wrap(repository.ErrVersionConflict, fiber.StatusPreconditionFailed, apperror.CodeVersionConflict,
+		"User has been modified, refetch and retry")
+}
+
+// emailExistsError adalah error 409 ketika email (tanpa membedakan huruf besar/kecil) sudah dipakai.
+func emailExistsError() error {
+	return apperror.Conflict(apperror.CodeEmailExists, "Email already exists")
+}
+
+// userLookupError menerjemahkan error repository saat mencari pengguna.
+func userLookupError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.NotFound(apperror.CodeUserNotFound, "User not found")
+	}
+	return apperror.Internal(err, "Failed to fetch user data")
+}
+
+// userWriteError menerjemahkan error repository saat menyimpan atau menghapus pengguna.
+func userWriteError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return VersionConflictError()
+	case errors.Is(err, repository.ErrDuplicate):
+		return emailExistsError()
+	default:
+		return apperror.Internal(err, message)
+	}
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/idp"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IdentityStateCookie adalah nama cookie yang membawa state alur login IdP dari start ke callback.
+const IdentityStateCookie = "identity_state"
+
+// IdentityStateTTL adalah waktu maksimal antara start dan callback.
+const IdentityStateTTL = 10 * time.Minute
+
+// IdentityService berisi aturan login lewat IdP eksternal: authorization code dengan PKCE ke
+// IdP, penautan identitas ke akun, dan provisioning akun just-in-time.
+type IdentityService struct {
+	identities repository.IdentityRepository
+	users      repository.UserRepository
+	auth       config.AuthConfig
+	providers  map[string]*idp.Provider
+	names      []string
+	returnURLs []string
+	stateKey   []byte
+}
+
+// NewIdentityService membuat IdentityService untuk provider di cfg. URL callback default
+// dibentuk dari issuer (URL publik server). client nil berarti klien HTTP dengan idp.DefaultTimeout.
+func NewIdentityService(identities repository.IdentityRepository, users repository.UserRepository, auth config.AuthConfig,
+	cfg config.IdentityConfig, issuer string, client *http.Client) *IdentityService {
+	providers := make(map[string]*idp.Provider, len(cfg.Providers))
+	for name, provider := range cfg.Providers {
+		redirectURL := provider.RedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimSuffix(issuer, "/") + "/api/v1/auth/oauth/" + name + "/callback"
+		}
+		providers[name] = idp.NewProvider(name, provider, redirectURL, client)
+	}
+
+	// Kunci cookie state diturunkan dari JWT_SECRET agar tidak perlu konfigurasi tambahan
+	key := sha256.Sum256([]byte("identity-state:" + auth.JWTSecret))
+	return &IdentityService{
+		identities: identities,
+		users:      users,
+		auth:       auth,
+		providers:  providers,
+		names:      cfg.Names(),
+		returnURLs: cfg.ReturnURLs,
+		stateKey:   key[:],
+	}
+}
+
+// identityState adalah data alur login yang disimpan di cookie bertanda tangan HMAC antara
+// start dan callback, sehingga server tidak perlu menyimpan sesi.
+type identityState struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	Nonce    string `json:"n"`
+	UserID   uint   `json:"u,omitempty"` // Pengguna yang menautkan identitas; 0 untuk login
+	ReturnTo string `json:"r,omitempty"`
+	Expires  int64  `json:"e"`
+}
+
+// IdentityCallback adalah parameter query yang dikirim IdP ke callback.
+type IdentityCallback struct {
+	Code             string
+	State            string
+	Error            string
+	ErrorDescription string
+}
+
+// IdentityResult adalah hasil callback. ReturnTo terisi begitu state terbukti valid, juga
+// ketika callback gagal, agar error bisa dikirim kembali ke frontend.
+type IdentityResult struct {
+	ReturnTo string
+	Token    string // Kosong untuk alur penautan
+	Identity model.UserIdentity
+	Created  bool
+	Linked   bool
+}
+
+// Providers mengembalikan provider yang dikonfigurasi, terurut berdasarkan nama.
+func (s *IdentityService) Providers() []model.IdentityProviderDTO {
+	providers := make([]model.IdentityProviderDTO, 0, len(s.names))
+	for _, name := range s.names {
+		providers = append(providers, model.IdentityProviderDTO{Name: name, DisplayName: s.providers[name].DisplayName()})
+	}
+	return providers
+}
+
+// Start memulai alur authorization code ke IdP dan mengembalikan URL IdP beserta nilai cookie
+// state. userID bukan nol berarti identitas akan ditautkan ke akun tersebut, bukan login.
+func (s *IdentityService) Start(ctx context.Context, providerName string, userID uint, returnTo string) (string, string, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+	if returnTo != "" && !slices.Contains(s.returnURLs, returnTo) {
+		return "", "", apperror.BadRequest(apperror.CodeValidationFailed, "return_to is not an allowed return URL")
+	}
+
+	state := identityState{
+		Provider: providerName,
+		State:    randomToken(32),
+		Verifier: randomToken(32),
+		Nonce:    randomToken(16),
+		UserID:   userID,
+		ReturnTo: returnTo,
+		Expires:  time.Now().Add(IdentityStateTTL).Unix(),
+	}
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	authURL, err := provider.AuthCodeURL(ctx, state.State, state.Nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", identityProviderError(err)
+	}
+	return authURL, s.sealState(state), nil
+}
+
+// Callback menyelesaikan alur dari Start: memeriksa state, menukar kode di IdP, lalu login,
+// menautkan, atau membuat akun sesuai aturan provider.
+func (s *IdentityService) Callback(ctx context.Context, providerName string, params IdentityCallback, cookie string) (IdentityResult, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return IdentityResult{}, err
+	}
+
+	state, ok := s.openState(cookie)
+	if !ok || state.Provider != providerName ||
+		subtle.ConstantTimeCompare([]byte(state.State), []byte(params.State)) != 1 {
+		return IdentityResult{}, apperror.BadRequest(apperror.CodeInvalidState,
+			"Login state is missing, expired, or does not match; start the login again")
+	}
+	result := IdentityResult{ReturnTo: state.ReturnTo}
+
+	if params.Error != "" {
+		message := "The identity provider did not authorize the login"
+		if params.ErrorDescription != "" {
+			message += ": " + params.ErrorDescription
+		}
+		return result, apperror.Forbidden(apperror.CodeAccessDenied, message).With("provider_error", params.Error)
+	}
+	if params.Code == "" {
+		return result, apperror.BadRequest(apperror.CodeInvalidRequest, "code is required")
+	}
+
+	claims, err := provider.Exchange(ctx, params.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		return result, identityProviderError(err)
+	}
+
+	if state.UserID != 0 {
+		identity, linked, err := s.linkUser(ctx, provider, claims, state.UserID)
+		result.Identity, result.Linked = identity, linked
+		return result, err
+	}
+
+	user, identity, err := s.resolveUser(ctx, provider, claims, &result)
+	if err != nil {
+		return result, err
+	}
+	result.Identity = identity
+
+	// Hanya akun dengan status aktif yang boleh login, sama seperti login dengan password
+	if user.Status != model.StatusActive {
+		return result, apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status)).
+			With("account_status", user.Status)
+	}
+	result.Token, err = utils.GenerateToken(user, s.auth.JWTSecret, s.auth.TokenTTL)
+	if err != nil {
+		return result, apperror.Internal(err, "Gagal menghasilkan token")
+	}
+	return result, nil
+}
+
+// resolveUser mencari pengguna untuk login: identitas yang sudah tertaut, akun dengan email
+// terverifikasi yang sama (jika LinkByEmail), atau akun baru (jika AutoProvision).
+func (s *IdentityService) resolveUser(ctx context.Context, provider *idp.Provider, claims idp.Claims,
+	result *IdentityResult) (model.User, model.UserIdentity, error) {
+	identity, user, err := s.findLinked(ctx, provider.Name(), claims.Subject)
+	if err == nil {
+		return user, identity, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return model.User{}, model.UserIdentity{}, err
+	}
+
+	rules := provider.Config()
+	if claims.EmailVerified {
+		existing, err := s.users.FindByEmail(ctx, claims.Email)
+		switch {
+		case err == nil && rules.LinkByEmail:
+			identity, err := s.createIdentity(ctx, provider.Name(), claims, existing.ID)
+			result.Linked = err == nil
+			return existing, identity, err
+		case err == nil:
+			return model.User{}, model.UserIdentity{}, apperror.Conflict(apperror.CodeEmailExists,
+				"An account with this email already exists; sign in with your password and link "+provider.DisplayName()+" from your account")
+		case !errors.Is(err, repository.ErrNotFound):
+			return model.User{}, model.UserIdentity{}, apperror.Internal(err, "Failed to fetch user data")
+		}
+	}
+
+	if !rules.AutoProvision {
+		return model.User{}, model.UserIdentity{}, apperror.Forbidden(apperror.CodeProvisioningDisabled,
+			"No account is linked to this "+provider.DisplayName()+" identity")
+	}
+	if !claims.EmailVerified {
+		return model.User{}, model.UserIdentity{}, apperror.Forbidden(apperror.CodeProvisioningDisabled,
+			provider.DisplayName()+" did not return a verified email address")
+	}
+	if !allowedDomain(rules.AllowedDomains, claims.Email) {
+		return model.User{}, model.UserIdentity{}, apperror.Forbidden(apperror.CodeProvisioningDisabled,
+			"Accounts with this email domain cannot sign up with "+provider.DisplayName())
+	}
+
+	user, identity, err = s.provision(ctx, provider.Name(), claims)
+	result.Created, result.Linked = err == nil, err == nil
+	return user, identity, err
+}
+
+// provision membuat akun tanpa password beserta identitasnya. Pengguna bisa menambahkan
+// password atau provider lain setelah login.
+func (s *IdentityService) provision(ctx context.Context, providerName string, claims idp.Claims) (model.User, model.UserIdentity, error) {
+	fullname := strings.TrimSpace(claims.Name)
+	if fullname == "" {
+		fullname, _, _ = strings.Cut(claims.Email, "@")
+	}
+	user := model.User{
+		Email:    claims.Email,
+		Fullname: fullname,
+		Role:     model.RoleUser,
+		Status:   model.StatusActive,
+		Version:  1,
+	}
+	if err := s.users.Create(ctx, &user); err != nil {
+		return model.User{}, model.UserIdentity{}, userWriteError(err, "Failed to create user")
+	}
+
+	identity, err := s.createIdentity(ctx, providerName, claims, user.ID)
+	if err != nil {
+		// Callback lain untuk subject yang sama menang; akun yang baru dibuat dibatalkan
+		_ = s.users.DeleteVersioned(ctx, user.ID, user.Version)
+		return model.User{}, model.UserIdentity{}, err
+	}
+	return user, identity, nil
+}
+
+// linkUser menautkan identitas ke akun yang memulai alur dari POST .../link.
+func (s *IdentityService) linkUser(ctx context.Context, provider *idp.Provider, claims idp.Claims, userID uint) (model.UserIdentity, bool, error) {
+	identity, _, err := s.findLinked(ctx, provider.Name(), claims.Subject)
+	switch {
+	case err == nil && identity.UserID == userID:
+		return identity, false, nil
+	case err == nil:
+		return model.UserIdentity{}, false, apperror.Conflict(apperror.CodeIdentityLinked,
+			"This "+provider.DisplayName()+" identity is already linked to another account")
+	case !errors.Is(err, repository.ErrNotFound):
+		return model.UserIdentity{}, false, err
+	}
+
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		return model.UserIdentity{}, false, userLookupError(err)
+	}
+	identity, err = s.createIdentity(ctx, provider.Name(), claims, userID)
+	return identity, err == nil, err
+}
+
+// findLinked mencari identitas beserta pemiliknya. Identitas milik akun yang sudah dihapus
+// dilepas dan dilaporkan sebagai ErrNotFound.
+func (s *IdentityService) findLinked(ctx context.Context, providerName, subject string) (model.UserIdentity, model.User, error) {
+	identity, err := s.identities.Find(ctx, providerName, subject)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.UserIdentity{}, model.User{}, err
+	}
+	if err != nil {
+		return model.UserIdentity{}, model.User{}, apperror.Internal(err, "Failed to fetch identity")
+	}
+
+	user, err := s.users.FindByID(ctx, identity.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if err := s.identities.Delete(ctx, identity.UserID, providerName); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return model.UserIdentity{}, model.User{}, apperror.Internal(err, "Failed to remove stale identity")
+		}
+		return model.UserIdentity{}, model.User{}, repository.ErrNotFound
+	}
+	if err != nil {
+		return model.UserIdentity{}, model.User{}, apperror.Internal(err, "Failed to fetch user data")
+	}
+	return identity, user, nil
+}
+
+func (s *IdentityService) createIdentity(ctx context.Context, providerName string, claims idp.Claims, userID uint) (model.UserIdentity, error) {
+	identity := model.UserIdentity{UserID: userID, Provider: providerName, Subject: claims.Subject, Email: claims.Email}
+	if err := s.identities.Create(ctx, &identity); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.UserIdentity{}, apperror.Conflict(apperror.CodeIdentityLinked,
+				"The account already has a linked "+providerName+" identity, or this identity was linked concurrently")
+		}
+		return model.UserIdentity{}, apperror.Internal(err, "Failed to link identity")
+	}
+	return identity, nil
+}
+
+// Identities mengembalikan identitas eksternal yang tertaut ke pengguna.
+func (s *IdentityService) Identities(ctx context.Context, userID uint) ([]model.UserIdentity, error) {
+	identities, err := s.identities.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch identities")
+	}
+	return identities, nil
+}
+
+// Unlink melepas identitas provider dari pengguna. Identitas terakhir milik akun tanpa password
+// tidak boleh dilepas karena akun tidak bisa login lagi.
+func (s *IdentityService) Unlink(ctx context.Context, user model.User, providerName string) error {
+	identities, err := s.Identities(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, identity := range identities {
+		found = found || identity.Provider == providerName
+	}
+	if !found {
+		return apperror.NotFound(apperror.CodeIdentityNotFound, "No "+providerName+" identity is linked to this account")
+	}
+	if user.PasswordHash == "" && len(identities) == 1 {
+		return apperror.Conflict(apperror.CodeLastLoginMethod,
+			"This identity is the only way to sign in to the account; link another provider first")
+	}
+
+	if err := s.identities.Delete(ctx, user.ID, providerName); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFound(apperror.CodeIdentityNotFound, "No "+providerName+" identity is linked to this account")
+		}
+		return apperror.Internal(err, "Failed to unlink identity")
+	}
+	return nil
+}
+
+func (s *IdentityService) provider(name string) (*idp.Provider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, apperror.NotFound(apperror.CodeProviderNotFound, "Identity provider "+name+" is not configured")
+	}
+	return provider, nil
+}
+
+// sealState membentuk nilai cookie: JSON base64url, titik, lalu HMAC-SHA256-nya.
+func (s *IdentityService) sealState(state identityState) string {
+	payload, _ := json.Marshal(state)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signState(encoded)
+}
+
+// openState memverifikasi tanda tangan dan masa berlaku cookie state.
+func (s *IdentityService) openState(cookie string) (identityState, bool) {
+	encoded, signature, ok := strings.Cut(cookie, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signState(encoded))) {
+		return identityState{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return identityState{}, false
+	}
+	var state identityState
+	if err := json.Unmarshal(payload, &state); err != nil || time.Now().Unix() > state.Expires {
+		return identityState{}, false
+	}
+	return state, true
+}
+
+func (s *IdentityService) signState(encoded string) string {
+	mac := hmac.New(sha256.New, s.stateKey)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// identityProviderError adalah error 502 ketika IdP tidak bisa dihubungi atau responsnya tidak valid.
+// Detail error hanya dicatat di log.
+func identityProviderError(err error) error {
+	return apperror.Wrap(err, fiber.StatusBadGateway, apperror.CodeIdentityProviderError,
+		"The identity provider could not complete the login")
+}
+
+// allowedDomain memeriksa domain email terhadap daftar domain; daftar kosong mengizinkan semua.
+func allowedDomain(domains []string, email string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	for _, allowed := range domains {
+		if strings.EqualFold(strings.TrimPrefix(allowed, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
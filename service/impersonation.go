@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/logging"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ClaimActor adalah klaim token impersonation yang berisi admin di baliknya (RFC 8693 bagian 4.1).
+const ClaimActor = "act"
+
+const (
+	maxImpersonationReasonLength = 500
+	defaultAuditLimit            = 100
+	maxAuditLimit                = 500
+)
+
+// Impersonation adalah sesi impersonation yang baru dimulai.
+type Impersonation struct {
+	Token     string
+	SessionID string
+	ExpiresAt time.Time
+	User      model.User
+}
+
+// ImpersonationService menerbitkan token berumur pendek bagi admin untuk bertindak sebagai
+// pengguna lain. Mulai, setiap request, dan akhir sesi dicatat di audit trail; jika pencatatan
+// gagal, request ditolak.
+type ImpersonationService struct {
+	audit  repository.AuditRepository
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+	auth   config.AuthConfig
+	config config.ImpersonationConfig
+}
+
+// NewImpersonationService membuat ImpersonationService.
+func NewImpersonationService(audit repository.AuditRepository, users repository.UserRepository,
+	tokens repository.TokenRepository, auth config.AuthConfig, cfg config.ImpersonationConfig) *ImpersonationService {
+	return &ImpersonationService{audit: audit, users: users, tokens: tokens, auth: auth, config: cfg}
+}
+
+// IsImpersonation memeriksa apakah klaim berasal dari token impersonation.
+func IsImpersonation(claims jwt.MapClaims) bool {
+	_, ok := claims[ClaimActor]
+	return ok
+}
+
+// ActorFromClaims membaca admin di balik token impersonation dari klaim act.
+func ActorFromClaims(claims jwt.MapClaims) (model.Actor, bool) {
+	act, ok := claims[ClaimActor].(map[string]interface{})
+	if !ok {
+		return model.Actor{}, false
+	}
+	userID, okID := act["user_id"].(float64)
+	email, okEmail := act["email"].(string)
+	session, okSession := act["session_id"].(string)
+	if !okID || !okEmail || !okSession || session == "" {
+		return model.Actor{}, false
+	}
+	return model.Actor{UserID: uint(userID), Email: email, SessionID: session}, true
+}
+
+// Start memulai impersonation pengguna targetID oleh actor. Hanya admin di impersonation.admins
+// yang boleh melakukannya, dan admin lain tidak bisa di-impersonate.
+func (s *ImpersonationService) Start(ctx context.Context, actor model.User, targetID uint,
+	req model.ImpersonationRequestDTO, ip string) (Impersonation, error) {
+	if actor.Role != model.RoleAdmin || !s.config.Allows(actor.Email) {
+		return Impersonation{}, apperror.Forbidden(apperror.CodeImpersonationNotAllowed,
+			"Your account is not allowed to impersonate users")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxImpersonationReasonLength {
+		return Impersonation{}, apperror.BadRequest(apperror.CodeValidationFailed,
+			"Reason is required and must be at most 500 characters long")
+	}
+	if targetID == actor.ID {
+		return Impersonation{}, apperror.Forbidden(apperror.CodeImpersonationNotAllowed, "You cannot impersonate yourself")
+	}
+
+	target, err := s.users.FindByID(ctx, targetID)
+	if err != nil {
+		return Impersonation{}, userLookupError(err)
+	}
+	if target.Role == model.RoleAdmin {
+		return Impersonation{}, apperror.Forbidden(apperror.CodeImpersonationNotAllowed, "Administrators cannot be impersonated")
+	}
+	if target.Status != model.StatusActive {
+		return Impersonation{}, apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(target.Status)).
+			With("account_status", target.Status)
+	}
+
+	// Awal sesi dicatat sebelum token diterbitkan
+	session := randomToken(16)
+	if err := s.record(ctx, model.AuditImpersonationStart, actor.ID, target.ID, session, reason, ip); err != nil {
+		return Impersonation{}, err
+	}
+
+	token, err := utils.GenerateToken(target, s.auth.JWTSecret, s.config.TTL, jwt.MapClaims{
+		ClaimActor: map[string]interface{}{"user_id": actor.ID, "email": actor.Email, "session_id": session},
+	})
+	if err != nil {
+		return Impersonation{}, apperror.Internal(err, "Failed to issue token")
+	}
+	slog.InfoContext(ctx, "impersonation started", "actor_id", actor.ID, "user_id", target.ID, "session_id", session)
+
+	return Impersonation{Token: token, SessionID: session, ExpiresAt: time.Now().Add(s.config.TTL), User: target}, nil
+}
+
+// VerifyActor memastikan admin di balik token impersonation masih aktif dan masih boleh
+// melakukan impersonation, sehingga mencabut hak admin langsung menghentikan sesinya.
+func (s *ImpersonationService) VerifyActor(ctx context.Context, claims jwt.MapClaims) error {
+	denied := apperror.Unauthorized(apperror.CodeTokenInvalid, "The administrator behind this token may no longer impersonate users")
+
+	actor, ok := ActorFromClaims(claims)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid act claim")
+	}
+	admin, err := s.users.FindByID(ctx, actor.UserID)
+	if err != nil {
+		return denied
+	}
+	if admin.Email != actor.Email || admin.Role != model.RoleAdmin || admin.Status != model.StatusActive ||
+		!s.config.Allows(admin.Email) {
+		return denied
+	}
+	return nil
+}
+
+// RecordRequest mencatat satu request (method dan path) yang dibuat dengan token impersonation.
+func (s *ImpersonationService) RecordRequest(ctx context.Context, claims jwt.MapClaims, request, ip string) error {
+	actor, ok := ActorFromClaims(claims)
+	if !ok {
+		return apperror.Unauthorized(apperror.CodeTokenInvalid, "Invalid act claim")
+	}
+	userID, _ := claims["user_id"].(float64)
+	return s.record(ctx, model.AuditImpersonationRequest, actor.UserID, uint(userID), actor.SessionID, request, ip)
+}
+
+// End mengakhiri sesi impersonation: token dibatalkan dan akhir sesi dicatat.
+func (s *ImpersonationService) End(ctx context.Context, claims jwt.MapClaims, token, ip string) error {
+	actor, ok := ActorFromClaims(claims)
+	if !ok {
+		return apperror.BadRequest(apperror.CodeNotImpersonating, "This token is not an impersonation token")
+	}
+
+	if err := s.tokens.Revoke(ctx, token); err != nil {
+		return apperror.Internal(err, "Failed to revoke token")
+	}
+	userID, _ := claims["user_id"].(float64)
+	if err := s.record(ctx, model.AuditImpersonationEnd, actor.UserID, uint(userID), actor.SessionID, "", ip); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "impersonation ended", "actor_id", actor.UserID, "user_id", uint(userID), "session_id", actor.SessionID)
+	return nil
+}
+
+// Events mengembalikan catatan audit trail terbaru yang cocok dengan filter.
+func (s *ImpersonationService) Events(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		return nil, apperror.BadRequest(apperror.CodeValidationFailed, "limit must be at most 500")
+	}
+
+	events, err := s.audit.List(ctx, filter)
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch audit events")
+	}
+	return events, nil
+}
+
+// record menyimpan satu catatan audit beserta ID request dari context.
+func (s *ImpersonationService) record(ctx context.Context, action string, actorID, subjectID uint, session, detail, ip string) error {
+	err := s.audit.Record(ctx, &model.AuditEvent{
+		Action:    action,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		SessionID: session,
+		Detail:    detail,
+		IP:        ip,
+		RequestID: logging.RequestID(ctx),
+	})
+	if err != nil {
+		return apperror.Internal(err, "Failed to record audit event")
+	}
+	return nil
+}
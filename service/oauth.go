@@ -0,0 +1,588 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/oidc"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt"
+)
+
+// TokenTypeBearer adalah token_type untuk semua token yang diterbitkan.
+const TokenTypeBearer = "Bearer"
+
+// maxNonceLength sesuai ukuran kolom nonce di oauth_authorization_codes.
+const maxNonceLength = 255
+
+var (
+	// scopePattern membatasi karakter nama scope (RFC 6749 bagian 3.3, disederhanakan).
+	scopePattern = regexp.MustCompile(`^[A-Za-z0-9_:.\-]+$`)
+	// pkceChallengePattern mencocokkan base64url tanpa padding dari SHA-256 (43 karakter).
+	pkceChallengePattern = regexp.MustCompile(`^[A-Za-z0-9_\-]{43}$`)
+	// pkceVerifierPattern mencocokkan code_verifier sesuai RFC 7636 bagian 4.1.
+	pkceVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9._~\-]{43,128}$`)
+)
+
+// OAuthService berisi aturan server otorisasi OAuth2: pendaftaran klien, authorization code
+// dengan PKCE, client credentials, serta introspection dan revocation token.
+type OAuthService struct {
+	oauth  repository.OAuthRepository
+	users  repository.UserRepository
+	tokens repository.TokenRepository
+	auth   config.AuthConfig
+	config config.OAuthConfig
+	signer *oidc.Signer // Penanda tangan ID token OpenID Connect
+}
+
+// NewOAuthService membuat OAuthService. Token akses ditandatangani dengan kunci JWT yang sama
+// dengan login biasa sehingga bisa dipakai langsung untuk memanggil API; ID token ditandatangani
+// signer agar bisa diverifikasi aplikasi lain lewat JWKS.
+func NewOAuthService(oauth repository.OAuthRepository, users repository.UserRepository, tokens repository.TokenRepository,
+	auth config.AuthConfig, cfg config.OAuthConfig, signer *oidc.Signer) *OAuthService {
+	return &OAuthService{oauth: oauth, users: users, tokens: tokens, auth: auth, config: cfg, signer: signer}
+}
+
+// AuthorizationError adalah error authorization endpoint setelah klien dan redirect_uri terbukti
+// valid, sehingga dikirim kembali ke klien lewat redirect (RFC 6749 bagian 4.1.2.1).
+type AuthorizationError struct {
+	RedirectURI string
+	State       string
+	Err         *apperror.Error
+}
+
+func (e *AuthorizationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthorizationError) Unwrap() error {
+	return e.Err
+}
+
+// Location membentuk URL redirect yang membawa error, error_description, dan state.
+func (e *AuthorizationError) Location() string {
+	params := url.Values{"error": {e.Err.Code}, "error_description": {e.Err.Message}}
+	if e.State != "" {
+		params.Set("state", e.State)
+	}
+	return withQuery(e.RedirectURI, params)
+}
+
+// oauthError membuat error OAuth2 dengan kode dari RFC 6749.
+func oauthError(status int, code, message string) *apperror.Error {
+	return apperror.New(status, code, message)
+}
+
+// RegisterClient mendaftarkan klien baru dan mengembalikan client secret dalam bentuk asli.
+// Secret hanya tersedia saat ini; yang disimpan hanyalah hash-nya.
+func (s *OAuthService) RegisterClient(ctx context.Context, req model.OAuthClientRequestDTO) (model.OAuthClient, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed, "Client name is required")
+	}
+
+	grants := uniqueFields(req.GrantTypes)
+	if len(grants) == 0 {
+		return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed, "At least one grant type is required")
+	}
+	for _, grant := range grants {
+		switch grant {
+		case model.GrantAuthorizationCode:
+			if len(req.RedirectURIs) == 0 {
+				return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+					"authorization_code requires at least one redirect URI")
+			}
+		case model.GrantClientCredentials:
+			if !req.Confidential {
+				return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+					"client_credentials is only available to confidential clients")
+			}
+		default:
+			return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+				fmt.Sprintf("Unsupported grant type %q", grant))
+		}
+	}
+
+	redirectURIs := uniqueFields(req.RedirectURIs)
+	for _, uri := range redirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed, err.Error())
+		}
+	}
+
+	scopes := uniqueFields(req.Scopes)
+	for _, scope := range scopes {
+		if !scopePattern.MatchString(scope) {
+			return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeValidationFailed,
+				fmt.Sprintf("Invalid scope %q", scope))
+		}
+	}
+
+	client := model.OAuthClient{
+		ClientID:     randomToken(16),
+		Name:         name,
+		RedirectURIs: strings.Join(redirectURIs, " "),
+		GrantTypes:   strings.Join(grants, " "),
+		Scopes:       strings.Join(scopes, " "),
+	}
+	var secret string
+	if req.Confidential {
+		secret = randomToken(32)
+		client.SecretHash = utils.GeneratePassword(secret)
+	}
+
+	if err := s.oauth.CreateClient(ctx, &client); err != nil {
+		return model.OAuthClient{}, "", apperror.Internal(err, "Failed to register client")
+	}
+	return client, secret, nil
+}
+
+// ListClients mengembalikan semua klien yang terdaftar.
+func (s *OAuthService) ListClients(ctx context.Context) ([]model.OAuthClient, error) {
+	clients, err := s.oauth.ListClients(ctx)
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch clients")
+	}
+	return clients, nil
+}
+
+// Authorize memvalidasi permintaan authorization code dan mengembalikan klien beserta scope
+// efektif (scope terdaftar klien jika tidak diminta). Klien atau redirect_uri yang tidak
+// valid menghasilkan *apperror.Error biasa karena tidak boleh di-redirect; error lain
+// berupa *AuthorizationError.
+func (s *OAuthService) Authorize(ctx context.Context, req model.OAuthAuthorizationRequest) (model.OAuthClient, string, error) {
+	client, err := s.oauth.FindClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeInvalidClient, "Unknown client")
+		}
+		return model.OAuthClient{}, "", apperror.Internal(err, "Failed to fetch client")
+	}
+	if req.RedirectURI == "" || !client.AllowsRedirectURI(req.RedirectURI) {
+		return model.OAuthClient{}, "", apperror.BadRequest(apperror.CodeInvalidRequest,
+			"redirect_uri is not registered for this client")
+	}
+
+	fail := func(code, message string) (model.OAuthClient, string, error) {
+		return model.OAuthClient{}, "", &AuthorizationError{
+			RedirectURI: req.RedirectURI,
+			State:       req.State,
+			Err:         oauthError(fiber.StatusBadRequest, code, message),
+		}
+	}
+	switch {
+	case req.ResponseType != "code":
+		return fail(apperror.CodeUnsupportedResponseType, "response_type must be code")
+	case !client.AllowsGrant(model.GrantAuthorizationCode):
+		return fail(apperror.CodeUnauthorizedClient, "Client may not use the authorization code grant")
+	case req.CodeChallengeMethod != model.PKCEMethodS256:
+		return fail(apperror.CodeInvalidRequest, "code_challenge_method must be S256")
+	case !pkceChallengePattern.MatchString(req.CodeChallenge):
+		return fail(apperror.CodeInvalidRequest, "code_challenge is required and must be a base64url SHA-256 hash")
+	case len(req.Nonce) > maxNonceLength:
+		return fail(apperror.CodeInvalidRequest, fmt.Sprintf("nonce must be at most %d characters", maxNonceLength))
+	}
+
+	scope := normalizeScope(req.Scope)
+	if scope == "" {
+		scope = client.Scopes
+	}
+	if !client.AllowsScope(scope) {
+		return fail(apperror.CodeInvalidScope, "Requested scope is not allowed for this client")
+	}
+	return client, scope, nil
+}
+
+// Approve menerbitkan kode otorisasi untuk user yang menyetujui permintaan dan mengembalikan
+// URL redirect ke klien yang membawa code dan state.
+func (s *OAuthService) Approve(ctx context.Context, req model.OAuthAuthorizationRequest, user model.User) (string, error) {
+	client, scope, err := s.Authorize(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	// Seperti login password, scope API dibatasi role pengguna: scope bawaan klien dipersempit,
+	// scope yang diminta tetapi tidak tersedia untuk role-nya ditolak
+	if allowed := roleScope(user.Role, scope); normalizeScope(req.Scope) == "" {
+		scope = allowed
+	} else if allowed != scope {
+		return "", &AuthorizationError{
+			RedirectURI: req.RedirectURI,
+			State:       req.State,
+			Err:         oauthError(fiber.StatusBadRequest, apperror.CodeInvalidScope, "Requested scope is not available to your account"),
+		}
+	}
+
+	code := randomToken(32)
+	if err := s.oauth.SaveAuthorizationCode(ctx, &model.OAuthAuthorizationCode{
+		CodeHash:            hashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               scope,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		ExpiresAt:           time.Now().Add(s.config.CodeTTL),
+	}); err != nil {
+		return "", apperror.Internal(err, "Failed to issue authorization code")
+	}
+
+	params := url.Values{"code": {code}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return withQuery(req.RedirectURI, params), nil
+}
+
+// Deny mengembalikan URL redirect dengan error access_denied ketika user menolak permintaan.
+func (s *OAuthService) Deny(ctx context.Context, req model.OAuthAuthorizationRequest) (string, error) {
+	if _, _, err := s.Authorize(ctx, req); err != nil {
+		return "", err
+	}
+	denied := &AuthorizationError{
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+		Err:         oauthError(fiber.StatusForbidden, apperror.CodeAccessDenied, "The user denied the request"),
+	}
+	return denied.Location(), nil
+}
+
+// AuthenticateClient memeriksa kredensial klien di token, introspection, dan revocation endpoint.
+// Klien confidential wajib mengirim secret; public client hanya mengirim client_id.
+func (s *OAuthService) AuthenticateClient(ctx context.Context, clientID, secret string) (model.OAuthClient, error) {
+	invalid := oauthError(fiber.StatusUnauthorized, apperror.CodeInvalidClient, "Client authentication failed")
+	if clientID == "" {
+		return model.OAuthClient{}, invalid
+	}
+
+	client, err := s.oauth.FindClient(ctx, clientID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return model.OAuthClient{}, invalid
+	}
+	if err != nil {
+		return model.OAuthClient{}, apperror.Internal(err, "Failed to fetch client")
+	}
+
+	if client.Confidential() {
+		if !utils.ComparePassword(ctx, client.SecretHash, secret) {
+			return model.OAuthClient{}, invalid
+		}
+	} else if secret != "" {
+		return model.OAuthClient{}, invalid
+	}
+	return client, nil
+}
+
+// Token menjalankan grant yang diminta untuk klien yang sudah diautentikasi.
+func (s *OAuthService) Token(ctx context.Context, client model.OAuthClient, req model.OAuthTokenRequest) (model.OAuthTokenResponse, error) {
+	switch req.GrantType {
+	case model.GrantAuthorizationCode:
+		return s.exchangeCode(ctx, client, req)
+	case model.GrantClientCredentials:
+		return s.clientCredentials(client, req)
+	case "":
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeInvalidRequest, "grant_type is required")
+	default:
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeUnsupportedGrantType,
+			"Unsupported grant type")
+	}
+}
+
+// exchangeCode menukar kode otorisasi dengan token akses atas nama pengguna.
+func (s *OAuthService) exchangeCode(ctx context.Context, client model.OAuthClient, req model.OAuthTokenRequest) (model.OAuthTokenResponse, error) {
+	if !client.AllowsGrant(model.GrantAuthorizationCode) {
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeUnauthorizedClient,
+			"Client may not use the authorization code grant")
+	}
+
+	invalid := func(message string) (model.OAuthTokenResponse, error) {
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeInvalidGrant, message)
+	}
+
+	// Kode dihapus saat dibaca sehingga percobaan kedua selalu gagal
+	code, err := s.oauth.ConsumeAuthorizationCode(ctx, hashToken(req.Code))
+	if errors.Is(err, repository.ErrNotFound) {
+		return invalid("Authorization code is invalid or has already been used")
+	}
+	if err != nil {
+		return model.OAuthTokenResponse{}, apperror.Internal(err, "Failed to redeem authorization code")
+	}
+
+	switch {
+	case code.ClientID != client.ClientID:
+		return invalid("Authorization code was issued to another client")
+	case time.Now().After(code.ExpiresAt):
+		return invalid("Authorization code has expired")
+	case code.RedirectURI != req.RedirectURI:
+		return invalid("redirect_uri does not match the authorization request")
+	case !verifyPKCE(code.CodeChallenge, req.CodeVerifier):
+		return invalid("code_verifier does not match code_challenge")
+	}
+
+	// Klien boleh meminta token dengan scope yang lebih sempit dari yang disetujui pengguna
+	scope := code.Scope
+	if requested := normalizeScope(req.Scope); requested != "" {
+		for _, want := range strings.Fields(requested) {
+			if !model.HasScope(code.Scope, want) {
+				return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeInvalidScope,
+					"Requested scope exceeds the scope granted by the user")
+			}
+		}
+		scope = requested
+	}
+
+	user, err := s.users.FindByID(ctx, code.UserID)
+	if err != nil || user.Status != model.StatusActive {
+		return invalid("The user is no longer active")
+	}
+	// Role pengguna bisa berubah sejak kode diterbitkan
+	scope = roleScope(user.Role, scope)
+
+	token, err := utils.GenerateToken(user, s.auth.JWTSecret, s.config.AccessTokenTTL, jwt.MapClaims{
+		"client_id": client.ClientID,
+		"scope":     scope,
+	})
+	if err != nil {
+		return model.OAuthTokenResponse{}, apperror.Internal(err, "Failed to issue token")
+	}
+	resp := s.tokenResponse(token, scope)
+
+	// Scope openid menjadikan permintaan ini autentikasi OpenID Connect
+	if oidc.HasScope(scope, oidc.ScopeOpenID) {
+		resp.IDToken, err = s.signer.Sign(oidc.IDToken{
+			Issuer:   s.config.Issuer,
+			ClientID: client.ClientID,
+			User:     user,
+			Scope:    scope,
+			Nonce:    code.Nonce,
+			AuthTime: code.CreatedAt,
+			TTL:      s.config.IDTokenTTL,
+		}.Claims())
+		if err != nil {
+			return model.OAuthTokenResponse{}, apperror.Internal(err, "Failed to issue ID token")
+		}
+	}
+	return resp, nil
+}
+
+// clientCredentials menerbitkan token untuk klien confidential yang bertindak atas namanya sendiri.
+func (s *OAuthService) clientCredentials(client model.OAuthClient, req model.OAuthTokenRequest) (model.OAuthTokenResponse, error) {
+	if !client.Confidential() || !client.AllowsGrant(model.GrantClientCredentials) {
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeUnauthorizedClient,
+			"Client may not use the client credentials grant")
+	}
+
+	scope := normalizeScope(req.Scope)
+	if scope == "" {
+		scope = client.Scopes
+	}
+	if !client.AllowsScope(scope) {
+		return model.OAuthTokenResponse{}, oauthError(fiber.StatusBadRequest, apperror.CodeInvalidScope,
+			"Requested scope is not allowed for this client")
+	}
+
+	token, err := utils.GenerateClientToken(client.ClientID, scope, s.auth.JWTSecret, s.config.AccessTokenTTL)
+	if err != nil {
+		return model.OAuthTokenResponse{}, apperror.Internal(err, "Failed to issue token")
+	}
+	return s.tokenResponse(token, scope), nil
+}
+
+func (s *OAuthService) tokenResponse(token, scope string) model.OAuthTokenResponse {
+	return model.OAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   TokenTypeBearer,
+		ExpiresIn:   int64(s.config.AccessTokenTTL.Seconds()),
+		Scope:       scope,
+	}
+}
+
+// Introspect melaporkan status token (RFC 7662). Token yang dibatalkan, kedaluwarsa, tidak
+// valid, atau milik akun yang tidak aktif dilaporkan sebagai active=false tanpa detail lain.
+// Hanya klien confidential yang boleh melakukan introspection.
+func (s *OAuthService) Introspect(ctx context.Context, client model.OAuthClient, token string) (model.OAuthIntrospection, error) {
+	if !client.Confidential() {
+		return model.OAuthIntrospection{}, oauthError(fiber.StatusBadRequest, apperror.CodeUnauthorizedClient,
+			"Only confidential clients may introspect tokens")
+	}
+
+	inactive := model.OAuthIntrospection{Active: false}
+	revoked, err := s.tokens.IsRevoked(ctx, token)
+	if err != nil {
+		return model.OAuthIntrospection{}, apperror.Internal(err, "Failed to check token")
+	}
+	if revoked {
+		return inactive, nil
+	}
+
+	claims, err := utils.VerifyToken(token, s.auth.JWTSecret)
+	if err != nil {
+		return inactive, nil
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Unix(int64(exp), 0).Before(time.Now()) {
+		return inactive, nil
+	}
+
+	result := model.OAuthIntrospection{
+		Active:    true,
+		TokenType: TokenTypeBearer,
+		Exp:       int64(exp),
+	}
+	result.Scope, _ = claims["scope"].(string)
+	result.ClientID, _ = claims["client_id"].(string)
+	if issuedAt, ok := claims["issued_at"].(float64); ok {
+		result.Iat = int64(issuedAt)
+	}
+
+	if userID, ok := claims["user_id"].(float64); ok {
+		// Token pengguna hanya aktif selama akunnya aktif
+		user, err := s.users.FindByID(ctx, uint(userID))
+		if err != nil || user.Status != model.StatusActive {
+			return inactive, nil
+		}
+		result.Sub = strconv.FormatUint(uint64(user.ID), 10)
+		result.Username = user.Email
+		return result, nil
+	}
+
+	// Token client credentials hanya aktif selama kliennya masih terdaftar
+	if _, err := s.oauth.FindClient(ctx, result.ClientID); err != nil {
+		return inactive, nil
+	}
+	result.Sub, _ = claims["sub"].(string)
+	return result, nil
+}
+
+// Revoke membatalkan token yang diterbitkan untuk klien (RFC 7009). Token yang tidak valid
+// dianggap sudah dibatalkan dan tidak menghasilkan error.
+func (s *OAuthService) Revoke(ctx context.Context, client model.OAuthClient, token string) error {
+	claims, err := utils.VerifyToken(token, s.auth.JWTSecret)
+	if err != nil {
+		return nil
+	}
+	if owner, _ := claims["client_id"].(string); owner != client.ClientID {
+		return oauthError(fiber.StatusBadRequest, apperror.CodeUnauthorizedClient, "Token was not issued to this client")
+	}
+
+	if err := s.tokens.Revoke(ctx, token); err != nil {
+		return apperror.Internal(err, "Failed to revoke token")
+	}
+	metrics.TokenRevocations.Inc()
+	return nil
+}
+
+// PurgeExpiredCodes menghapus kode otorisasi yang kedaluwarsa tanpa pernah ditukar.
+func (s *OAuthService) PurgeExpiredCodes(ctx context.Context) (int64, error) {
+	return s.oauth.PurgeExpiredCodes(ctx, time.Now())
+}
+
+// verifyPKCE mencocokkan code_verifier dengan code_challenge S256 (RFC 7636 bagian 4.6).
+func verifyPKCE(challenge, verifier string) bool {
+	if !pkceVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// validateRedirectURI mewajibkan URI absolut tanpa fragment, memakai https kecuali untuk
+// alamat loopback (aplikasi lokal dan pengembangan).
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("redirect URI %q must be an absolute URL", raw)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not contain a fragment", raw)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("redirect URI %q must use https (http is only allowed for loopback addresses)", raw)
+}
+
+// withQuery menambahkan params ke query string uri tanpa membuang parameter yang sudah ada.
+func withQuery(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// roleScope membuang scope API yang tidak tersedia untuk role (model.RoleScopes) dari scope;
+// scope lain seperti openid tidak diubah.
+func roleScope(role, scope string) string {
+	allowed := model.RoleScopes(role)
+	kept := slices.DeleteFunc(strings.Fields(scope), func(s string) bool {
+		return model.IsAPIScope(s) && !slices.Contains(allowed, s)
+	})
+	return strings.Join(kept, " ")
+}
+
+// normalizeScope merapikan daftar scope yang dipisahkan spasi.
+func normalizeScope(scope string) string {
+	return strings.Join(uniqueFields(strings.Fields(scope)), " ")
+}
+
+// uniqueFields membuang nilai kosong dan duplikat tanpa mengubah urutan.
+func uniqueFields(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// randomToken menghasilkan n byte acak dalam base64url, untuk client ID, secret, dan kode.
+func randomToken(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// hashToken mengembalikan SHA-256 heksadesimal dari token yang disimpan di database.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
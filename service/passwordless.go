@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/mailer"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PasswordlessDeviceCookie adalah cookie HttpOnly berisi rahasia perangkat yang meminta login,
+// sehingga magic link yang dibuka di browser yang sama tidak perlu mengirim device_secret.
+const PasswordlessDeviceCookie = "login_device"
+
+// PasswordlessVerifyPath adalah endpoint verifikasi yang dibuka magic link jika
+// passwordless.link_url kosong.
+const PasswordlessVerifyPath = "/api/v1/auth/passwordless/verify"
+
+// PasswordlessService menangani login tanpa password: magic link atau kode 6 digit dikirim
+// lewat email dan hanya bisa dipakai sekali dari perangkat yang memintanya.
+type PasswordlessService struct {
+	challenges repository.LoginChallengeRepository
+	users      repository.UserRepository
+	mailer     mailer.Mailer
+	auth       config.AuthConfig
+	config     config.PasswordlessConfig
+	linkURL    string
+	secretKey  []byte
+}
+
+// NewPasswordlessService membuat PasswordlessService. issuer adalah URL publik server, dipakai
+// untuk magic link jika cfg.LinkURL kosong.
+func NewPasswordlessService(challenges repository.LoginChallengeRepository, users repository.UserRepository,
+	mail mailer.Mailer, auth config.AuthConfig, cfg config.PasswordlessConfig, issuer string) *PasswordlessService {
+	linkURL := cfg.LinkURL
+	if linkURL == "" {
+		linkURL = strings.TrimSuffix(issuer, "/") + PasswordlessVerifyPath
+	}
+	// Kode 6 digit mudah ditebak offline dari hash biasa, jadi disimpan sebagai HMAC dengan
+	// kunci turunan JWT_SECRET
+	key := sha256.Sum256([]byte("passwordless:" + auth.JWTSecret))
+	return &PasswordlessService{
+		challenges: challenges,
+		users:      users,
+		mailer:     mail,
+		auth:       auth,
+		config:     cfg,
+		linkURL:    linkURL,
+		secretKey:  key[:],
+	}
+}
+
+// Request membuat challenge login dan mengirim magic link atau kode ke email pengguna.
+// Email yang tidak terdaftar atau akun yang tidak aktif mendapat respons yang sama tanpa
+// email terkirim, agar endpoint ini tidak bisa dipakai untuk menebak akun. Challenge baru hanya
+// menggantikan challenge dari perangkat yang sama (req.DeviceSecret), sehingga permintaan dari
+// perangkat lain tidak bisa membatalkan atau memblokir challenge pengguna.
+func (s *PasswordlessService) Request(ctx context.Context, req model.PasswordlessRequestDTO) (model.PasswordlessChallengeDTO, error) {
+	method := req.Method
+	if method == "" {
+		method = model.LoginMethodLink
+	}
+	if method != model.LoginMethodLink && method != model.LoginMethodCode {
+		return model.PasswordlessChallengeDTO{}, apperror.BadRequest(apperror.CodeValidationFailed, "Method must be link or code")
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		return model.PasswordlessChallengeDTO{}, apperror.BadRequest(apperror.CodeValidationFailed, "A valid email is required")
+	}
+
+	resp := model.PasswordlessChallengeDTO{
+		ChallengeID:  randomToken(16),
+		Method:       method,
+		DeviceSecret: req.DeviceSecret,
+		ExpiresIn:    int64(s.config.TTL / time.Second),
+	}
+	if resp.DeviceSecret == "" {
+		resp.DeviceSecret = randomToken(32)
+	}
+
+	user, err := s.users.FindByEmail(ctx, req.Email)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && user.Status != model.StatusActive) {
+		return resp, nil
+	}
+	if err != nil {
+		return model.PasswordlessChallengeDTO{}, apperror.Internal(err, "Failed to fetch user data")
+	}
+
+	var secret string
+	if method == model.LoginMethodCode {
+		secret = randomCode()
+	} else {
+		secret = randomToken(32)
+	}
+
+	// Hanya challenge terbaru dari perangkat ini yang berlaku; link atau kode sebelumnya dibatalkan
+	if err := s.cancelDeviceChallenges(ctx, user.ID, hashToken(resp.DeviceSecret)); err != nil {
+		return model.PasswordlessChallengeDTO{}, apperror.Internal(err, "Failed to create login challenge")
+	}
+	challenge := model.LoginChallenge{
+		ChallengeID: resp.ChallengeID,
+		UserID:      user.ID,
+		Method:      method,
+		SecretHash:  s.hashSecret(resp.ChallengeID, secret),
+		DeviceHash:  hashToken(resp.DeviceSecret),
+		ExpiresAt:   time.Now().Add(s.config.TTL),
+	}
+	if err := s.challenges.Create(ctx, &challenge); err != nil {
+		return model.PasswordlessChallengeDTO{}, apperror.Internal(err, "Failed to create login challenge")
+	}
+
+	if err := s.mailer.Send(ctx, s.message(user, challenge, secret)); err != nil {
+		// Challenge tanpa email tidak bisa dipakai, jadi langsung dihapus
+		_ = s.challenges.Consume(ctx, challenge.ID)
+		return model.PasswordlessChallengeDTO{}, apperror.Internal(err, "Failed to send login email")
+	}
+	return resp, nil
+}
+
+// Verify menukar token magic link atau kode dengan JWT yang sama seperti login password.
+// deviceSecret harus sama dengan yang diterima perangkat saat Request.
+func (s *PasswordlessService) Verify(ctx context.Context, req model.PasswordlessVerifyDTO) (_ string, err error) {
+	defer func() { metrics.LoginAttempts.WithLabelValues(metrics.Outcome(err)).Inc() }()
+
+	secret := strings.TrimSpace(req.Secret)
+	if req.ChallengeID == "" || secret == "" {
+		return "", apperror.BadRequest(apperror.CodeValidationFailed, "challenge_id and secret are required")
+	}
+
+	challenge, err := s.challenges.Find(ctx, req.ChallengeID)
+	if err != nil {
+		return "", challengeLookupError(err)
+	}
+	// Tabel yang sama menyimpan ceremony passkey
+	if challenge.Method != model.LoginMethodLink && challenge.Method != model.LoginMethodCode {
+		return "", challengeLookupError(repository.ErrNotFound)
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		_ = s.challenges.Consume(ctx, challenge.ID)
+		return "", apperror.Unauthorized(apperror.CodeChallengeExpired, "Login link or code has expired, request a new one")
+	}
+
+	// Hanya perangkat yang meminta challenge yang bisa memakai jatah percobaannya
+	if req.DeviceSecret == "" || !hmac.Equal([]byte(hashToken(req.DeviceSecret)), []byte(challenge.DeviceHash)) {
+		return "", apperror.Forbidden(apperror.CodeDeviceMismatch,
+			"Login must be completed on the device that requested it")
+	}
+
+	// Percobaan dihitung sebelum diperiksa sehingga tebakan bersamaan tidak melewati batas
+	attempts, err := s.challenges.RecordAttempt(ctx, challenge.ID)
+	if err != nil {
+		return "", challengeLookupError(err)
+	}
+	if attempts > s.config.MaxAttempts {
+		return "", tooManyAttemptsError()
+	}
+	if !hmac.Equal([]byte(s.hashSecret(challenge.ChallengeID, secret)), []byte(challenge.SecretHash)) {
+		remaining := s.config.MaxAttempts - attempts
+		if remaining <= 0 {
+			return "", tooManyAttemptsError()
+		}
+		return "", apperror.Unauthorized(apperror.CodeInvalidCode, "Login link or code is incorrect").
+			With("attempts_remaining", remaining)
+	}
+
+	// Hanya verifikasi yang berhasil menghapus challenge yang mendapat token
+	if err := s.challenges.Consume(ctx, challenge.ID); err != nil {
+		return "", challengeLookupError(err)
+	}
+
+	user, err := s.users.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return "", userLookupError(err)
+	}
+	// Status bisa berubah setelah email dikirim
+	if user.Status != model.StatusActive {
+		return "", apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status)).
+			With("account_status", user.Status)
+	}
+
+	token, err := utils.GenerateToken(user, s.auth.JWTSecret, s.auth.TokenTTL)
+	if err != nil {
+		return "", apperror.Internal(err, "Gagal menghasilkan token")
+	}
+	return token, nil
+}
+
+// PurgeExpiredChallenges menghapus challenge yang sudah kedaluwarsa.
+func (s *PasswordlessService) PurgeExpiredChallenges(ctx context.Context) (int64, error) {
+	return s.challenges.PurgeExpired(ctx, time.Now())
+}
+
+// cancelDeviceChallenges menghapus challenge email pengguna yang diminta dari perangkat deviceHash.
+func (s *PasswordlessService) cancelDeviceChallenges(ctx context.Context, userID uint, deviceHash string) error {
+	for _, method := range []string{model.LoginMethodLink, model.LoginMethodCode} {
+		challenges, err := s.challenges.ListByUser(ctx, userID, method)
+		if err != nil {
+			return err
+		}
+		for _, challenge := range challenges {
+			if challenge.DeviceHash != deviceHash {
+				continue
+			}
+			if err := s.challenges.Consume(ctx, challenge.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// message menyusun email berisi magic link atau kode untuk challenge.
+func (s *PasswordlessService) message(user model.User, challenge model.LoginChallenge, secret string) mailer.Message {
+	minutes := int(s.config.TTL.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	if challenge.Method == model.LoginMethodCode {
+		return mailer.Message{
+			To:      user.Email,
+			Subject: "Your login code",
+			Text: fmt.Sprintf("Hi %s,\n\nYour login code is %s. It expires in %d minutes and can only be used once.\n\n"+
+				"If you did not try to sign in, you can ignore this email.\n", user.Fullname, secret, minutes),
+		}
+	}
+
+	link, _ := url.Parse(s.linkURL)
+	query := link.Query()
+	query.Set("challenge_id", challenge.ChallengeID)
+	query.Set("token", secret)
+	link.RawQuery = query.Encode()
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Text: fmt.Sprintf("Hi %s,\n\nOpen this link on the device where you requested it to sign in:\n\n%s\n\n"+
+			"The link expires in %d minutes and can only be used once. If you did not try to sign in, "+
+			"you can ignore this email.\n", user.Fullname, link.String(), minutes),
+	}
+}
+
+// hashSecret mengembalikan HMAC token atau kode, terikat ke challenge agar kode yang sama di
+// challenge lain menghasilkan hash berbeda.
+func (s *PasswordlessService) hashSecret(challengeID, secret string) string {
+	mac := hmac.New(sha256.New, s.secretKey)
+	mac.Write([]byte(challengeID + ":" + secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomCode menghasilkan kode 6 digit acak dari crypto/rand.
+func randomCode() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
+
+// challengeLookupError menerjemahkan error repository saat mencari atau memakai challenge.
+func challengeLookupError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperror.Unauthorized(apperror.CodeInvalidChallenge, "Login link or code is invalid or has already been used")
+	}
+	return apperror.Internal(err, "Failed to verify login challenge")
+}
+
+// tooManyAttemptsError adalah error 429 setelah jatah percobaan challenge habis.
+func tooManyAttemptsError() error {
+	return apperror.New(fiber.StatusTooManyRequests, apperror.CodeTooManyAttempts,
+		"Too many attempts, request a new login link or code")
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PurgeExpiredRevocations menghapus catatan token yang dibatalkan lebih lama dari masa berlaku token.
+// Token tersebut sudah pasti kedaluwarsa sehingga catatannya tidak diperlukan lagi.
+func (s *AuthService) PurgeExpiredRevocations(ctx context.Context) (int64, error) {
+	return s.tokens.PurgeRevokedBefore(ctx, time.Now().Add(-s.config.TokenTTL))
+}
+
+// RunRevocationCleanup menjalankan PurgeExpiredRevocations setiap interval sampai ctx dibatalkan.
+// Dipakai sebagai background job; kembali setelah putaran yang sedang berjalan selesai.
+func (s *AuthService) RunRevocationCleanup(ctx context.Context, interval time.Duration) {
+	runCleanup(ctx, interval, "revoked tokens", s.PurgeExpiredRevocations)
+}
+
+// RunCodeCleanup menjalankan PurgeExpiredCodes setiap interval sampai ctx dibatalkan.
+func (s *OAuthService) RunCodeCleanup(ctx context.Context, interval time.Duration) {
+	runCleanup(ctx, interval, "authorization codes", s.PurgeExpiredCodes)
+}
+
+// runCleanup memanggil purge setiap interval dan mencatat hasilnya dengan nama what.
+func runCleanup(ctx context.Context, interval time.Duration, what string, purge func(context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := purge(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to purge "+what, "error", err)
+			} else if purged > 0 {
+				slog.InfoContext(ctx, "purged expired "+what, "count", purged)
+			}
+		}
+	}
+}
+
+// RunChallengeCleanup menjalankan PurgeExpiredChallenges setiap interval sampai ctx dibatalkan.
+func (s *PasswordlessService) RunChallengeCleanup(ctx context.Context, interval time.Duration) {
+	runCleanup(ctx, interval, "login challenges", s.PurgeExpiredChallenges)
+}
+
+// RunAPITokenCleanup menjalankan PurgeExpiredTokens setiap interval sampai ctx dibatalkan.
+func (s *APITokenService) RunAPITokenCleanup(ctx context.Context, interval time.Duration) {
+	runCleanup(ctx, interval, "API tokens", s.PurgeExpiredTokens)
+}
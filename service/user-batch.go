@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxBatchOperations membatasi jumlah operasi dalam satu permintaan batch.
+const maxBatchOperations = 100
+
+// statusForOperation memetakan operasi batch ke status tujuan.
+var statusForOperation = map[string]string{
+	model.BatchOpSuspend:    model.StatusSuspended,
+	model.BatchOpReactivate: model.StatusActive,
+	model.BatchOpDisable:    model.StatusDisabled,
+}
+
+// Batch menjalankan beberapa operasi pengguna (suspend, reactivate, disable, delete, set_role) sekaligus.
+// Mode atomic menjalankan semua operasi di dalam satu transaksi; jika satu gagal, semuanya dibatalkan
+// dan error yang dikembalikan membawa hasil per operasi. Mode best_effort menjalankan setiap operasi
+// secara terpisah dan melaporkan hasilnya satu per satu.
+func (s *UserService) Batch(ctx context.Context, req model.UserBatchRequest) ([]model.UserBatchResult, error) {
+	if req.Mode != model.BatchModeAtomic && req.Mode != model.BatchModeBestEffort {
+		return nil, apperror.BadRequest(apperror.CodeValidationFailed, "Mode must be either atomic or best_effort")
+	}
+
+	if len(req.Operations) == 0 || len(req.Operations) > maxBatchOperations {
+		return nil, apperror.BadRequest(apperror.CodeValidationFailed,
+			fmt.Sprintf("Operations must contain between 1 and %d items", maxBatchOperations))
+	}
+
+	results := make([]model.UserBatchResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = model.UserBatchResult{Index: i, Op: op.Op, ID: op.ID}
+	}
+
+	if req.Mode == model.BatchModeBestEffort {
+		for i, op := range req.Operations {
+			setBatchResult(&results[i], applyBatchOperation(ctx, s.users, op))
+		}
+		return results, nil
+	}
+
+	// Mode atomic: hentikan pada kegagalan pertama dan rollback seluruh transaksi
+	failed := -1
+	err := s.users.Transaction(ctx, func(users repository.UserRepository) error {
+		for i, op := range req.Operations {
+			if err := applyBatchOperation(ctx, users, op); err != nil {
+				failed = i
+				setBatchResult(&results[i], err)
+				return err
+			}
+			setBatchResult(&results[i], nil)
+		}
+		return nil
+	})
+
+	if err != nil {
+		if failed == -1 {
+			// Kegagalan terjadi saat commit, bukan pada operasi tertentu
+			return nil, apperror.Internal(err, "Failed to commit batch")
+		}
+
+		for i := range results {
+			if i == failed {
+				continue
+			}
+			results[i].Status = fiber.StatusFailedDependency
+			results[i].Code = apperror.CodeBatchRolledBack
+			results[i].Message = "Rolled back because another operation failed"
+		}
+		return nil, apperror.New(fiber.StatusUnprocessableEntity, apperror.CodeBatchRolledBack, "Batch rolled back").
+			With("results", results)
+	}
+
+	return results, nil
+}
+
+// applyBatchOperation menjalankan satu operasi batch menggunakan repository atau transaksi yang diberikan.
+func applyBatchOperation(ctx context.Context, users repository.UserRepository, op model.UserBatchOperation) error {
+	if op.ID == 0 {
+		return apperror.BadRequest(apperror.CodeValidationFailed, "User ID is required")
+	}
+
+	switch op.Op {
+	case model.BatchOpSuspend, model.BatchOpReactivate, model.BatchOpDisable, model.BatchOpDelete:
+	case model.BatchOpSetRole:
+		if !model.IsValidRole(op.Role) {
+			return apperror.BadRequest(apperror.CodeValidationFailed, "Invalid role")
+		}
+	default:
+		return apperror.BadRequest(apperror.CodeValidationFailed, "Unknown operation")
+	}
+
+	user, err := users.FindByID(ctx, op.ID)
+	if err != nil {
+		return userLookupError(err)
+	}
+
+	switch op.Op {
+	case model.BatchOpSuspend, model.BatchOpReactivate, model.BatchOpDisable:
+		return changeStatus(ctx, users, &user, statusForOperation[op.Op], op.Reason)
+	case model.BatchOpSetRole:
+		user.Role = op.Role
+		if err := users.UpdateVersioned(ctx, &user, "role"); err != nil {
+			return userWriteError(err, "Failed to update user role")
+		}
+		return nil
+	default:
+		if err := users.DeleteVersioned(ctx, user.ID, user.Version); err != nil {
+			return userWriteError(err, "Failed to delete user")
+		}
+		return nil
+	}
+}
+
+// setBatchResult mengisi kode status, kode error, dan pesan hasil operasi batch.
+func setBatchResult(result *model.UserBatchResult, err error) {
+	if err == nil {
+		result.Status = fiber.StatusOK
+		result.Message = "OK"
+		return
+	}
+
+	appErr := apperror.From(err)
+	result.Status = appErr.Status
+	result.Code = appErr.Code
+	result.Message = appErr.Message
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+)
+
+// Precondition diperiksa terhadap data pengguna saat ini sebelum perubahan disimpan,
+// misalnya untuk mencocokkan header If-Match.
+type Precondition func(user model.User) error
+
+// UserService berisi aturan bisnis untuk manajemen pengguna oleh admin.
+type UserService struct {
+	users repository.UserRepository
+}
+
+// NewUserService membuat UserService dengan repository yang diberikan.
+func NewUserService(users repository.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+// List mengambil daftar pengguna, opsional difilter berdasarkan status.
+func (s *UserService) List(ctx context.Context, statuses []string) ([]model.User, error) {
+	for _, status := range statuses {
+		if !model.IsValidStatus(status) {
+			return nil, apperror.BadRequest(apperror.CodeValidationFailed, "Invalid status filter")
+		}
+	}
+
+	users, err := s.users.List(ctx, repository.UserFilter{Statuses: statuses})
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch users")
+	}
+	return users, nil
+}
+
+// Get mengambil satu pengguna berdasarkan ID.
+func (s *UserService) Get(ctx context.Context, id uint) (model.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return model.User{}, userLookupError(err)
+	}
+	return user, nil
+}
+
+// Create membuat pengguna baru oleh admin.
+func (s *UserService) Create(ctx context.Context, req model.UserRequestDTO) (model.User, error) {
+	// Validasi input
+	if req.Email == "" || req.Password == "" || len(req.Password) < 6 {
+		return model.User{}, apperror.BadRequest(apperror.CodeValidationFailed, "Email and password are required, and password must be at least 6 characters")
+	}
+
+	// Cek apakah email sudah ada
+	if _, err := s.users.FindByEmail(ctx, req.Email); err == nil {
+		return model.User{}, emailExistsError()
+	}
+
+	user := newUser(req)
+	// Create tetap bisa gagal dengan ErrDuplicate bila ada pendaftaran bersamaan
+	if err := s.users.Create(ctx, &user); err != nil {
+		return model.User{}, userWriteError(err, "Failed to create user")
+	}
+	return user, nil
+}
+
+// Update mengganti seluruh data profil pengguna.
+func (s *UserService) Update(ctx context.Context, id uint, req model.UserRequestDTO, check Precondition) (model.User, error) {
+	user, err := s.findForWrite(ctx, id, check)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	user.Email = strings.TrimSpace(req.Email)
+	user.Fullname = req.Fullname
+	user.Address = req.Address
+	user.Gender = req.Gender
+	user.PhoneNumber = req.PhoneNumber
+
+	// Hanya set PasswordHash jika password baru disediakan
+	if req.Password != "" {
+		user.PasswordHash = utils.GeneratePassword(req.Password)
+	}
+
+	return s.saveProfile(ctx, user)
+}
+
+// Patch memperbarui sebagian data profil; field nil tidak diubah.
+func (s *UserService) Patch(ctx context.Context, id uint, req model.UserPatchDTO, check Precondition) (model.User, error) {
+	if req.Password != nil && len(*req.Password) < 6 {
+		return model.User{}, apperror.BadRequest(apperror.CodeValidationFailed, "Password must be at least 6 characters")
+	}
+
+	user, err := s.findForWrite(ctx, id, check)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if req.Email != nil {
+		user.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.Fullname != nil {
+		user.Fullname = *req.Fullname
+	}
+	if req.Address != nil {
+		user.Address = *req.Address
+	}
+	if req.Gender != nil {
+		user.Gender = *req.Gender
+	}
+	if req.PhoneNumber != nil {
+		user.PhoneNumber = *req.PhoneNumber
+	}
+	if req.Password != nil {
+		user.PasswordHash = utils.GeneratePassword(*req.Password)
+	}
+
+	return s.saveProfile(ctx, user)
+}
+
+// Delete menghapus pengguna jika versinya belum berubah.
+func (s *UserService) Delete(ctx context.Context, id uint, check Precondition) (model.User, error) {
+	user, err := s.findForWrite(ctx, id, check)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if err := s.users.DeleteVersioned(ctx, user.ID, user.Version); err != nil {
+		return model.User{}, userWriteError(err, "Failed to delete user")
+	}
+	return user, nil
+}
+
+// ChangeStatus memindahkan status pengguna sesuai aturan transisi di model.
+func (s *UserService) ChangeStatus(ctx context.Context, id uint, to, reason string) (model.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return model.User{}, userLookupError(err)
+	}
+
+	if err := changeStatus(ctx, s.users, &user, to, reason); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// findForWrite mengambil pengguna lalu menjalankan precondition (jika ada).
+func (s *UserService) findForWrite(ctx context.Context, id uint, check Precondition) (model.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return model.User{}, userLookupError(err)
+	}
+
+	if check != nil {
+		if err := check(user); err != nil {
+			return model.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// saveProfile menyimpan kolom profil dengan pengecekan versi.
+func (s *UserService) saveProfile(ctx context.Context, user model.User) (model.User, error) {
+	err := s.users.UpdateVersioned(ctx, &user,
+		"email", "password_hash", "fullname", "address", "gender", "phone_number")
+	if err != nil {
+		return model.User{}, userWriteError(err, "Failed to update user")
+	}
+	return user, nil
+}
+
+// changeStatus memvalidasi transisi dan menyimpan hanya kolom status.
+func changeStatus(ctx context.Context, users repository.UserRepository, user *model.User, to, reason string) error {
+	if err := user.TransitionStatus(to, reason); err != nil {
+		return apperror.Conflict(apperror.CodeInvalidStatusTransition, err.Error())
+	}
+
+	err := users.UpdateVersioned(ctx, user, "status", "status_reason", "status_changed_at")
+	if err != nil {
+		return userWriteError(err, "Failed to update user status")
+	}
+	return nil
+}
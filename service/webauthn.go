@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/config"
+	"go-fiber-user-management/metrics"
+	"go-fiber-user-management/model"
+	"go-fiber-user-management/repository"
+	"go-fiber-user-management/utils"
+	"go-fiber-user-management/webauthn"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultPasskeyName dipakai jika pengguna tidak memberi nama saat mendaftarkan passkey.
+const DefaultPasskeyName = "Passkey"
+
+// Batas panjang nama passkey dan credential ID yang disimpan, sesuai kolom tabel.
+const (
+	maxPasskeyNameLength        = 100
+	maxStoredCredentialIDLength = 768
+)
+
+// WebAuthnService menangani passkey: registrasi, login tanpa password, konfirmasi login
+// password sebagai faktor kedua, dan pengelolaan passkey milik pengguna. Challenge setiap
+// ceremony disimpan di LoginChallengeRepository dan hanya bisa dipakai sekali.
+type WebAuthnService struct {
+	credentials repository.WebAuthnRepository
+	challenges  repository.LoginChallengeRepository
+	users       repository.UserRepository
+	auth        config.AuthConfig
+	config      config.WebAuthnConfig
+	rp          webauthn.RelyingParty
+	handleKey   []byte
+}
+
+// NewWebAuthnService membuat WebAuthnService. issuer adalah URL publik server, dipakai sebagai
+// default RP ID dan origin.
+func NewWebAuthnService(credentials repository.WebAuthnRepository, challenges repository.LoginChallengeRepository,
+	users repository.UserRepository, auth config.AuthConfig, cfg config.WebAuthnConfig, issuer string) *WebAuthnService {
+	rpID, origins := cfg.RelyingParty(issuer)
+	// User handle tidak boleh berisi data pribadi, jadi dibentuk dari HMAC ID pengguna
+	key := sha256.Sum256([]byte("webauthn:" + auth.JWTSecret))
+	return &WebAuthnService{
+		credentials: credentials,
+		challenges:  challenges,
+		users:       users,
+		auth:        auth,
+		config:      cfg,
+		rp:          webauthn.RelyingParty{ID: rpID, Name: cfg.RPName, Origins: origins},
+		handleKey:   key[:],
+	}
+}
+
+// BeginRegistration membuat opsi navigator.credentials.create untuk passkey baru milik user.
+// Passkey yang sudah terdaftar dikecualikan agar authenticator yang sama tidak didaftarkan dua kali.
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, user model.User) (webauthn.CreationOptions, error) {
+	existing, err := s.credentials.ListByUser(ctx, user.ID)
+	if err != nil {
+		return webauthn.CreationOptions{}, apperror.Internal(err, "Failed to fetch passkeys")
+	}
+
+	// Hanya registrasi terbaru yang berlaku
+	if err := s.challenges.DeleteByUser(ctx, user.ID, model.PasskeyRegistration); err != nil {
+		return webauthn.CreationOptions{}, apperror.Internal(err, "Failed to start passkey registration")
+	}
+	challenge, err := s.startCeremony(ctx, model.PasskeyRegistration, user.ID)
+	if err != nil {
+		return webauthn.CreationOptions{}, err
+	}
+
+	params := make([]webauthn.CredentialParameter, 0, len(webauthn.SupportedAlgorithms))
+	for _, alg := range webauthn.SupportedAlgorithms {
+		params = append(params, webauthn.CredentialParameter{Type: webauthn.PublicKeyType, Alg: alg})
+	}
+	return webauthn.CreationOptions{
+		RP: webauthn.RelyingPartyEntity{ID: s.rp.ID, Name: s.rp.Name},
+		User: webauthn.UserEntity{
+			ID:          s.userHandle(user.ID),
+			Name:        user.Email,
+			DisplayName: user.Fullname,
+		},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            s.config.Timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(existing),
+		AuthenticatorSelection: webauthn.AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: webauthn.UserVerificationPreferred,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration memverifikasi respons navigator.credentials.create dan menyimpan passkey.
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, user model.User, req model.PasskeyRegisterRequest) (model.WebAuthnCredential, error) {
+	name, err := passkeyName(req.Name)
+	if err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+
+	ceremony, challenge, err := s.takeCeremony(ctx, req.Credential.Response.ClientDataJSON, model.PasskeyRegistration,
+		fiber.StatusBadRequest)
+	if err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+	if ceremony.UserID != user.ID {
+		return model.WebAuthnCredential{}, invalidCeremonyError(fiber.StatusBadRequest)
+	}
+
+	verified, err := s.rp.VerifyRegistration(challenge, req.Credential, false)
+	if err != nil {
+		return model.WebAuthnCredential{}, passkeyVerificationError(err, fiber.StatusBadRequest)
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(verified.ID)
+	if len(credentialID) > maxStoredCredentialIDLength {
+		return model.WebAuthnCredential{}, apperror.BadRequest(apperror.CodePasskeyVerificationFailed,
+			"Credential ID is too long")
+	}
+
+	credential := model.WebAuthnCredential{
+		UserID:         user.ID,
+		CredentialID:   credentialID,
+		Name:           name,
+		PublicKey:      verified.PublicKey,
+		Algorithm:      verified.Algorithm,
+		SignCount:      int64(verified.SignCount),
+		Transports:     strings.Join(verified.Transports, ","),
+		AAGUID:         formatAAGUID(verified.AAGUID),
+		UserVerified:   verified.UserVerified,
+		BackupEligible: verified.BackupEligible,
+	}
+	if err := s.credentials.Create(ctx, &credential); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return model.WebAuthnCredential{}, apperror.Conflict(apperror.CodePasskeyExists, "Passkey is already registered")
+		}
+		return model.WebAuthnCredential{}, apperror.Internal(err, "Failed to save passkey")
+	}
+	return credential, nil
+}
+
+// BeginLogin membuat opsi navigator.credentials.get. Tanpa mfaToken, ini login tanpa password
+// dengan passkey apa pun milik RP dan verifikasi pengguna (PIN atau biometrik) wajib. Dengan
+// mfaToken dari login password, hanya passkey pemilik akun yang diterima.
+func (s *WebAuthnService) BeginLogin(ctx context.Context, req model.PasskeyLoginBeginRequest) (webauthn.RequestOptions, error) {
+	options := webauthn.RequestOptions{
+		Timeout:          s.config.Timeout.Milliseconds(),
+		RPID:             s.rp.ID,
+		AllowCredentials: []webauthn.CredentialDescriptor{},
+		UserVerification: webauthn.UserVerificationRequired,
+	}
+
+	var userID uint
+	if req.MFAToken != "" {
+		mfa, err := s.challenges.Find(ctx, hashToken(req.MFAToken))
+		if err != nil || mfa.Method != model.LoginMethodMFA {
+			return webauthn.RequestOptions{}, mfaTokenError(err)
+		}
+		// Token MFA hanya bisa ditukar sekali
+		if err := s.challenges.Consume(ctx, mfa.ID); err != nil {
+			return webauthn.RequestOptions{}, mfaTokenError(err)
+		}
+		if time.Now().After(mfa.ExpiresAt) {
+			return webauthn.RequestOptions{}, mfaTokenError(repository.ErrNotFound)
+		}
+
+		existing, err := s.credentials.ListByUser(ctx, mfa.UserID)
+		if err != nil {
+			return webauthn.RequestOptions{}, apperror.Internal(err, "Failed to fetch passkeys")
+		}
+		userID = mfa.UserID
+		options.AllowCredentials = descriptors(existing)
+		// Password sudah menjadi faktor pertama
+		options.UserVerification = webauthn.UserVerificationPreferred
+	}
+
+	challenge, err := s.startCeremony(ctx, model.LoginMethodPasskey, userID)
+	if err != nil {
+		return webauthn.RequestOptions{}, err
+	}
+	options.Challenge = challenge
+	return options, nil
+}
+
+// FinishLogin memverifikasi respons navigator.credentials.get dan mengembalikan JWT yang sama
+// seperti login password tanpa scope yang diminta, yaitu dengan scope bawaan role pengguna.
+func (s *WebAuthnService) FinishLogin(ctx context.Context, req model.PasskeyLoginRequest) (_ string, err error) {
+	defer func() { metrics.LoginAttempts.WithLabelValues(metrics.Outcome(err)).Inc() }()
+
+	// Passkey dicari sebelum ceremony dipakai agar pengguna yang memilih passkey yang salah
+	// (mis. yang sudah dihapus) masih bisa mencoba passkey lain dengan challenge yang sama
+	credential, err := s.credentials.FindByCredentialID(ctx, base64.RawURLEncoding.EncodeToString(req.Credential.RawID))
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", apperror.Unauthorized(apperror.CodePasskeyVerificationFailed, "Passkey is not registered")
+	}
+	if err != nil {
+		return "", apperror.Internal(err, "Failed to fetch passkey")
+	}
+
+	ceremony, challenge, err := s.takeCeremony(ctx, req.Credential.Response.ClientDataJSON, model.LoginMethodPasskey,
+		fiber.StatusUnauthorized)
+	if err != nil {
+		return "", err
+	}
+	// Ceremony faktor kedua terikat ke pengguna yang sudah memasukkan password
+	if ceremony.UserID != 0 && credential.UserID != ceremony.UserID {
+		return "", apperror.Unauthorized(apperror.CodePasskeyVerificationFailed, "Passkey does not belong to this account")
+	}
+
+	assertion, err := s.rp.VerifyAssertion(challenge, req.Credential, credential.PublicKey, ceremony.UserID == 0)
+	if err != nil {
+		return "", passkeyVerificationError(err, fiber.StatusUnauthorized)
+	}
+	if len(assertion.UserHandle) > 0 && !hmac.Equal(assertion.UserHandle, s.userHandle(credential.UserID)) {
+		return "", apperror.Unauthorized(apperror.CodePasskeyVerificationFailed, "Passkey does not belong to this account")
+	}
+	// Counter yang tidak naik menandakan kunci yang digandakan; passkey tersinkron selalu 0
+	signCount := int64(assertion.SignCount)
+	if (signCount != 0 || credential.SignCount != 0) && signCount <= credential.SignCount {
+		return "", apperror.Unauthorized(apperror.CodePasskeyVerificationFailed,
+			"Passkey signature counter did not increase, the authenticator may have been cloned")
+	}
+	if err := s.credentials.RecordUse(ctx, credential.ID, signCount, time.Now()); err != nil {
+		return "", apperror.Internal(err, "Failed to update passkey")
+	}
+
+	user, err := s.users.FindByID(ctx, credential.UserID)
+	if err != nil {
+		return "", userLookupError(err)
+	}
+	if user.Status != model.StatusActive {
+		return "", apperror.Forbidden(apperror.CodeAccountInactive, model.StatusMessage(user.Status)).
+			With("account_status", user.Status)
+	}
+
+	token, err := utils.GenerateToken(user, s.auth.JWTSecret, s.auth.TokenTTL)
+	if err != nil {
+		return "", apperror.Internal(err, "Gagal menghasilkan token")
+	}
+	return token, nil
+}
+
+// SecondFactor adalah SecondFactor untuk AuthService: pengguna yang punya passkey mendapat
+// token MFA yang ditukar di BeginLogin, pengguna tanpa passkey login dengan password saja.
+func (s *WebAuthnService) SecondFactor(ctx context.Context, user model.User) (string, error) {
+	existing, err := s.credentials.ListByUser(ctx, user.ID)
+	if err != nil {
+		return "", apperror.Internal(err, "Failed to fetch passkeys")
+	}
+	if len(existing) == 0 {
+		return "", nil
+	}
+
+	token := randomToken(32)
+	mfa := model.LoginChallenge{
+		ChallengeID: hashToken(token),
+		UserID:      user.ID,
+		Method:      model.LoginMethodMFA,
+		ExpiresAt:   time.Now().Add(s.config.Timeout),
+	}
+	if err := s.challenges.Create(ctx, &mfa); err != nil {
+		return "", apperror.Internal(err, "Failed to start passkey confirmation")
+	}
+	return token, nil
+}
+
+// List mengembalikan passkey milik pengguna, urut dari yang paling lama didaftarkan.
+func (s *WebAuthnService) List(ctx context.Context, userID uint) ([]model.WebAuthnCredential, error) {
+	credentials, err := s.credentials.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, apperror.Internal(err, "Failed to fetch passkeys")
+	}
+	return credentials, nil
+}
+
+// Rename mengganti nama passkey milik pengguna.
+func (s *WebAuthnService) Rename(ctx context.Context, userID, id uint, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return apperror.BadRequest(apperror.CodeValidationFailed, "Name is required")
+	}
+	name, err := passkeyName(name)
+	if err != nil {
+		return err
+	}
+	return passkeyWriteError(s.credentials.Rename(ctx, userID, id, name), "Failed to rename passkey")
+}
+
+// Delete menghapus passkey milik pengguna. Passkey lain dan password tetap bisa dipakai login.
+func (s *WebAuthnService) Delete(ctx context.Context, userID, id uint) error {
+	return passkeyWriteError(s.credentials.Delete(ctx, userID, id), "Failed to remove passkey")
+}
+
+// startCeremony menyimpan challenge baru untuk ceremony method milik userID (0 untuk login
+// tanpa password yang penggunanya belum diketahui).
+func (s *WebAuthnService) startCeremony(ctx context.Context, method string, userID uint) (webauthn.URLEncoded, error) {
+	challenge := webauthn.NewChallenge()
+	ceremony := model.LoginChallenge{
+		ChallengeID: hashToken(base64.RawURLEncoding.EncodeToString(challenge)),
+		UserID:      userID,
+		Method:      method,
+		ExpiresAt:   time.Now().Add(s.config.Timeout),
+	}
+	if err := s.challenges.Create(ctx, &ceremony); err != nil {
+		return nil, apperror.Internal(err, "Failed to start passkey ceremony")
+	}
+	return challenge, nil
+}
+
+// takeCeremony mencari ceremony dari challenge di clientDataJSON lalu langsung menghapusnya,
+// sehingga respons yang sama tidak bisa dipakai ulang meskipun verifikasinya gagal.
+func (s *WebAuthnService) takeCeremony(ctx context.Context, clientDataJSON []byte, method string, status int) (model.LoginChallenge, string, error) {
+	challenge, err := webauthn.ClientChallenge(clientDataJSON)
+	if err != nil {
+		return model.LoginChallenge{}, "", passkeyVerificationError(err, status)
+	}
+
+	ceremony, err := s.challenges.Find(ctx, hashToken(challenge))
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && ceremony.Method != method) {
+		return model.LoginChallenge{}, "", invalidCeremonyError(status)
+	}
+	if err != nil {
+		return model.LoginChallenge{}, "", apperror.Internal(err, "Failed to fetch passkey ceremony")
+	}
+	if err := s.challenges.Consume(ctx, ceremony.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return model.LoginChallenge{}, "", invalidCeremonyError(status)
+		}
+		return model.LoginChallenge{}, "", apperror.Internal(err, "Failed to fetch passkey ceremony")
+	}
+	if time.Now().After(ceremony.ExpiresAt) {
+		return model.LoginChallenge{}, "", apperror.New(status, apperror.CodeChallengeExpired,
+			"Passkey ceremony has expired, start again")
+	}
+	return ceremony, challenge, nil
+}
+
+// userHandle mengembalikan user handle WebAuthn untuk pengguna.
+func (s *WebAuthnService) userHandle(userID uint) []byte {
+	mac := hmac.New(sha256.New, s.handleKey)
+	mac.Write([]byte("user:" + strconv.FormatUint(uint64(userID), 10)))
+	return mac.Sum(nil)[:16]
+}
+
+// descriptors mengubah passkey tersimpan menjadi CredentialDescriptor untuk opsi ceremony.
+func descriptors(credentials []model.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	result := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		id, err := base64.RawURLEncoding.DecodeString(credential.CredentialID)
+		if err != nil {
+			continue
+		}
+		result = append(result, webauthn.CredentialDescriptor{
+			Type:       webauthn.PublicKeyType,
+			ID:         id,
+			Transports: splitTransports(credential.Transports),
+		})
+	}
+	return result
+}
+
+// splitTransports mengubah kolom transports yang dipisahkan koma menjadi slice.
+func splitTransports(transports string) []string {
+	if transports == "" {
+		return []string{}
+	}
+	return strings.Split(transports, ",")
+}
+
+// passkeyName merapikan nama passkey dan mengisi default jika kosong.
+func passkeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultPasskeyName, nil
+	}
+	if utf8.RuneCountInString(name) > maxPasskeyNameLength {
+		return "", apperror.BadRequest(apperror.CodeValidationFailed, "Name must be at most 100 characters long")
+	}
+	return name, nil
+}
+
+// formatAAGUID menulis AAGUID sebagai UUID; AAGUID nol (authenticator tanpa attestation) menjadi kosong.
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 || strings.Trim(hex.EncodeToString(aaguid), "0") == "" {
+		return ""
+	}
+	h := hex.EncodeToString(aaguid)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// invalidCeremonyError adalah error ketika challenge tidak dikenal atau sudah dipakai.
+func invalidCeremonyError(status int) error {
+	return apperror.New(status, apperror.CodeInvalidCeremony, "Passkey ceremony is invalid or has already been used")
+}
+
+// passkeyVerificationError adalah error ketika respons authenticator ditolak; penyebabnya
+// hanya masuk log.
+func passkeyVerificationError(err error, status int) error {
+	if !errors.Is(err, webauthn.ErrVerification) {
+		return apperror.Internal(err, "Failed to verify passkey")
+	}
+	return apperror.Wrap(err, status, apperror.CodePasskeyVerificationFailed, "Passkey verification failed")
+}
+
+// mfaTokenError menerjemahkan error saat menukar token MFA.
+func mfaTokenError(err error) error {
+	if err == nil || errors.Is(err, repository.ErrNotFound) {
+		return apperror.Unauthorized(apperror.CodeInvalidChallenge, "MFA token is invalid or has expired, sign in again")
+	}
+	return apperror.Internal(err, "Failed to verify MFA token")
+}
+
+// passkeyWriteError menerjemahkan error repository saat mengubah atau menghapus passkey.
+func passkeyWriteError(err error, message string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrNotFound):
+		return apperror.NotFound(apperror.CodePasskeyNotFound, "Passkey not found")
+	default:
+		return apperror.Internal(err, message)
+	}
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// gormPlugin membuat span client untuk setiap query GORM sebagai turunan span di context query.
+type gormPlugin struct {
+	system string
+}
+
+func (gormPlugin) Name() string { return "tracing" }
+
+func (p gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("tracing:before_create", p.startSpan("create")),
+		cb.Create().After("*").Register("tracing:after_create", endSpan),
+		cb.Query().Before("*").Register("tracing:before_query", p.startSpan("select")),
+		cb.Query().After("*").Register("tracing:after_query", endSpan),
+		cb.Update().Before("*").Register("tracing:before_update", p.startSpan("update")),
+		cb.Update().After("*").Register("tracing:after_update", endSpan),
+		cb.Delete().Before("*").Register("tracing:before_delete", p.startSpan("delete")),
+		cb.Delete().After("*").Register("tracing:after_delete", endSpan),
+		cb.Row().Before("*").Register("tracing:before_row", p.startSpan("row")),
+		cb.Row().After("*").Register("tracing:after_row", endSpan),
+		cb.Raw().Before("*").Register("tracing:before_raw", p.startSpan("raw")),
+		cb.Raw().After("*").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func (p gormPlugin) startSpan(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		name := "db." + operation
+		if tx.Statement.Table != "" {
+			name += " " + tx.Statement.Table
+		}
+		ctx, span := Tracer().Start(tx.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(p.system),
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(tx.Statement.Table),
+			),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	// SQL memakai placeholder; nilai parameter (password hash, token) tidak dicatat
+	span.SetAttributes(
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// InstrumentDB memasang tracing query pada db. system adalah nama driver (postgres, mysql, sqlite).
+func InstrumentDB(db *gorm.DB, system string) error {
+	return db.Use(gormPlugin{system: system})
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go-fiber-user-management/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier menghubungkan header fasthttp dengan propagator OpenTelemetry.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (hc headerCarrier) Get(key string) string { return string(hc.header.Peek(key)) }
+
+func (hc headerCarrier) Set(key, value string) { hc.header.Set(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	var keys []string
+	hc.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Middleware membuat span server untuk setiap request, melanjutkan trace dari header
+// traceparent/tracestate (W3C trace context) jika ada. Span diberi nama "METHOD /pola/rute"
+// dan disimpan di user context sehingga span handler, bcrypt, dan query menjadi turunannya.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		method := utils.CopyString(c.Method())
+		ctx, span := Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = apperror.From(err).Status
+		}
+
+		route := utils.CopyString(c.Route().Path)
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) {
+			span.SetName(method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLPath(utils.CopyString(c.Path())),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+		return err
+	}
+}
+
+// Handler membungkus handler controller dengan span bernama sesuai method-nya,
+// mis. "UserController.GetUsers". Error dengan status 5xx menandai span sebagai gagal.
+func Handler(handler fiber.Handler) fiber.Handler {
+	name := handlerName(handler)
+	return func(c *fiber.Ctx) error {
+		ctx, span := Tracer().Start(c.UserContext(), name)
+		defer span.End()
+
+		parent := c.UserContext()
+		c.SetUserContext(ctx)
+		defer c.SetUserContext(parent)
+
+		err := handler(c)
+		if err != nil {
+			span.RecordError(err)
+			if apperror.From(err).Status >= fiber.StatusInternalServerError {
+				span.SetStatus(codes.Error, err.Error())
+			}
+		}
+		return err
+	}
+}
+
+// handlerName mengubah nama fungsi seperti
+// "go-fiber-user-management/controller.(*UserController).GetUsers-fm" menjadi "UserController.GetUsers".
+func handlerName(handler fiber.Handler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	if dot := strings.Index(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName adalah nama tracer untuk semua span aplikasi.
+const InstrumentationName = "go-fiber-user-management"
+
+// Exporter span yang didukung.
+const (
+	ExporterNone   = "none"   // Tracing nonaktif, tetapi trace context tetap diteruskan
+	ExporterOTLP   = "otlp"   // OTLP/HTTP ke collector (mis. localhost:4318)
+	ExporterStdout = "stdout" // Span ditulis ke stderr, untuk development lokal
+	ExporterFile   = "file"   // Span ditulis sebagai JSON ke file, untuk development lokal
+)
+
+// Config berisi pengaturan tracing.
+type Config struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`         // none, otlp, stdout, atau file
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`         // host:port collector OTLP/HTTP; kosong memakai OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure    bool    `yaml:"insecure" toml:"insecure"`         // OTLP tanpa TLS
+	File        string  `yaml:"file" toml:"file"`                 // Path file untuk exporter file
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"` // Rasio trace root yang disampel, 0..1
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+}
+
+// Validate memeriksa konfigurasi tracing.
+func (cfg Config) Validate() error {
+	switch cfg.Exporter {
+	case ExporterNone, ExporterOTLP, ExporterStdout:
+	case ExporterFile:
+		if cfg.File == "" {
+			return errors.New("TRACING_FILE is required for the file exporter")
+		}
+	default:
+		return fmt.Errorf("unsupported TRACING_EXPORTER %q: use none, otlp, stdout or file", cfg.Exporter)
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", cfg.SampleRatio)
+	}
+	return nil
+}
+
+// Tracer mengembalikan tracer aplikasi dari TracerProvider global.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Setup memasang propagator W3C trace context dan TracerProvider global sesuai cfg.
+// Fungsi shutdown yang dikembalikan mengirim span yang tersisa dan harus dipanggil saat berhenti.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	noop := func(context.Context) error { return nil }
+	if cfg.Exporter == ExporterNone {
+		return noop, nil
+	}
+
+	var closer io.Closer
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		// stdout dipakai log JSON, jadi span ditulis ke stderr
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		var file *os.File
+		file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err == nil {
+			closer = file
+			exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		}
+	default:
+		err = fmt.Errorf("unsupported exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// Ikuti keputusan sampling dari upstream; trace baru disampel sesuai rasio
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go-fiber-user-management/database"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans memasang TracerProvider yang menyimpan span di memori selama test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+type sampleController struct{}
+
+func (sampleController) GetThing(c *fiber.Ctx) error {
+	_, span := Tracer().Start(c.UserContext(), "work")
+	span.End()
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func TestMiddlewareContinuesTraceContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/things/:id", Handler(sampleController{}.GetThing))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/things/7", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := app.Test(req, -1); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Errorf("span %q has trace id %s, want %s", span.Name(), span.SpanContext().TraceID(), traceID)
+		}
+	}
+
+	server, ok := spans["GET /things/:id"]
+	if !ok {
+		t.Fatalf("no server span named after route, got %v", spans)
+	}
+	if server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span kind = %v", server.SpanKind())
+	}
+	handler, ok := spans["sampleController.GetThing"]
+	if !ok || handler.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Fatalf("handler span missing or not a child of the server span: %v", spans)
+	}
+	if work := spans["work"]; work == nil || work.Parent().SpanID() != handler.SpanContext().SpanID() {
+		t.Errorf("work span is not a child of the handler span")
+	}
+}
+
+func TestInstrumentDB(t *testing.T) {
+	recorder := recordSpans(t)
+
+	db, err := database.Open(database.Config{
+		Driver:  database.DriverSQLite,
+		Name:    filepath.Join(t.TempDir(), "trace.db"),
+		SSLMode: "disable",
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := InstrumentDB(db, database.DriverSQLite); err != nil {
+		t.Fatalf("instrument: %v", err)
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	if err := db.WithContext(ctx).Exec("CREATE TABLE secrets (value TEXT)").Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := db.WithContext(ctx).Exec("INSERT INTO secrets (value) VALUES (?)", "hunter2").Error; err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	parent.End()
+
+	var queries int
+	for _, span := range recorder.Ended() {
+		if span.Name() == "parent" {
+			continue
+		}
+		queries++
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("query span %q is not a child of the request span", span.Name())
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Value.Emit() == "hunter2" || attr.Value.Emit() == "INSERT INTO secrets (value) VALUES (\"hunter2\")" {
+				t.Errorf("span %q leaks query parameters: %v", span.Name(), attr)
+			}
+		}
+	}
+	if queries != 2 {
+		t.Errorf("recorded %d query spans, want 2", queries)
+	}
+}
+
+func TestHandlerName(t *testing.T) {
+	if got := handlerName(sampleController{}.GetThing); got != "sampleController.GetThing" {
+		t.Errorf("handlerName = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// plusAddressingDomains adalah penyedia email yang mengabaikan bagian "+tag" pada local part.
+var plusAddressingDomains = map[string]bool{
+	"gmail.com":      true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"fastmail.com":   true,
+	"protonmail.com": true,
+	"proton.me":      true,
+}
+
+// canonicalizeProviders diatur sekali saat startup melalui SetProviderCanonicalization.
+var canonicalizeProviders atomic.Bool
+
+// SetProviderCanonicalization mengaktifkan aturan khusus penyedia pada NormalizeEmail
+// (konfigurasi email.canonicalize_providers / EMAIL_CANONICALIZE_PROVIDERS).
+func SetProviderCanonicalization(enabled bool) {
+	canonicalizeProviders.Store(enabled)
+}
+
+// NormalizeEmail mengembalikan bentuk email yang dipakai untuk pencarian dan keunikan:
+// spasi di awal/akhir dibuang dan huruf dijadikan kecil.
+//
+// Jika kanonikalisasi penyedia aktif, aturan khusus penyedia juga diterapkan
+// (mis. "J.Doe+news@googlemail.com" menjadi "jdoe@gmail.com"). Mengaktifkannya pada data
+// yang sudah ada memerlukan pengisian ulang kolom email_normalized.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if !canonicalizeProviders.Load() {
+		return email
+	}
+	return canonicalizeProviderEmail(email)
+}
+
+// canonicalizeProviderEmail menerapkan aturan alias milik penyedia email tertentu.
+func canonicalizeProviderEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if !plusAddressingDomains[domain] {
+		return email
+	}
+
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	// Gmail tidak membedakan titik pada local part
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email        string
+		canonicalize bool
+		want         string
+	}{
+		{"  Foo@X.com ", false, "foo@x.com"},
+		{"J.Doe+news@GoogleMail.com", false, "j.doe+news@googlemail.com"},
+		{"J.Doe+news@GoogleMail.com", true, "jdoe@gmail.com"},
+		{"first.last+tag@outlook.com", true, "first.last@outlook.com"},
+		{"first.last+tag@example.com", true, "first.last+tag@example.com"},
+		{"not-an-email", true, "not-an-email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			SetProviderCanonicalization(tt.canonicalize)
+			t.Cleanup(func() { SetProviderCanonicalization(false) })
+			if got := NormalizeEmail(tt.email); got != tt.want {
+				t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"context"
+
+	"go-fiber-user-management/tracing"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func GeneratePassword(password string) string {
 	// Create hash from password
@@ -8,7 +14,12 @@ func GeneratePassword(password string) string {
 	return string(hash)
 }
 
-func ComparePassword(hashedPassword string, password string) bool {
+// ComparePassword mencocokkan password dengan hash bcrypt. Perbandingan dicatat sebagai span
+// karena bcrypt sengaja lambat dan biasanya mendominasi latensi login.
+func ComparePassword(ctx context.Context, hashedPassword string, password string) bool {
+	_, span := tracing.Tracer().Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	// compare hash andd password
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -11,32 +10,52 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-// GenerateToken membuat token JWT baru untuk pengguna yang ditentukan.
-func GenerateToken(user model.User) (string, error) {
-	// Mengambil rahasia JWT dari variabel lingkungan.
-	jwtSecret := os.Getenv("JWT_SECRET")
+// GenerateToken membuat token JWT baru untuk pengguna yang ditentukan, berlaku selama ttl.
+// Token membawa scope bawaan role pengguna (model.DefaultScope) kecuali extra menimpa klaim
+// scope; extra juga menambahkan klaim lain, mis. client_id untuk token yang diterbitkan lewat OAuth2.
+func GenerateToken(user model.User, jwtSecret string, ttl time.Duration, extra ...jwt.MapClaims) (string, error) {
+	// Klaim mencakup ID dan email pengguna serta scope token.
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"scope":   model.DefaultScope(user.Role),
+	}
+	for _, m := range extra {
+		for key, value := range m {
+			claims[key] = value
+		}
+	}
+	return signToken(claims, jwtSecret, ttl)
+}
+
+// GenerateClientToken membuat token JWT untuk klien OAuth2 yang bertindak atas namanya sendiri
+// (grant client_credentials); token ini tidak membawa user_id.
+func GenerateClientToken(clientID, scope, jwtSecret string, ttl time.Duration) (string, error) {
+	return signToken(jwt.MapClaims{
+		"sub":       "client:" + clientID,
+		"client_id": clientID,
+		"scope":     scope,
+	}, jwtSecret, ttl)
+}
+
+// signToken menambahkan waktu terbit dan kedaluwarsa ke claims lalu menandatanganinya.
+func signToken(claims jwt.MapClaims, jwtSecret string, ttl time.Duration) (string, error) {
 	if jwtSecret == "" {
-		return "", fmt.Errorf("JWT_SECRET tidak diset")
+		return "", fmt.Errorf("JWT secret tidak diset")
 	}
 
-	// Membuat token JWT baru dengan klaim yang mencakup ID dan email pengguna.
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":   user.ID,
-		"email":     user.Email,
-		"issued_at": time.Now().Unix(),
-		"exp":       time.Now().Add(time.Hour * 24).Unix(), // Menetapkan kedaluwarsa token selama 24 jam
-	})
+	claims["issued_at"] = time.Now().Unix()
+	claims["exp"] = time.Now().Add(ttl).Unix() // Menetapkan kedaluwarsa token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Menandatangani token menggunakan rahasia dan mengembalikannya.
 	return token.SignedString([]byte(jwtSecret))
 }
 
 // VerifyToken memeriksa apakah token yang diberikan valid dan mengembalikan klaim.
-func VerifyToken(tokenString string) (jwt.MapClaims, error) {
-	// Mengambil rahasia JWT dari variabel lingkungan.
-	jwtSecret := os.Getenv("JWT_SECRET")
+func VerifyToken(tokenString, jwtSecret string) (jwt.MapClaims, error) {
 	if jwtSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET tidak diset")
+		return nil, fmt.Errorf("JWT secret tidak diset")
 	}
 
 	// Menghapus prefix "Bearer " tanpa if statement.
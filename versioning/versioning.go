@@ -0,0 +1,157 @@
+package versioning
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-user-management/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Header dipakai klien untuk memilih versi pada path tanpa versi, dan dikirim balik
+// di setiap response berisi versi yang melayani request.
+const Header = "API-Version"
+
+// Header siklus hidup versi (RFC 9745 dan RFC 8594).
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// versionName mengenali nama versi seperti v1 atau v2.
+var versionName = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// aliasKey menandai request yang masuk lewat path tanpa versi.
+type aliasKey struct{}
+
+// Policy menjelaskan siklus hidup satu versi API.
+type Policy struct {
+	Deprecated time.Time // Sejak kapan versi dinyatakan usang; zero berarti masih didukung penuh
+	Sunset     time.Time // Kapan versi akan dihapus; zero berarti belum dijadwalkan
+	Successor  string    // Versi pengganti yang dirujuk di header Link (mis. v2)
+}
+
+// API mengelola versi di bawah satu prefix (mis. /api). Path tanpa versi tetap dilayani
+// sebagai alias usang dan diteruskan ke versi yang dipilih lewat header API-Version
+// atau ke versi default.
+type API struct {
+	prefix   string
+	fallback string
+	alias    Policy
+	versions map[string]Policy
+}
+
+// New membuat API dengan prefix, versi default untuk path tanpa versi, dan kebijakan
+// usang untuk alias tanpa versi tersebut.
+func New(prefix, defaultVersion string, alias Policy) *API {
+	return &API{
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		fallback: defaultVersion,
+		alias:    alias,
+		versions: map[string]Policy{},
+	}
+}
+
+// Valid memeriksa apakah name adalah nama versi yang sah (v1, v2, ...).
+func Valid(name string) bool {
+	return versionName.MatchString(name)
+}
+
+// Normalize mengubah nilai header seperti "2" atau "V2" menjadi "v2".
+func Normalize(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value != "" && !strings.HasPrefix(value, "v") {
+		value = "v" + value
+	}
+	return value
+}
+
+// Version mendaftarkan versi baru dan mengembalikan grup rutenya, mis. /api/v2. Setiap
+// versi punya handler dan DTO sendiri; response membawa header API-Version serta
+// Deprecation/Sunset sesuai policy.
+func (a *API) Version(app fiber.Router, name string, policy Policy) fiber.Router {
+	if !Valid(name) {
+		panic("versioning: invalid version name " + name)
+	}
+	a.versions[name] = policy
+	return app.Group(a.prefix+"/"+name, a.headers(name, policy))
+}
+
+// Versions mengembalikan nama versi yang terdaftar secara berurutan.
+func (a *API) Versions() []string {
+	names := make([]string, 0, len(a.versions))
+	for name := range a.versions {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) < len(names[j]) || len(names[i]) == len(names[j]) && names[i] < names[j]
+	})
+	return names
+}
+
+// Negotiate harus dipasang sebelum middleware lain dengan app.Use(prefix, api.Negotiate()).
+// Request ke path tanpa versi ditulis ulang ke versi dari header API-Version (atau versi
+// default) lalu routing diulang, sehingga middleware lain hanya berjalan sekali.
+func (a *API) Negotiate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rest := strings.TrimPrefix(c.Path(), a.prefix)
+		if rest != "" && rest[0] != '/' {
+			return c.Next() // Prefix lain, mis. /apix
+		}
+
+		segment, _, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+		if _, ok := a.versions[segment]; ok {
+			return c.Next()
+		}
+
+		version := a.fallback
+		if requested := c.Get(Header); requested != "" {
+			version = Normalize(requested)
+			if _, ok := a.versions[version]; !ok {
+				return apperror.BadRequest(apperror.CodeUnsupportedVersion, "Unsupported API version").
+					With("supported_versions", a.Versions())
+			}
+		}
+
+		c.Locals(aliasKey{}, true)
+		c.Path(a.prefix + "/" + version + rest)
+		return c.RestartRouting()
+	}
+}
+
+// headers menambahkan header versi dan siklus hidup ke setiap response grup versi.
+func (a *API) headers(name string, policy Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(Header, name)
+
+		if alias, _ := c.Locals(aliasKey{}).(bool); alias {
+			// Path tanpa versi usang; penggantinya adalah path berversi yang sama
+			c.Vary(Header)
+			setLifecycle(c, a.alias, c.Path())
+		} else if successor := policy.Successor; successor != "" {
+			setLifecycle(c, policy, a.prefix+"/"+successor+strings.TrimPrefix(c.Path(), a.prefix+"/"+name))
+		} else {
+			setLifecycle(c, policy, "")
+		}
+		return c.Next()
+	}
+}
+
+// setLifecycle menulis header Deprecation, Sunset, dan Link ke versi pengganti.
+func setLifecycle(c *fiber.Ctx, policy Policy, successor string) {
+	if policy.Deprecated.IsZero() {
+		return
+	}
+	c.Set(HeaderDeprecation, "@"+strconv.FormatInt(policy.Deprecated.Unix(), 10))
+	if !policy.Sunset.IsZero() {
+		c.Set(HeaderSunset, policy.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if successor != "" {
+		c.Append(fiber.HeaderLink, "<"+successor+`>; rel="successor-version"`)
+	}
+}
@@ -0,0 +1,137 @@
+package versioning_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-fiber-user-management/apperror"
+	"go-fiber-user-management/versioning"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	deprecated = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sunset     = time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newApp membuat aplikasi dengan v1 (usang, digantikan v2) dan v2 yang memakai bentuk DTO berbeda.
+// middlewareRuns menghitung berapa kali middleware global berjalan per request.
+func newApp(middlewareRuns *int) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	api := versioning.New("/api", "v1", versioning.Policy{Deprecated: deprecated, Sunset: sunset})
+	app.Use("/api", api.Negotiate())
+	app.Use(func(c *fiber.Ctx) error {
+		*middlewareRuns++
+		return c.Next()
+	})
+
+	v1 := api.Version(app, "v1", versioning.Policy{Deprecated: deprecated, Successor: "v2"})
+	v1.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"fullname": "Jane Doe"})
+	})
+
+	v2 := api.Version(app, "v2", versioning.Policy{})
+	v2.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"name": fiber.Map{"given": "Jane", "family": "Doe"}})
+	})
+	return app
+}
+
+func request(t *testing.T, app *fiber.App, path, version string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if version != "" {
+		req.Header.Set(versioning.Header, version)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("decode %q: %v", raw, err)
+	}
+	return resp, body
+}
+
+func TestExplicitVersion(t *testing.T) {
+	var runs int
+	app := newApp(&runs)
+
+	resp, body := request(t, app, "/api/v2/users/1", "")
+	if resp.Header.Get(versioning.Header) != "v2" || body["name"] == nil {
+		t.Fatalf("v2 = %v %v", resp.Header, body)
+	}
+	if resp.Header.Get(versioning.HeaderDeprecation) != "" {
+		t.Error("current version must not be deprecated")
+	}
+
+	// Versi usang menunjuk ke path yang sama di versi penggantinya
+	resp, body = request(t, app, "/api/v1/users/1", "")
+	if body["fullname"] != "Jane Doe" {
+		t.Errorf("v1 body = %v", body)
+	}
+	if got := resp.Header.Get(versioning.HeaderDeprecation); got != "@1767225600" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := resp.Header.Get(fiber.HeaderLink); got != `</api/v2/users/1>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+	if resp.Header.Get(versioning.HeaderSunset) != "" {
+		t.Error("v1 has no sunset date")
+	}
+}
+
+func TestUnversionedAlias(t *testing.T) {
+	var runs int
+	app := newApp(&runs)
+
+	resp, body := request(t, app, "/api/users/1", "")
+	if resp.StatusCode != http.StatusOK || body["fullname"] != "Jane Doe" {
+		t.Fatalf("alias = %d %v, want default version v1", resp.StatusCode, body)
+	}
+	if runs != 1 {
+		t.Errorf("global middleware ran %d times, want 1", runs)
+	}
+	if resp.Header.Get(versioning.HeaderSunset) != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", resp.Header.Get(versioning.HeaderSunset))
+	}
+	if got := resp.Header.Get(fiber.HeaderLink); got != `</api/v1/users/1>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+	if resp.Header.Get(fiber.HeaderVary) != versioning.Header {
+		t.Errorf("Vary = %q", resp.Header.Get(fiber.HeaderVary))
+	}
+}
+
+func TestHeaderNegotiation(t *testing.T) {
+	var runs int
+	app := newApp(&runs)
+
+	for _, version := range []string{"2", "v2", "V2"} {
+		resp, body := request(t, app, "/api/users/1", version)
+		if resp.Header.Get(versioning.Header) != "v2" || body["name"] == nil {
+			t.Errorf("API-Version %s = %v", version, body)
+		}
+	}
+
+	resp, body := request(t, app, "/api/users/1", "9")
+	if resp.StatusCode != http.StatusBadRequest || body["code"] != apperror.CodeUnsupportedVersion {
+		t.Errorf("unsupported version = %d %v", resp.StatusCode, body)
+	}
+
+	// Path berversi selalu menang atas header
+	_, body = request(t, app, "/api/v1/users/1", "2")
+	if body["fullname"] != "Jane Doe" {
+		t.Errorf("explicit path with header = %v", body)
+	}
+}
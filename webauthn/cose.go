@@ -0,0 +1,62 @@
+package webauthn
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Algoritma COSE (RFC 9053) yang diterima untuk kredensial.
+const (
+	AlgES256 int64 = -7   // ECDSA P-256 dengan SHA-256
+	AlgEdDSA int64 = -8   // Ed25519
+	AlgRS256 int64 = -257 // RSASSA-PKCS1-v1_5 dengan SHA-256
+)
+
+// SupportedAlgorithms adalah algoritma yang ditawarkan di pubKeyCredParams, urut preferensi.
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// crvP256 adalah kurva P-256 di parameter crv kunci EC2 (RFC 9053 bagian 7.1).
+const crvP256 = 1
+
+// minRSABits adalah panjang modulus minimum untuk kunci RS256.
+const minRSABits = 2048
+
+// keyAlgorithm men-decode kunci COSE dari authenticator data atau database dan mengembalikan
+// algoritmanya. Kunci dengan algoritma di luar SupportedAlgorithms ditolak meskipun library
+// bisa memverifikasinya.
+func keyAlgorithm(cose []byte) (int64, error) {
+	key, err := webauthncose.ParsePublicKey(cose)
+	if err != nil {
+		return 0, fmt.Errorf("decode public key: %w", err)
+	}
+
+	var data webauthncose.PublicKeyData
+	switch key := key.(type) {
+	case webauthncose.EC2PublicKeyData:
+		// Library mengabaikan field yang gagal di-decode, jadi ukuran koordinat diperiksa di sini
+		if key.Curve != crvP256 || len(key.XCoord) != 32 || len(key.YCoord) != 32 {
+			return 0, errors.New("invalid P-256 public key")
+		}
+		data = key.PublicKeyData
+	case webauthncose.OKPPublicKeyData:
+		if len(key.XCoord) != ed25519.PublicKeySize {
+			return 0, errors.New("invalid Ed25519 public key")
+		}
+		data = key.PublicKeyData
+	case webauthncose.RSAPublicKeyData:
+		if len(key.Modulus)*8 < minRSABits {
+			return 0, fmt.Errorf("RSA public key is shorter than %d bits", minRSABits)
+		}
+		data = key.PublicKeyData
+	default:
+		return 0, fmt.Errorf("unsupported public key type %T", key)
+	}
+	if !slices.Contains(SupportedAlgorithms, data.Algorithm) {
+		return 0, fmt.Errorf("unsupported public key algorithm %d", data.Algorithm)
+	}
+	return data.Algorithm, nil
+}
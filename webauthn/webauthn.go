@@ -0,0 +1,315 @@
+// Package webauthn memverifikasi ceremony WebAuthn (passkey) di sisi relying party: opsi
+// registrasi dan autentikasi untuk navigator.credentials, serta verifikasi respons attestation
+// dan assertion dari browser. Parsing CBOR/COSE dan verifikasi kriptografis memakai
+// github.com/go-webauthn/webauthn/protocol; package ini menjaga bentuk JSON API dan menambah
+// pemeriksaan yang tidak dilakukan library (crossOrigin, flag backup, algoritma yang diterima).
+// Attestation tidak dipercaya (conveyance "none"); yang dijamin adalah kepemilikan kunci privat.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+)
+
+// Nilai userVerification di opsi ceremony.
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+// PublicKeyType adalah satu-satunya tipe kredensial WebAuthn.
+const PublicKeyType = "public-key"
+
+// ErrVerification membungkus semua kegagalan verifikasi respons dari browser.
+var ErrVerification = errors.New("webauthn verification failed")
+
+func verificationError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrVerification, fmt.Sprintf(format, args...))
+}
+
+// protocolError membungkus error dari library sebagai ErrVerification beserta detailnya.
+func protocolError(step string, err error) error {
+	var perr *protocol.Error
+	if errors.As(err, &perr) && perr.DevInfo != "" {
+		return verificationError("%s: %s: %s", step, perr.Details, strings.TrimSpace(perr.DevInfo))
+	}
+	return verificationError("%s: %v", step, err)
+}
+
+// URLEncoded adalah byte yang diserialisasi sebagai base64url tanpa padding di JSON, sesuai
+// PublicKeyCredential.toJSON() dan parseCreationOptionsFromJSON() di browser.
+type URLEncoded []byte
+
+// MarshalJSON meng-encode byte sebagai string base64url.
+func (b URLEncoded) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON menerima base64url dengan atau tanpa padding.
+func (b *URLEncoded) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return fmt.Errorf("invalid base64url value: %w", err)
+	}
+	*b = raw
+	return nil
+}
+
+// NewChallenge menghasilkan challenge acak 32 byte untuk satu ceremony.
+func NewChallenge() URLEncoded {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		panic(fmt.Sprintf("crypto/rand: %v", err))
+	}
+	return challenge
+}
+
+// RelyingPartyEntity adalah identitas server di opsi registrasi.
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity adalah akun yang didaftarkan. ID adalah user handle yang disimpan authenticator
+// dan tidak boleh berisi data pribadi.
+type UserEntity struct {
+	ID          URLEncoded `json:"id"`
+	Name        string     `json:"name"`
+	DisplayName string     `json:"displayName"`
+}
+
+// CredentialParameter adalah algoritma kunci yang diterima server.
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor merujuk kredensial yang sudah terdaftar.
+type CredentialDescriptor struct {
+	Type       string     `json:"type"`
+	ID         URLEncoded `json:"id"`
+	Transports []string   `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection membatasi authenticator yang boleh dipakai saat registrasi.
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions adalah PublicKeyCredentialCreationOptions untuk navigator.credentials.create.
+type CreationOptions struct {
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              URLEncoded             `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"` // Milidetik
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions adalah PublicKeyCredentialRequestOptions untuk navigator.credentials.get.
+// AllowCredentials kosong berarti authenticator menawarkan passkey yang tersimpan untuk RP ini.
+type RequestOptions struct {
+	Challenge        URLEncoded             `json:"challenge"`
+	Timeout          int64                  `json:"timeout"` // Milidetik
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// AttestationResponse adalah hasil navigator.credentials.create dalam bentuk toJSON().
+type AttestationResponse struct {
+	ID       string     `json:"id"`
+	RawID    URLEncoded `json:"rawId"`
+	Type     string     `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncoded `json:"clientDataJSON"`
+		AttestationObject URLEncoded `json:"attestationObject"`
+		Transports        []string   `json:"transports,omitempty"`
+	} `json:"response"`
+}
+
+// AssertionResponse adalah hasil navigator.credentials.get dalam bentuk toJSON().
+type AssertionResponse struct {
+	ID       string     `json:"id"`
+	RawID    URLEncoded `json:"rawId"`
+	Type     string     `json:"type"`
+	Response struct {
+		ClientDataJSON    URLEncoded `json:"clientDataJSON"`
+		AuthenticatorData URLEncoded `json:"authenticatorData"`
+		Signature         URLEncoded `json:"signature"`
+		UserHandle        URLEncoded `json:"userHandle,omitempty"`
+	} `json:"response"`
+}
+
+// Credential adalah kredensial baru hasil registrasi yang perlu disimpan.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // Kunci publik COSE dari authenticator
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool // Passkey yang bisa disinkronkan antar perangkat
+}
+
+// Assertion adalah hasil autentikasi yang sudah diverifikasi.
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+	UserHandle   []byte
+}
+
+// RelyingParty memverifikasi respons untuk satu RP ID dan daftar origin yang diizinkan.
+type RelyingParty struct {
+	ID      string   // Domain, mis. example.com
+	Name    string   // Nama yang ditampilkan authenticator
+	Origins []string // Origin halaman yang boleh menjalankan ceremony, mis. https://app.example.com
+}
+
+// ClientChallenge mengambil challenge (base64url) dari clientDataJSON sehingga server bisa
+// mencari ceremony yang sesuai sebelum verifikasi lengkap.
+func ClientChallenge(clientDataJSON []byte) (string, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil || data.Challenge == "" {
+		return "", verificationError("malformed clientDataJSON")
+	}
+	return data.Challenge, nil
+}
+
+// VerifyRegistration memverifikasi respons navigator.credentials.create untuk challenge
+// (base64url) yang diterbitkan server.
+func (rp RelyingParty) VerifyRegistration(challenge string, resp AttestationResponse, requireUV bool) (Credential, error) {
+	if err := verifyCrossOrigin(resp.Response.ClientDataJSON); err != nil {
+		return Credential{}, err
+	}
+	parsed, err := protocol.CredentialCreationResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: resp.ID, Type: resp.Type},
+			RawID:      protocol.URLEncodedBase64(resp.RawID),
+		},
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{
+				ClientDataJSON: protocol.URLEncodedBase64(resp.Response.ClientDataJSON),
+			},
+			AttestationObject: protocol.URLEncodedBase64(resp.Response.AttestationObject),
+			Transports:        resp.Response.Transports,
+		},
+	}.Parse()
+	if err != nil {
+		return Credential{}, protocolError("parse attestation", err)
+	}
+	if err := parsed.Verify(challenge, requireUV, rp.ID, rp.Origins); err != nil {
+		return Credential{}, protocolError("verify attestation", err)
+	}
+
+	authData := parsed.Response.AttestationObject.AuthData
+	if err := verifyBackupFlags(authData.Flags); err != nil {
+		return Credential{}, err
+	}
+	if !bytes.Equal(authData.AttData.CredentialID, resp.RawID) {
+		return Credential{}, verificationError("credential ID does not match rawId")
+	}
+	alg, err := keyAlgorithm(authData.AttData.CredentialPublicKey)
+	if err != nil {
+		return Credential{}, verificationError("%v", err)
+	}
+
+	return Credential{
+		ID:             authData.AttData.CredentialID,
+		PublicKey:      authData.AttData.CredentialPublicKey,
+		Algorithm:      alg,
+		SignCount:      authData.Counter,
+		AAGUID:         authData.AttData.AAGUID,
+		Transports:     resp.Response.Transports,
+		UserVerified:   authData.Flags.HasUserVerified(),
+		BackupEligible: authData.Flags.HasBackupEligible(),
+	}, nil
+}
+
+// VerifyAssertion memverifikasi respons navigator.credentials.get dengan kunci publik COSE
+// yang tersimpan untuk kredensial resp.RawID. Pemeriksaan sign counter dilakukan pemanggil.
+func (rp RelyingParty) VerifyAssertion(challenge string, resp AssertionResponse, publicKeyCOSE []byte, requireUV bool) (Assertion, error) {
+	// Kunci tersimpan yang rusak adalah kesalahan server, bukan respons yang ditolak
+	if _, err := keyAlgorithm(publicKeyCOSE); err != nil {
+		return Assertion{}, fmt.Errorf("stored public key: %w", err)
+	}
+	if err := verifyCrossOrigin(resp.Response.ClientDataJSON); err != nil {
+		return Assertion{}, err
+	}
+	parsed, err := protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: resp.ID, Type: resp.Type},
+			RawID:      protocol.URLEncodedBase64(resp.RawID),
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{
+				ClientDataJSON: protocol.URLEncodedBase64(resp.Response.ClientDataJSON),
+			},
+			// Library menambahkan hash clientDataJSON langsung ke slice ini
+			AuthenticatorData: protocol.URLEncodedBase64(slices.Clip(resp.Response.AuthenticatorData)),
+			Signature:         protocol.URLEncodedBase64(resp.Response.Signature),
+			UserHandle:        protocol.URLEncodedBase64(resp.Response.UserHandle),
+		},
+	}.Parse()
+	if err != nil {
+		return Assertion{}, protocolError("parse assertion", err)
+	}
+	if err := parsed.Verify(challenge, rp.ID, rp.Origins, "", requireUV, publicKeyCOSE); err != nil {
+		return Assertion{}, protocolError("verify assertion", err)
+	}
+
+	authData := parsed.Response.AuthenticatorData
+	if err := verifyBackupFlags(authData.Flags); err != nil {
+		return Assertion{}, err
+	}
+	return Assertion{
+		SignCount:    authData.Counter,
+		UserVerified: authData.Flags.HasUserVerified(),
+		UserHandle:   resp.Response.UserHandle,
+	}, nil
+}
+
+// clientData adalah bagian CollectedClientData yang diperiksa di luar library.
+type clientData struct {
+	Challenge   string `json:"challenge"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyCrossOrigin menolak ceremony yang dijalankan di iframe lintas origin, yang tidak
+// diperiksa library.
+func verifyCrossOrigin(raw []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return verificationError("malformed clientDataJSON")
+	}
+	if data.CrossOrigin {
+		return verificationError("cross-origin ceremonies are not allowed")
+	}
+	return nil
+}
+
+// verifyBackupFlags menolak authenticator data yang menandai backup tanpa backup eligible
+// (WebAuthn bagian 6.1.3).
+func verifyBackupFlags(flags protocol.AuthenticatorFlags) error {
+	if flags.HasBackupState() && !flags.HasBackupEligible() {
+		return verificationError("invalid backup flags")
+	}
+	return nil
+}
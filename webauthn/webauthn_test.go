@@ -0,0 +1,152 @@
+package webauthn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Respons browser asli dari webauthn.io, sama dengan fixture di test suite
+// github.com/go-webauthn/webauthn/protocol.
+var (
+	// Registrasi dengan attestation "none"
+	fixtureRegistration = `{
+		"id": "6xrtBhJQW6QU4tOaB4rrHaS2Ks0yDDL_q8jDC16DEjZ-VLVf4kCRkvl2xp2D71sTPYns-exsHQHTy3G-zJRK8g",
+		"rawId": "6xrtBhJQW6QU4tOaB4rrHaS2Ks0yDDL_q8jDC16DEjZ-VLVf4kCRkvl2xp2D71sTPYns-exsHQHTy3G-zJRK8g",
+		"type": "public-key",
+		"response": {
+			"attestationObject": "o2NmbXRkbm9uZWdhdHRTdG10oGhhdXRoRGF0YVjEdKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBBAAAAAAAAAAAAAAAAAAAAAAAAAAAAQOsa7QYSUFukFOLTmgeK6x2ktirNMgwy_6vIwwtegxI2flS1X-JAkZL5dsadg-9bEz2J7PnsbB0B08txvsyUSvKlAQIDJiABIVggLKF5xS0_BntttUIrm2Z2tgZ4uQDwllbdIfrrBMABCNciWCDHwin8Zdkr56iSIh0MrB5qZiEzYLQpEOREhMUkY6q4Vw",
+			"clientDataJSON": "eyJjaGFsbGVuZ2UiOiJXOEd6RlU4cEdqaG9SYldyTERsYW1BZnFfeTRTMUNaRzFWdW9lUkxBUnJFIiwib3JpZ2luIjoiaHR0cHM6Ly93ZWJhdXRobi5pbyIsInR5cGUiOiJ3ZWJhdXRobi5jcmVhdGUifQ",
+			"transports": ["usb", "nfc"]
+		}
+	}`
+	fixtureRegistrationChallenge = "W8GzFU8pGjhoRbWrLDlamAfq_y4S1CZG1VuoeRLARrE"
+
+	// Assertion Touch ID di macOS
+	fixtureAssertion = `{
+		"id": "AI7D5q2P0LS-Fal9ZT7CHM2N5BLbUunF92T8b6iYC199bO2kagSuU05-5dZGqb1SP0A0lyTWng",
+		"rawId": "AI7D5q2P0LS-Fal9ZT7CHM2N5BLbUunF92T8b6iYC199bO2kagSuU05-5dZGqb1SP0A0lyTWng",
+		"type": "public-key",
+		"response": {
+			"authenticatorData": "dKbqkhPJnC90siSSsyDPQCYqlMGpUKA5fyklC2CEHvBFXJJiGa3OAAI1vMYKZIsLJfHwVQMANwCOw-atj9C0vhWpfWU-whzNjeQS21Lpxfdk_G-omAtffWztpGoErlNOfuXWRqm9Uj9ANJck1p6lAQIDJiABIVggKAhfsdHcBIc0KPgAcRyAIK_-Vi-nCXHkRHPNaCMBZ-4iWCBxB8fGYQSBONi9uvq0gv95dGWlhJrBwCsj_a4LJQKVHQ",
+			"clientDataJSON": "eyJjaGFsbGVuZ2UiOiJFNFBUY0lIX0hmWDFwQzZTaWdrMVNDOU5BbGdlenROMDQzOXZpOHpfYzlrIiwibmV3X2tleXNfbWF5X2JlX2FkZGVkX2hlcmUiOiJkbyBub3QgY29tcGFyZSBjbGllbnREYXRhSlNPTiBhZ2FpbnN0IGEgdGVtcGxhdGUuIFNlZSBodHRwczovL2dvby5nbC95YWJQZXgiLCJvcmlnaW4iOiJodHRwczovL3dlYmF1dGhuLmlvIiwidHlwZSI6IndlYmF1dGhuLmdldCJ9",
+			"signature": "MEUCIBtIVOQxzFYdyWQyxaLR0tik1TnuPhGVhXVSNgFwLmN5AiEAnxXdCq0UeAVGWxOaFcjBZ_mEZoXqNboY5IkQDdlWZYc",
+			"userHandle": "0ToAAAAAAAAAAA"
+		}
+	}`
+	fixtureAssertionChallenge = "E4PTcIH_HfX1pC6Sigk1SC9NAlgeztN0439vi8z_c9k"
+	fixtureAssertionKey       = "pQMmIAEhWCAoCF-x0dwEhzQo-ABxHIAgr_5WL6cJceREc81oIwFn7iJYIHEHx8ZhBIE42L26-rSC_3l0ZaWEmsHAKyP9rgslApUdAQI"
+)
+
+var fixtureRP = RelyingParty{ID: "webauthn.io", Name: "webauthn.io", Origins: []string{"https://webauthn.io"}}
+
+func TestVerifyRegistrationBrowserFixture(t *testing.T) {
+	var resp AttestationResponse
+	if err := json.Unmarshal([]byte(fixtureRegistration), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	credential, err := fixtureRP.VerifyRegistration(fixtureRegistrationChallenge, resp, false)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !bytes.Equal(credential.ID, resp.RawID) || credential.Algorithm != AlgES256 || credential.UserVerified {
+		t.Errorf("credential = %+v", credential)
+	}
+	if _, err := keyAlgorithm(credential.PublicKey); err != nil {
+		t.Errorf("stored public key: %v", err)
+	}
+
+	// Authenticator ini tidak melakukan verifikasi pengguna
+	if _, err := fixtureRP.VerifyRegistration(fixtureRegistrationChallenge, resp, true); !errors.Is(err, ErrVerification) {
+		t.Errorf("require UV err = %v, want ErrVerification", err)
+	}
+	if _, err := fixtureRP.VerifyRegistration("other", resp, false); !errors.Is(err, ErrVerification) {
+		t.Errorf("wrong challenge err = %v, want ErrVerification", err)
+	}
+	otherRP := RelyingParty{ID: "example.com", Origins: fixtureRP.Origins}
+	if _, err := otherRP.VerifyRegistration(fixtureRegistrationChallenge, resp, false); !errors.Is(err, ErrVerification) {
+		t.Errorf("wrong RP ID err = %v, want ErrVerification", err)
+	}
+}
+
+func TestVerifyAssertionBrowserFixture(t *testing.T) {
+	var resp AssertionResponse
+	if err := json.Unmarshal([]byte(fixtureAssertion), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	key, err := base64.RawURLEncoding.DecodeString(fixtureAssertionKey)
+	if err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+
+	assertion, err := fixtureRP.VerifyAssertion(fixtureAssertionChallenge, resp, key, true)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !assertion.UserVerified || assertion.SignCount == 0 || !bytes.Equal(assertion.UserHandle, resp.Response.UserHandle) {
+		t.Errorf("assertion = %+v", assertion)
+	}
+
+	tampered := resp
+	tampered.Response.Signature = append(URLEncoded(nil), resp.Response.Signature...)
+	tampered.Response.Signature[len(tampered.Response.Signature)-1] ^= 0x01
+	if _, err := fixtureRP.VerifyAssertion(fixtureAssertionChallenge, tampered, key, true); !errors.Is(err, ErrVerification) {
+		t.Errorf("tampered signature err = %v, want ErrVerification", err)
+	}
+	wrongOrigin := RelyingParty{ID: fixtureRP.ID, Origins: []string{"https://evil.example"}}
+	if _, err := wrongOrigin.VerifyAssertion(fixtureAssertionChallenge, resp, key, true); !errors.Is(err, ErrVerification) {
+		t.Errorf("wrong origin err = %v, want ErrVerification", err)
+	}
+	// Kunci tersimpan yang rusak bukan kegagalan verifikasi dari sisi browser
+	if _, err := fixtureRP.VerifyAssertion(fixtureAssertionChallenge, resp, []byte{0xa0}, true); err == nil || errors.Is(err, ErrVerification) {
+		t.Errorf("corrupt stored key err = %v, want a non-verification error", err)
+	}
+}
+
+func TestKeyAlgorithmRejectsInvalidKeys(t *testing.T) {
+	invalid := map[string]string{
+		"empty":            "",
+		"not a map":        "AQ",
+		"unknown key type": "ogEEAyY",                                                        // {1: 4, 3: -7}
+		"short P-256 x":    "pQECAyYgASFCAAEiWCAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", // x 2 byte
+	}
+	for name, raw := range invalid {
+		cose, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if _, err := keyAlgorithm(cose); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestURLEncoded(t *testing.T) {
+	var decoded struct {
+		Raw    URLEncoded `json:"raw"`
+		Padded URLEncoded `json:"padded"`
+	}
+	if err := json.Unmarshal([]byte(`{"raw":"AQI","padded":"AQI="}`), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if string(decoded.Raw) != "\x01\x02" || string(decoded.Padded) != "\x01\x02" {
+		t.Errorf("decoded = %x, %x", decoded.Raw, decoded.Padded)
+	}
+	if encoded, _ := json.Marshal(URLEncoded{0xfb, 0xff}); string(encoded) != `"-_8"` {
+		t.Errorf("encoded = %s, want \"-_8\"", encoded)
+	}
+	if err := json.Unmarshal([]byte(`"a+b/"`), new(URLEncoded)); err == nil {
+		t.Error("standard base64 must be rejected")
+	}
+}
+
+func TestClientChallengeRejectsMalformedData(t *testing.T) {
+	for _, raw := range []string{``, `{}`, `{"challenge":1}`} {
+		if _, err := ClientChallenge([]byte(raw)); !errors.Is(err, ErrVerification) {
+			t.Errorf("ClientChallenge(%q) err = %v, want ErrVerification", raw, err)
+		}
+	}
+}